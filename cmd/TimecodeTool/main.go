@@ -4,10 +4,14 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
+	"path/filepath"
 	"reflect"
+	"strconv"
+	"strings"
 
-	"github.com/invopop/jsonschema"
 	"github.com/marcrleonard/TimecodeTool/pkg"
 	"github.com/spf13/cobra"
 	"github.com/spf13/cobra/doc"
@@ -16,20 +20,61 @@ import (
 func main() {
 
 	var (
-		fps                   float64
+		fps                   string
 		jsonOutput            bool
 		prettyPrintJsonOutput bool
 		keyOutput             string
 		excludeLastTimecode   bool
+		targetFps             string
+		convertStrategy       string
+		repairPolicy          string
+		signedSpan            bool
+		edlOffset             string
+		edlSplitAt            string
+		edlRenumber           bool
+		outputPath            string
+		batchFormat           string
+		serveAddr             string
+		filmGauge             string
+		ltcChannel            int
+		duration              string
+		ltcSampleRate         int
+		ltcBitDepth           int
+		ltcUserBits           string
+		ltcColorFrame         bool
+		mtcReverse            bool
+		mtcFps                string
+		spanFields            bool
+		spanSampleRate        int
+		todFps                string
+		todDate               string
+		todZone               string
+		captionFormat         string
+		captionStrategy       string
+		captionOffset         string
+		otioFps               string
 	)
 
 	var rootCmd = &cobra.Command{
-		Use:     "TimecodeTool [validate|span|calculate|schema] [args] [flags]",
+		Use:     "TimecodeTool [validate|span|calculate|convert|fix|edl|conform|fcpxml|otio|ltc|mtc|todclock|captions|batch|repl|serve|schema] [args] [flags]",
 		Short:   "A timecode CLI tool.",
 		Version: timecodetool.VERSION,
 		Long: "A timecode CLI tool.\n\n`TimecodeTool validate [args] [flags]` For timecode validation\n\n" +
 			"`TimecodeTool span [args] [flags]` For timecode span length information\n\n" +
-			"`TimecodeTool calculator [args] [flags]` for timecode calculations",
+			"`TimecodeTool calculator [args] [flags]` for timecode calculations\n\n" +
+			"`TimecodeTool convert [args] [flags]` for converting timecodes between frame rates\n\n" +
+			"`TimecodeTool fix [args] [flags]` for repairing broken timecodes\n\n" +
+			"`TimecodeTool edl [args] [flags]` for analysing CMX3600 EDLs\n\n" +
+			"`TimecodeTool conform [args] [flags]` for offsetting, converting, renumbering, merging and splitting CMX3600 EDLs\n\n" +
+			"`TimecodeTool fcpxml [args] [flags]` for listing the clips of Final Cut Pro XML projects\n\n" +
+			"`TimecodeTool otio [args] [flags]` for reporting the clips of OpenTimelineIO timelines\n\n" +
+			"`TimecodeTool ltc [decode|encode] [args] [flags]` for reading and writing LTC audio in WAV files\n\n" +
+			"`TimecodeTool mtc [dump|encode] [args] [flags]` for reading and writing MIDI timecode\n\n" +
+			"`TimecodeTool todclock [args] [flags]` for converting between time of day timecode and the clock\n\n" +
+			"`TimecodeTool captions retime [args] [flags]` for retiming and checking SRT, WebVTT and SCC caption files\n\n" +
+			"`TimecodeTool batch [flags] < jobs` for running many validate, span and calculate jobs at once\n\n" +
+			"`TimecodeTool repl [flags]` for an interactive calculator\n\n" +
+			"`TimecodeTool serve [flags]` for serving the tools over HTTP",
 	}
 
 	validateCmd := &cobra.Command{
@@ -60,38 +105,748 @@ func main() {
 			startTc := args[0]
 			resp := timecodetool.NewValidateTimecode(startTc, fps)
 			if jsonOutput {
+				writeJSONOutput(cmd, resp)
+			} else {
+				PrettyPrintValidate(resp)
+			}
+		},
+	}
+	validateCmd.Flags().BoolVar(&jsonOutput, "json-output", false, "Output as JSON")
+	validateCmd.Flags().BoolVar(&prettyPrintJsonOutput, "pretty-print", false, "Output indented JSON")
+	validateCmd.Flags().StringVar(&fps, "fps", "29.97", "Frame rate of timecodes. Accepts decimals (29.97), fractions (30000/1001) and a DF/NDF suffix (29.97DF)")
+	validateCmd.Flags().StringVar(&keyOutput, "key", "", "Specifies the key of which the sole value will be returned.")
+	validateCmd.MarkFlagsOneRequired("fps")
+
+	spanCmd := &cobra.Command{
+		Use:   "span [flags] [First Timecode] [Last Timecode]",
+		Short: "Get duration information spanning two timecodes.",
+		Args:  cobra.ExactArgs(2),
+		Long: "Get duration information spanning two timecodes. Returns durations in frames, seconds, time, and more. " +
+			"If the last timecode is before the first timecode the span crosses midnight, unless --signed is set, in which case the span is negative.",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+
+			jsonOutput := cmd.Flags().Changed("json-output")
+
+			if jsonOutput {
+				if err := validateJsonOptions(cmd, args); err != nil {
+					return err
+				}
+
+				if cmd.Flags().Changed("key") {
+					if exists := hasJSONField(timecodetool.SpanResponse{}, keyOutput); !exists {
+						return fmt.Errorf("%s is not a valid key.", keyOutput)
+					}
+
+				}
+			}
+
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			startTc := args[0]
+			endTc := args[1]
+			resp := timecodetool.NewSpan(startTc, endTc, fps, timecodetool.SpanOptions{
+				ExcludeLastTimecode: excludeLastTimecode,
+				Signed:              signedSpan,
+				FilmGauge:           filmGauge,
+				Fields:              spanFields,
+				SampleRate:          spanSampleRate,
+			})
+
+			if jsonOutput {
+				writeJSONOutput(cmd, resp)
+			} else {
+				PrettyPrintSpan(resp)
+			}
+		},
+	}
+	spanCmd.Flags().BoolVar(&jsonOutput, "json-output", false, "Output as JSON")
+	spanCmd.Flags().BoolVar(&prettyPrintJsonOutput, "pretty-print", false, "Output indented JSON")
+	spanCmd.Flags().BoolVarP(&excludeLastTimecode, "exclude-last-timecode", "e", false, `When entering a first timecode and a last timecode, the calculations will be based off the last timecode, minus one frame. This typically make it easier to read and enter timecode. For instance, with this flag set, a span of "00:00:00:00" "00:00:01:00" represents one second.`)
+	spanCmd.Flags().StringVar(&fps, "fps", "29.97", "Frame rate of timecodes. Accepts decimals (29.97), fractions (30000/1001) and a DF/NDF suffix (29.97DF)")
+	spanCmd.Flags().BoolVar(&signedSpan, "signed", false, "A last timecode before the first timecode gives a negative span, rather than crossing midnight.")
+	spanCmd.Flags().StringVar(&keyOutput, "key", "", "Specifies the key of which the sole value will be returned.")
+	spanCmd.Flags().StringVar(&filmGauge, "film-gauge", "", "Adds the length in feet+frames: 35mm-4perf (16 frames a foot), 35mm-3perf (21.33) or 16mm (40)")
+	spanCmd.Flags().IntVar(&spanSampleRate, "sample-rate", 0, "Adds the length in audio samples at this sample rate, ie 48000 or 96000")
+	spanCmd.Flags().BoolVar(&spanFields, "fields", false, "Adds the length in fields, for interlaced 50i/59.94i. Timecodes can end with a field, ie 01:00:00:00.1 or 01:00:00:00*")
+	spanCmd.MarkFlagsOneRequired("fps")
+
+	calcCmd := &cobra.Command{
+		Use:   "calculate --fps=29.97 [First Timecode] + [Timecode] - [frame number]",
+		Short: "Timecode/Frame calculator. Enter either timecode strings or frame numbers. ",
+		Args:  cobra.MinimumNArgs(2),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+
+			jsonOutput := cmd.Flags().Changed("json-output")
+
+			if jsonOutput {
+				if err := validateJsonOptions(cmd, args); err != nil {
+					return err
+				}
+
+				if cmd.Flags().Changed("key") {
+					if exists := hasJSONField(timecodetool.CalcResponse{}, keyOutput); !exists {
+						return fmt.Errorf("%s is not a valid key.", keyOutput)
+					}
+
+				}
+			}
+
+			return nil
+		},
+		Long: "Timecode/Frame calculator. Enter either timecode strings or frame numbers. It will add all these all together and generate span. When entering a timecode the amount of frames added/subtracted is relative to 00:00:00:00, with the timecode entered being inclusive. Use the `-e` flag to make it exclusive." +
+			"\n\nEverything after the first timecode is one expression, which must start with + or -. It can use parentheses, " +
+			"multiply or divide by a number, and units of hours, minutes, seconds or frames (1h, 2m, 30s, 12f). Units can be written together like a Go duration (1h2m3.5s, 500ms). Every unit is timecode rather than real time, so 1h30m is the same as 1h + 30m and 30.0s as 30s, and part of a second is rounded to the nearest frame. Timecodes that are multiplied or divided are lengths and don't count their last frame, so 00:00:30:00 * 4 is 00:02:00:00. Quote it so the shell leaves * and ( ) alone. Examples:" +
+			"\n  TimecodeTool calculate --fps=25 01:00:00:00 + 00:00:10:00 - 5" +
+			"\n  TimecodeTool calculate --fps=25 01:00:00:00 '+ 00:00:30:00 * 4'" +
+			"\n  TimecodeTool calculate --fps=25 01:00:00:00 '- (1h + 30s) / 2 + 12f'" +
+			"\n  TimecodeTool calculate --fps=29.97 01:00:00:00 + 1h2m3.5s",
+		Run: func(cmd *cobra.Command, args []string) {
+			resp := timecodetool.NewCalculate(args[0], args[1:], fps, timecodetool.CalculateOptions{
+				ExcludeLastTimecode: excludeLastTimecode,
+				FilmGauge:           filmGauge,
+			})
+
+			if jsonOutput {
+				writeJSONOutput(cmd, resp)
+			} else {
+				PrettyPrintCalc(resp)
+			}
+
+		},
+	}
+	calcCmd.Flags().BoolVar(&jsonOutput, "json-output", false, "Output as JSON")
+	calcCmd.Flags().BoolVar(&prettyPrintJsonOutput, "pretty-print", false, "Output indented JSON")
+	calcCmd.Flags().BoolVarP(&excludeLastTimecode, "exclude-last-timecode", "e", false, `When entering a timecode to be added or subtracted, the calculations will be based off the timecode, minus one frame. This typically make it easier to read and enter timecode."`)
+	calcCmd.Flags().StringVar(&fps, "fps", "29.97", "Frame rate of timecodes. Accepts decimals (29.97), fractions (30000/1001) and a DF/NDF suffix (29.97DF)")
+	calcCmd.Flags().StringVar(&keyOutput, "key", "", "Specifies the key of which the sole value will be returned.")
+	calcCmd.Flags().StringVar(&filmGauge, "film-gauge", "", "Allows feet+frames (ie 123+08) and adds the length in feet+frames: 35mm-4perf, 35mm-3perf or 16mm")
+	calcCmd.MarkFlagsOneRequired("fps")
+
+	convertCmd := &cobra.Command{
+		Use:   "convert --fps=25 --to-fps=23.976 [Timecode] [Last Timecode]",
+		Short: "Convert a timecode, or a span, to another frame rate.",
+		Args:  cobra.RangeArgs(1, 2),
+		Long: "Convert a timecode, or a span of two timecodes, to another frame rate. The --strategy flag decides how it is mapped:" +
+			"\n  realtime        keep the real time position, nearest frame (default)" +
+			"\n  realtime-floor  keep the real time position, frame at or before it" +
+			"\n  realtime-ceil   keep the real time position, frame at or after it" +
+			"\n  frames          keep the frame index" +
+			"\n  label           keep the hh:mm:ss label and scale the frames" +
+			"\n  pulldown        2:3 pulldown between 23.976 and 29.97 (or 24 and 30)" +
+			"\nThe target is drop frame when --to-fps has a DF suffix, ie --to-fps=29.97DF.",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+
+			jsonOutput := cmd.Flags().Changed("json-output")
+
+			if jsonOutput {
+				if err := validateJsonOptions(cmd, args); err != nil {
+					return err
+				}
+
+				if cmd.Flags().Changed("key") {
+					if exists := hasJSONField(timecodetool.ConvertResponse{}, keyOutput); !exists {
+						return fmt.Errorf("%s is not a valid key.", keyOutput)
+					}
+
+				}
+			}
+
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			startTc := args[0]
+			endTc := ""
+			if len(args) > 1 {
+				endTc = args[1]
+			}
+			resp := timecodetool.NewConvertTimecode(startTc, endTc, fps, targetFps, convertStrategy)
+
+			if jsonOutput {
+				writeJSONOutput(cmd, resp)
+			} else {
+				PrettyPrintConvert(resp)
+			}
+		},
+	}
+	convertCmd.Flags().BoolVar(&jsonOutput, "json-output", false, "Output as JSON")
+	convertCmd.Flags().BoolVar(&prettyPrintJsonOutput, "pretty-print", false, "Output indented JSON")
+	convertCmd.Flags().StringVar(&fps, "fps", "29.97", "Frame rate of the input timecodes. Accepts decimals (29.97), fractions (30000/1001) and a DF/NDF suffix (29.97DF)")
+	convertCmd.Flags().StringVar(&targetFps, "to-fps", "", "Frame rate to convert to. Accepts the same formats as --fps")
+	convertCmd.Flags().StringVar(&convertStrategy, "strategy", "realtime", "How timecodes are mapped: realtime, realtime-floor, realtime-ceil, frames, label or pulldown")
+	convertCmd.Flags().StringVar(&keyOutput, "key", "", "Specifies the key of which the sole value will be returned.")
+	convertCmd.MarkFlagRequired("to-fps")
+
+	fixCmd := &cobra.Command{
+		Use:   "fix [flags] [Timecode]",
+		Short: "Attempts to repair a broken timecode.",
+		Args:  cobra.ExactArgs(1),
+		Long: "Attempts to repair a broken timecode. Reports everything wrong with it (frame overflow, illegal drop frame labels, " +
+			"hour rollover, wrong delimiters) and the corrected timecode. The --policy flag decides how it is corrected:" +
+			"\n  carry         carry overflowing fields into the next field up (default)" +
+			"\n  snap-forward  move to the next valid timecode" +
+			"\n  snap-back     move to the previous valid timecode",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+
+			jsonOutput := cmd.Flags().Changed("json-output")
+
+			if jsonOutput {
+				if err := validateJsonOptions(cmd, args); err != nil {
+					return err
+				}
+
+				if cmd.Flags().Changed("key") {
+					if exists := hasJSONField(timecodetool.FixResponse{}, keyOutput); !exists {
+						return fmt.Errorf("%s is not a valid key.", keyOutput)
+					}
+
+				}
+			}
+
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			resp := timecodetool.NewFixTimecode(args[0], fps, repairPolicy)
+
+			if jsonOutput {
+				writeJSONOutput(cmd, resp)
+			} else {
+				PrettyPrintFix(resp)
+			}
+		},
+	}
+	fixCmd.Flags().BoolVar(&jsonOutput, "json-output", false, "Output as JSON")
+	fixCmd.Flags().BoolVar(&prettyPrintJsonOutput, "pretty-print", false, "Output indented JSON")
+	fixCmd.Flags().StringVar(&fps, "fps", "29.97", "Frame rate of timecodes. Accepts decimals (29.97), fractions (30000/1001) and a DF/NDF suffix (29.97DF)")
+	fixCmd.Flags().StringVar(&repairPolicy, "policy", "carry", "How the timecode is corrected: carry, snap-forward or snap-back")
+	fixCmd.Flags().StringVar(&keyOutput, "key", "", "Specifies the key of which the sole value will be returned.")
+
+	edlCmd := &cobra.Command{
+		Use:   "edl --fps=25 [EDL file]",
+		Short: "Reports the events, durations, gaps and overlaps of a CMX3600 EDL.",
+		Args:  cobra.ExactArgs(1),
+		Long: "Reads a CMX3600 EDL and reports each event (reel, track, transition, source and record timecodes, clip name, " +
+			"M2 speed changes) with its record duration, the total record duration and any gaps or overlaps between events " +
+			"on the same track. EDLs don't carry their frame rate, so --fps has to match the EDL. Use - to read the EDL from stdin.",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+
+			jsonOutput := cmd.Flags().Changed("json-output")
+
+			if jsonOutput {
+				if err := validateJsonOptions(cmd, args); err != nil {
+					return err
+				}
+
 				if cmd.Flags().Changed("key") {
-					value, err := GetValueFromStruct(resp, keyOutput)
-					if err != nil {
-						panic(err)
+					if exists := hasJSONField(timecodetool.EdlResponse{}, keyOutput); !exists {
+						return fmt.Errorf("%s is not a valid key.", keyOutput)
 					}
-					fmt.Println(value)
-				} else if prettyPrintJsonOutput {
-					prettyJSON, err := json.MarshalIndent(resp, "", "  ")
-					if err != nil {
-						fmt.Println("Error encoding JSON:", err)
-						os.Exit(1)
+
+				}
+			}
+
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			var (
+				edl []byte
+				err error
+			)
+			if args[0] == "-" {
+				edl, err = io.ReadAll(os.Stdin)
+			} else {
+				edl, err = os.ReadFile(args[0])
+			}
+			if err != nil {
+				fmt.Println("Error reading EDL:", err)
+				os.Exit(1)
+			}
+
+			resp := timecodetool.NewEdlAnalysis(string(edl), fps)
+
+			if jsonOutput {
+				writeJSONOutput(cmd, resp)
+			} else {
+				PrettyPrintEdl(resp)
+			}
+		},
+	}
+	edlCmd.Flags().BoolVar(&jsonOutput, "json-output", false, "Output as JSON")
+	edlCmd.Flags().BoolVar(&prettyPrintJsonOutput, "pretty-print", false, "Output indented JSON")
+	edlCmd.Flags().StringVar(&fps, "fps", "29.97", "Frame rate of the EDL. Accepts decimals (29.97), fractions (30000/1001) and a DF/NDF suffix (29.97DF)")
+	edlCmd.Flags().StringVar(&keyOutput, "key", "", "Specifies the key of which the sole value will be returned.")
+	edlCmd.MarkFlagsOneRequired("fps")
+
+	conformCmd := &cobra.Command{
+		Use:   "conform --fps=29.97 [flags] [EDL file] [EDL file to merge]...",
+		Short: "Conforms CMX3600 EDLs and writes them back out.",
+		Args:  cobra.MinimumNArgs(1),
+		Long: "Conforms one or more CMX3600 EDLs and writes the result back out as CMX3600. Several EDLs are merged into one, " +
+			"sorted by record in. The operations are applied in this order:" +
+			"\n  --to-fps     convert to another frame rate, or between DF and NDF (ie --to-fps=29.97NDF), using --strategy" +
+			"\n  --offset     move every record timecode by a timecode or frame count, ie --offset=-01:00:00:00" +
+			"\n  --split-at   split the EDL in two at a record timecode" +
+			"\n  --renumber   number the events from 1" +
+			"\nThe EDL is printed unless --output is set. A split writes two files, ie cut.edl becomes cut_1.edl and cut_2.edl.",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+
+			jsonOutput := cmd.Flags().Changed("json-output")
+
+			if jsonOutput {
+				if err := validateJsonOptions(cmd, args); err != nil {
+					return err
+				}
+
+				if cmd.Flags().Changed("key") {
+					if exists := hasJSONField(timecodetool.EdlConformResponse{}, keyOutput); !exists {
+						return fmt.Errorf("%s is not a valid key.", keyOutput)
 					}
-					fmt.Println(string(prettyJSON))
-				} else if err := json.NewEncoder(os.Stdout).Encode(resp); err != nil {
-					panic("Error encoding json")
+
+				}
+			} else if edlSplitAt != "" && outputPath == "" {
+				return fmt.Errorf("the --split-at flag requires the --output flag or the --json-output flag to be set")
+			}
+
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			var edls []string
+			for _, path := range args {
+				var (
+					edl []byte
+					err error
+				)
+				if path == "-" {
+					edl, err = io.ReadAll(os.Stdin)
+				} else {
+					edl, err = os.ReadFile(path)
+				}
+				if err != nil {
+					fmt.Println("Error reading EDL:", err)
+					os.Exit(1)
+				}
+				edls = append(edls, string(edl))
+			}
+
+			resp := timecodetool.NewEdlConform(edls, fps, timecodetool.EdlConformOptions{
+				TargetFps: targetFps,
+				Strategy:  convertStrategy,
+				Offset:    edlOffset,
+				SplitAt:   edlSplitAt,
+				Renumber:  edlRenumber,
+			})
+
+			if jsonOutput {
+				writeJSONOutput(cmd, resp)
+				return
+			}
+
+			if !resp.Valid {
+				fmt.Fprintln(os.Stderr, "Error:", resp.ErrorMsg)
+				os.Exit(1)
+			}
+			if outputPath == "" {
+				fmt.Print(resp.Edls[0].Edl)
+				return
+			}
+			for i, edl := range resp.Edls {
+				path := outputPath
+				if len(resp.Edls) > 1 {
+					ext := filepath.Ext(outputPath)
+					path = fmt.Sprintf("%s_%d%s", strings.TrimSuffix(outputPath, ext), i+1, ext)
+				}
+				if err := os.WriteFile(path, []byte(edl.Edl), 0644); err != nil {
+					fmt.Println("Error writing EDL:", err)
+					os.Exit(1)
+				}
+				fmt.Printf("Wrote %s (%d events)\n", path, edl.EventCount)
+			}
+		},
+	}
+	conformCmd.Flags().BoolVar(&jsonOutput, "json-output", false, "Output as JSON")
+	conformCmd.Flags().BoolVar(&prettyPrintJsonOutput, "pretty-print", false, "Output indented JSON")
+	conformCmd.Flags().StringVar(&fps, "fps", "29.97", "Frame rate of the EDLs. Accepts decimals (29.97), fractions (30000/1001) and a DF/NDF suffix (29.97DF)")
+	conformCmd.Flags().StringVar(&targetFps, "to-fps", "", "Frame rate to convert to. Accepts the same formats as --fps")
+	conformCmd.Flags().StringVar(&convertStrategy, "strategy", "realtime", "How timecodes are mapped by --to-fps: realtime, realtime-floor, realtime-ceil, frames, label or pulldown")
+	conformCmd.Flags().StringVar(&edlOffset, "offset", "", "Moves every record timecode by a timecode or frame count. Prefix with - to move back")
+	conformCmd.Flags().StringVar(&edlSplitAt, "split-at", "", "Splits the EDL in two at this record timecode")
+	conformCmd.Flags().BoolVar(&edlRenumber, "renumber", false, "Numbers the events from 1")
+	conformCmd.Flags().StringVarP(&outputPath, "output", "o", "", "File to write the EDL to, rather than printing it")
+	conformCmd.Flags().StringVar(&keyOutput, "key", "", "Specifies the key of which the sole value will be returned.")
+	conformCmd.MarkFlagsOneRequired("fps")
+
+	fcpxmlCmd := &cobra.Command{
+		Use:   "fcpxml [FCPXML file]",
+		Short: "Lists the clips of an FCPXML project with their record and source timecodes.",
+		Args:  cobra.ExactArgs(1),
+		Long: "Reads a Final Cut Pro XML (.fcpxml) document and lists the clips of each project's sequence, in the primary " +
+			"storyline and connected to it, with their record in and out timecodes at the sequence's frame rate and their " +
+			"source in and out timecodes at the media's frame rate. FCPXML times are rational seconds (ie 1001/30000s) and " +
+			"carry their frame rates, so no --fps is needed. Edits that aren't on a frame boundary are flagged and reported " +
+			"at the nearest frame. Use - to read the FCPXML from stdin.",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+
+			jsonOutput := cmd.Flags().Changed("json-output")
+
+			if jsonOutput {
+				if err := validateJsonOptions(cmd, args); err != nil {
+					return err
+				}
+
+				if cmd.Flags().Changed("key") {
+					if exists := hasJSONField(timecodetool.FcpxmlResponse{}, keyOutput); !exists {
+						return fmt.Errorf("%s is not a valid key.", keyOutput)
+					}
+
+				}
+			}
+
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			var (
+				fcpxml []byte
+				err    error
+			)
+			if args[0] == "-" {
+				fcpxml, err = io.ReadAll(os.Stdin)
+			} else {
+				fcpxml, err = os.ReadFile(args[0])
+			}
+			if err != nil {
+				fmt.Println("Error reading FCPXML:", err)
+				os.Exit(1)
+			}
+
+			resp := timecodetool.NewFcpxmlAnalysis(string(fcpxml))
+
+			if jsonOutput {
+				writeJSONOutput(cmd, resp)
+			} else {
+				PrettyPrintFcpxml(resp)
+			}
+		},
+	}
+	fcpxmlCmd.Flags().BoolVar(&jsonOutput, "json-output", false, "Output as JSON")
+	fcpxmlCmd.Flags().BoolVar(&prettyPrintJsonOutput, "pretty-print", false, "Output indented JSON")
+	fcpxmlCmd.Flags().StringVar(&keyOutput, "key", "", "Specifies the key of which the sole value will be returned.")
+
+	otioCmd := &cobra.Command{
+		Use:   "otio [OTIO file]",
+		Short: "Reports the source and record spans of the clips in an OpenTimelineIO timeline.",
+		Args:  cobra.ExactArgs(1),
+		Long: "Reads an OpenTimelineIO (.otio) JSON timeline and reports the source span of each clip at its media's rate " +
+			"and its record span in the timeline, from the timeline's global_start_time, in the same JSON as the span command. " +
+			"Transitions are reported with the overlap around their cut. The timecode is at the timeline's rate unless --fps is " +
+			"given (ie --fps=29.97DF for drop frame, which OTIO doesn't record). Clips or a timeline at another rate are reported " +
+			"as rate mismatches and their record times are rescaled to the nearest frame. Use - to read the OTIO from stdin.",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+
+			jsonOutput := cmd.Flags().Changed("json-output")
+
+			if jsonOutput {
+				if err := validateJsonOptions(cmd, args); err != nil {
+					return err
+				}
+
+				if cmd.Flags().Changed("key") {
+					if exists := hasJSONField(timecodetool.OtioResponse{}, keyOutput); !exists {
+						return fmt.Errorf("%s is not a valid key.", keyOutput)
+					}
+
+				}
+			}
+
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			var (
+				otio []byte
+				err  error
+			)
+			if args[0] == "-" {
+				otio, err = io.ReadAll(os.Stdin)
+			} else {
+				otio, err = os.ReadFile(args[0])
+			}
+			if err != nil {
+				fmt.Println("Error reading OTIO:", err)
+				os.Exit(1)
+			}
+
+			resp := timecodetool.NewOtioAnalysis(string(otio), otioFps)
+
+			if jsonOutput {
+				writeJSONOutput(cmd, resp)
+			} else {
+				PrettyPrintOtio(resp)
+			}
+		},
+	}
+	otioCmd.Flags().BoolVar(&jsonOutput, "json-output", false, "Output as JSON")
+	otioCmd.Flags().BoolVar(&prettyPrintJsonOutput, "pretty-print", false, "Output indented JSON")
+	otioCmd.Flags().StringVar(&otioFps, "fps", "", "Reports timecode at this rate rather than the timeline's rate, ie 29.97DF for drop frame")
+	otioCmd.Flags().StringVar(&keyOutput, "key", "", "Specifies the key of which the sole value will be returned.")
+
+	ltcCmd := &cobra.Command{
+		Use:   "ltc [decode|encode]",
+		Short: "Reads and writes SMPTE linear timecode (LTC) in WAV files.",
+		Long: "Reads and writes SMPTE ST 12-1 linear timecode (LTC) in WAV files. Examples:" +
+			"\n  TimecodeTool ltc decode --fps=25 timecode.wav" +
+			"\n  TimecodeTool ltc encode --fps=29.97DF --duration=00:01:00;00 -o timecode.wav 00:59:50;00",
+	}
+
+	ltcDecodeCmd := &cobra.Command{
+		Use:   "decode --fps=25 [flags] [WAV file]",
+		Short: "Decodes the LTC in a WAV file.",
+		Args:  cobra.ExactArgs(1),
+		Long: "Decodes the LTC on a channel of a WAV file and reports each frame with its user bits, drop frame and color frame flags " +
+			"and where it starts in the audio. LTC played backwards is decoded too. LTC doesn't carry its frame rate, so --fps has " +
+			"to be given. Drop frame comes from the LTC. Use - to read the WAV file from stdin.",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+
+			jsonOutput := cmd.Flags().Changed("json-output")
+
+			if jsonOutput {
+				if err := validateJsonOptions(cmd, args); err != nil {
+					return err
+				}
+
+				if cmd.Flags().Changed("key") {
+					if exists := hasJSONField(timecodetool.LtcDecodeResponse{}, keyOutput); !exists {
+						return fmt.Errorf("%s is not a valid key.", keyOutput)
+					}
+
+				}
+			}
+
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			var (
+				wav []byte
+				err error
+			)
+			if args[0] == "-" {
+				wav, err = io.ReadAll(os.Stdin)
+			} else {
+				wav, err = os.ReadFile(args[0])
+			}
+			if err != nil {
+				fmt.Println("Error reading WAV file:", err)
+				os.Exit(1)
+			}
+
+			resp := timecodetool.NewLtcDecode(wav, fps, ltcChannel)
+
+			if jsonOutput {
+				writeJSONOutput(cmd, resp)
+			} else {
+				PrettyPrintLtcDecode(resp)
+			}
+		},
+	}
+	ltcDecodeCmd.Flags().BoolVar(&jsonOutput, "json-output", false, "Output as JSON")
+	ltcDecodeCmd.Flags().BoolVar(&prettyPrintJsonOutput, "pretty-print", false, "Output indented JSON")
+	ltcDecodeCmd.Flags().StringVar(&fps, "fps", "29.97", "Frame rate of the LTC. Accepts decimals (29.97), fractions (30000/1001) and a DF/NDF suffix (29.97DF)")
+	ltcDecodeCmd.Flags().IntVar(&ltcChannel, "channel", 1, "Channel of the WAV file the LTC is on, counting from 1")
+	ltcDecodeCmd.Flags().StringVar(&keyOutput, "key", "", "Specifies the key of which the sole value will be returned.")
+	ltcDecodeCmd.MarkFlagsOneRequired("fps")
+
+	ltcEncodeCmd := &cobra.Command{
+		Use:   "encode --fps=25 --duration=00:01:00:00 -o [WAV file] [flags] [Timecode]",
+		Short: "Writes a mono WAV file of LTC starting at a timecode.",
+		Args:  cobra.ExactArgs(1),
+		Long: "Writes a mono PCM WAV file of LTC counting up from a timecode, for --duration (a timecode length or a frame count). " +
+			"Use --fps=29.97DF (or a ; in the timecode) for drop frame.",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+
+			jsonOutput := cmd.Flags().Changed("json-output")
+
+			if jsonOutput {
+				if err := validateJsonOptions(cmd, args); err != nil {
+					return err
+				}
+
+				if cmd.Flags().Changed("key") {
+					if exists := hasJSONField(timecodetool.LtcEncodeResponse{}, keyOutput); !exists {
+						return fmt.Errorf("%s is not a valid key.", keyOutput)
+					}
+
+				}
+			}
+
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			resp := timecodetool.NewLtcEncode(args[0], fps, timecodetool.LtcEncodeOptions{
+				Duration:      duration,
+				SampleRate:    ltcSampleRate,
+				BitsPerSample: ltcBitDepth,
+				UserBits:      ltcUserBits,
+				ColorFrame:    ltcColorFrame,
+			})
+
+			if resp.Valid {
+				if err := os.WriteFile(outputPath, resp.Wav, 0644); err != nil {
+					fmt.Println("Error writing WAV file:", err)
+					os.Exit(1)
+				}
+			}
+
+			if jsonOutput {
+				writeJSONOutput(cmd, resp)
+			} else {
+				PrettyPrintLtcEncode(resp, outputPath)
+			}
+		},
+	}
+	ltcEncodeCmd.Flags().BoolVar(&jsonOutput, "json-output", false, "Output as JSON")
+	ltcEncodeCmd.Flags().BoolVar(&prettyPrintJsonOutput, "pretty-print", false, "Output indented JSON")
+	ltcEncodeCmd.Flags().StringVar(&fps, "fps", "29.97", "Frame rate of the LTC. Accepts decimals (29.97), fractions (30000/1001) and a DF/NDF suffix (29.97DF)")
+	ltcEncodeCmd.Flags().StringVar(&duration, "duration", "", "How much LTC to write, as a timecode length or a frame count")
+	ltcEncodeCmd.Flags().IntVar(&ltcSampleRate, "sample-rate", 48000, "Sample rate of the WAV file")
+	ltcEncodeCmd.Flags().IntVar(&ltcBitDepth, "bit-depth", 16, "Bit depth of the WAV file: 16 or 24")
+	ltcEncodeCmd.Flags().StringVar(&ltcUserBits, "user-bits", "", "User bits as up to 8 hex digits, user bit group 8 first")
+	ltcEncodeCmd.Flags().BoolVar(&ltcColorFrame, "color-frame", false, "Sets the color frame flag")
+	ltcEncodeCmd.Flags().StringVarP(&outputPath, "output", "o", "", "File to write the WAV file to")
+	ltcEncodeCmd.Flags().StringVar(&keyOutput, "key", "", "Specifies the key of which the sole value will be returned.")
+	ltcEncodeCmd.MarkFlagRequired("duration")
+	ltcEncodeCmd.MarkFlagRequired("output")
+
+	ltcCmd.AddCommand(ltcDecodeCmd, ltcEncodeCmd)
+
+	mtcCmd := &cobra.Command{
+		Use:   "mtc [dump|encode]",
+		Short: "Reads and writes MIDI timecode (MTC).",
+		Long: "Reads and writes MIDI timecode (MTC) full frame and quarter frame messages as raw MIDI bytes. Examples:" +
+			"\n  TimecodeTool mtc dump capture.mid" +
+			"\n  TimecodeTool mtc encode --fps=29.97DF --duration=00:00:10;00 -o mtc.bin 00:59:50;00",
+	}
+
+	mtcDumpCmd := &cobra.Command{
+		Use:   "dump [flags] [MIDI byte file]",
+		Short: "Lists the MTC in a file of raw MIDI bytes.",
+		Args:  cobra.ExactArgs(1),
+		Long: "Lists the MTC full frame messages and complete sets of 8 quarter frames in a file of raw MIDI bytes, with the " +
+			"direction the quarter frames are running in. Other MIDI messages are skipped. The rate comes from the MTC, " +
+			"which only has 24, 25, 29.97DF and 30. Use --fps to read it at a rate with the same timebase, ie --fps=23.976. " +
+			"Use - to read from stdin.",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+
+			jsonOutput := cmd.Flags().Changed("json-output")
+
+			if jsonOutput {
+				if err := validateJsonOptions(cmd, args); err != nil {
+					return err
+				}
+
+				if cmd.Flags().Changed("key") {
+					if exists := hasJSONField(timecodetool.MtcDumpResponse{}, keyOutput); !exists {
+						return fmt.Errorf("%s is not a valid key.", keyOutput)
+					}
+
+				}
+			}
+
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			var (
+				data []byte
+				err  error
+			)
+			if args[0] == "-" {
+				data, err = io.ReadAll(os.Stdin)
+			} else {
+				data, err = os.ReadFile(args[0])
+			}
+			if err != nil {
+				fmt.Println("Error reading MIDI bytes:", err)
+				os.Exit(1)
+			}
+
+			resp := timecodetool.NewMtcDump(data, mtcFps)
+
+			if jsonOutput {
+				writeJSONOutput(cmd, resp)
+			} else {
+				PrettyPrintMtcDump(resp)
+			}
+		},
+	}
+	mtcDumpCmd.Flags().BoolVar(&jsonOutput, "json-output", false, "Output as JSON")
+	mtcDumpCmd.Flags().BoolVar(&prettyPrintJsonOutput, "pretty-print", false, "Output indented JSON")
+	mtcDumpCmd.Flags().StringVar(&mtcFps, "fps", "", "Reads the MTC at this rate rather than the rate in the MTC, ie 23.976 for MTC at 24")
+	mtcDumpCmd.Flags().StringVar(&keyOutput, "key", "", "Specifies the key of which the sole value will be returned.")
+
+	mtcEncodeCmd := &cobra.Command{
+		Use:   "encode --fps=25 --duration=00:00:10:00 -o [file] [flags] [Timecode]",
+		Short: "Writes MTC starting at a timecode as raw MIDI bytes.",
+		Args:  cobra.ExactArgs(1),
+		Long: "Writes a full frame message for a timecode followed by the quarter frames for --duration (a timecode length or a " +
+			"frame count) as raw MIDI bytes. MTC only has 24, 25, 29.97DF and 30, so 23.976 is sent as 24 and 29.97 non drop " +
+			"frame as 30. --reverse counts down, with each set of quarter frames sent backwards.",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+
+			jsonOutput := cmd.Flags().Changed("json-output")
+
+			if jsonOutput {
+				if err := validateJsonOptions(cmd, args); err != nil {
+					return err
+				}
+
+				if cmd.Flags().Changed("key") {
+					if exists := hasJSONField(timecodetool.MtcEncodeResponse{}, keyOutput); !exists {
+						return fmt.Errorf("%s is not a valid key.", keyOutput)
+					}
+
+				}
+			}
+
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			resp := timecodetool.NewMtcEncode(args[0], fps, timecodetool.MtcEncodeOptions{
+				Duration: duration,
+				Reverse:  mtcReverse,
+			})
+
+			if resp.Valid {
+				if err := os.WriteFile(outputPath, resp.Mtc, 0644); err != nil {
+					fmt.Println("Error writing MIDI bytes:", err)
+					os.Exit(1)
 				}
+			}
+
+			if jsonOutput {
+				writeJSONOutput(cmd, resp)
 			} else {
-				PrettyPrintValidate(resp)
+				PrettyPrintMtcEncode(resp, outputPath)
 			}
 		},
 	}
-	validateCmd.Flags().BoolVar(&jsonOutput, "json-output", false, "Output as JSON")
-	validateCmd.Flags().BoolVar(&prettyPrintJsonOutput, "pretty-print", false, "Output indented JSON")
-	validateCmd.Flags().Float64Var(&fps, "fps", 29.97, "Frame rate of timecodes")
-	validateCmd.Flags().StringVar(&keyOutput, "key", "", "Specifies the key of which the sole value will be returned.")
-	validateCmd.MarkFlagsOneRequired("fps")
+	mtcEncodeCmd.Flags().BoolVar(&jsonOutput, "json-output", false, "Output as JSON")
+	mtcEncodeCmd.Flags().BoolVar(&prettyPrintJsonOutput, "pretty-print", false, "Output indented JSON")
+	mtcEncodeCmd.Flags().StringVar(&fps, "fps", "29.97", "Frame rate of the MTC: 24 (or 23.976), 25, 29.97DF or 30 (or 29.97)")
+	mtcEncodeCmd.Flags().StringVar(&duration, "duration", "", "How much MTC to write, as a timecode length or a frame count")
+	mtcEncodeCmd.Flags().BoolVar(&mtcReverse, "reverse", false, "Counts down from the timecode")
+	mtcEncodeCmd.Flags().StringVarP(&outputPath, "output", "o", "", "File to write the MIDI bytes to")
+	mtcEncodeCmd.Flags().StringVar(&keyOutput, "key", "", "Specifies the key of which the sole value will be returned.")
+	mtcEncodeCmd.MarkFlagRequired("duration")
+	mtcEncodeCmd.MarkFlagRequired("output")
 
-	spanCmd := &cobra.Command{
-		Use:   "span [flags] [First Timecode] [Last Timecode]",
-		Short: "Get duration information spanning two timecodes.",
-		Args:  cobra.ExactArgs(2),
-		Long:  "Get duration information spanning two timecodes. Returns durations in frames, seconds, time, and more.",
+	mtcCmd.AddCommand(mtcDumpCmd, mtcEncodeCmd)
+
+	todClockCmd := &cobra.Command{
+		Use:   "todclock --fps=29.97DF [flags] [Timecode or clock time]",
+		Short: "Converts between time of day timecode and the clock.",
+		Args:  cobra.ExactArgs(1),
+		Long: "Gives the clock time of a time of day timecode, or the timecode of a clock time (hh:mm:ss.sss or an RFC 3339 " +
+			"time), for timecode jammed to the clock at midnight. At 29.97 the timecode drifts from the clock: drop frame gets " +
+			"86.4 ms ahead in a day and non drop frame falls 86.4 seconds behind. The drift at that time and over a day are reported. Examples:" +
+			"\n  TimecodeTool todclock --fps=29.97DF 14:32:05.250" +
+			"\n  TimecodeTool todclock --fps=29.97DF --zone=America/New_York --date=2024-07-04 14:32:05;08",
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 
 			jsonOutput := cmd.Flags().Changed("json-output")
@@ -102,7 +857,7 @@ func main() {
 				}
 
 				if cmd.Flags().Changed("key") {
-					if exists := hasJSONField(timecodetool.SpanResponse{}, keyOutput); !exists {
+					if exists := hasJSONField(timecodetool.TodClockResponse{}, keyOutput); !exists {
 						return fmt.Errorf("%s is not a valid key.", keyOutput)
 					}
 
@@ -112,43 +867,45 @@ func main() {
 			return nil
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			startTc := args[0]
-			endTc := args[1]
-			resp := timecodetool.NewSpanTimecode(startTc, endTc, fps, excludeLastTimecode)
+			resp := timecodetool.NewTodClock(args[0], todFps, timecodetool.TodClockOptions{
+				Date: todDate,
+				Zone: todZone,
+			})
 
 			if jsonOutput {
-				if cmd.Flags().Changed("key") {
-					value, err := GetValueFromStruct(resp, keyOutput)
-					if err != nil {
-						panic(err)
-					}
-					fmt.Println(value)
-				} else if prettyPrintJsonOutput {
-					prettyJSON, err := json.MarshalIndent(resp, "", "  ")
-					if err != nil {
-						fmt.Println("Error encoding JSON:", err)
-						os.Exit(1)
-					}
-					fmt.Println(string(prettyJSON))
-				} else if err := json.NewEncoder(os.Stdout).Encode(resp); err != nil {
-					panic("Error encoding json")
-				}
+				writeJSONOutput(cmd, resp)
 			} else {
-				PrettyPrintSpan(resp)
+				PrettyPrintTodClock(resp)
 			}
 		},
 	}
-	spanCmd.Flags().BoolVar(&jsonOutput, "json-output", false, "Output as JSON")
-	spanCmd.Flags().BoolVar(&prettyPrintJsonOutput, "pretty-print", false, "Output indented JSON")
-	spanCmd.Flags().BoolVarP(&excludeLastTimecode, "exclude-last-timecode", "e", false, `When entering a first timecode and a last timecode, the calculations will be based off the last timecode, minus one frame. This typically make it easier to read and enter timecode. For instance, with this flag set, a span of "00:00:00:00" "00:00:01:00" represents one second.`)
-	spanCmd.Flags().Float64Var(&fps, "fps", 29.97, "Frame rate of timecodes")
-	spanCmd.Flags().StringVar(&keyOutput, "key", "", "Specifies the key of which the sole value will be returned.")
-	spanCmd.MarkFlagsOneRequired("fps")
+	todClockCmd.Flags().BoolVar(&jsonOutput, "json-output", false, "Output as JSON")
+	todClockCmd.Flags().BoolVar(&prettyPrintJsonOutput, "pretty-print", false, "Output indented JSON")
+	todClockCmd.Flags().StringVar(&todFps, "fps", "29.97DF", "Frame rate of the timecode. Accepts decimals (29.97), fractions (30000/1001) and a DF/NDF suffix (29.97DF)")
+	todClockCmd.Flags().StringVar(&todDate, "date", "", "The day, as yyyy-mm-dd. Defaults to today")
+	todClockCmd.Flags().StringVar(&todZone, "zone", "", "IANA time zone of the clock, ie Europe/London. Defaults to the local time zone")
+	todClockCmd.Flags().StringVar(&keyOutput, "key", "", "Specifies the key of which the sole value will be returned.")
 
-	calcCmd := &cobra.Command{
-		Use:   "calculate --fps=29.97 [First Timecode] + [Timecode] - [frame number]",
-		Short: "Timecode/Frame calculator. Enter either timecode strings or frame numbers. ",
-		Args:  cobra.MinimumNArgs(3),
+	captionsCmd := &cobra.Command{
+		Use:   "captions [retime]",
+		Short: "Retimes and checks SRT, WebVTT and SCC caption files.",
+		Long: "Retimes and checks SRT, WebVTT and Scenarist SCC caption files. Examples:" +
+			"\n  TimecodeTool captions retime --fps=25 --to-fps=23.976 episode.srt -o episode_2398.srt" +
+			"\n  TimecodeTool captions retime --fps=29.97DF --offset=-01:00:00;00 episode.scc -o episode.scc",
+	}
+
+	captionsRetimeCmd := &cobra.Command{
+		Use:   "retime --fps=25 [flags] [caption file]",
+		Short: "Retimes a caption file and reports overlapping and zero length cues.",
+		Args:  cobra.ExactArgs(1),
+		Long: "Retimes an SRT, WebVTT or SCC file and writes it back out in the same format. SRT and WebVTT are timed in " +
+			"milliseconds, so --fps is the rate of the video they go with. SCC is timed in 29.97 timecode, and drop frame comes from " +
+			"the file. The operations are applied in this order:" +
+			"\n  --to-fps     convert to another frame rate, or between DF and NDF (ie --to-fps=29.97NDF), using --strategy. The " +
+			"default, frames, keeps each cue on the same frame of video, so the captions follow a 25 fps master slowed to 23.976" +
+			"\n  --offset     move every cue by a Go duration, timecode or frame count, ie --offset=-1.5s or --offset=-01:00:00:00" +
+			"\nCues that overlap or aren't on screen for a frame are reported as warnings. An SCC cue is on the frames its caption " +
+			"data is sent in, a word a frame. The captions are printed unless --output is set. Use - to read the file from stdin.",
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 
 			jsonOutput := cmd.Flags().Changed("json-output")
@@ -159,7 +916,7 @@ func main() {
 				}
 
 				if cmd.Flags().Changed("key") {
-					if exists := hasJSONField(timecodetool.CalcResponse{}, keyOutput); !exists {
+					if exists := hasJSONField(timecodetool.CaptionsRetimeResponse{}, keyOutput); !exists {
 						return fmt.Errorf("%s is not a valid key.", keyOutput)
 					}
 
@@ -168,62 +925,153 @@ func main() {
 
 			return nil
 		},
-		Long: "Timecode/Frame calculator. Enter either timecode strings or frame numbers. It will add all these all together and generate span. When entering a timecode the amount of frames added/subtracted is relative to 00:00:00:00, with the timecode entered being inclusive. Use the `-e` flag to make it exclusive.",
 		Run: func(cmd *cobra.Command, args []string) {
-			resp := timecodetool.NewCalculateTimecodes(args[0], args[1:], fps, excludeLastTimecode)
+			var (
+				captions []byte
+				err      error
+			)
+			if args[0] == "-" {
+				captions, err = io.ReadAll(os.Stdin)
+			} else {
+				captions, err = os.ReadFile(args[0])
+			}
+			if err != nil {
+				fmt.Println("Error reading captions:", err)
+				os.Exit(1)
+			}
+
+			resp := timecodetool.NewCaptionsRetime(string(captions), fps, timecodetool.CaptionsRetimeOptions{
+				Format:    captionFormat,
+				TargetFps: targetFps,
+				Strategy:  captionStrategy,
+				Offset:    captionOffset,
+			})
 
 			if jsonOutput {
-				if cmd.Flags().Changed("key") {
-					value, err := GetValueFromStruct(resp, keyOutput)
-					if err != nil {
-						panic(err)
-					}
-					fmt.Println(value)
-				} else if prettyPrintJsonOutput {
-					prettyJSON, err := json.MarshalIndent(resp, "", "  ")
-					if err != nil {
-						fmt.Println("Error encoding JSON:", err)
-						os.Exit(1)
-					}
-					fmt.Println(string(prettyJSON))
-				} else if err := json.NewEncoder(os.Stdout).Encode(resp); err != nil {
-					panic("Error encoding json")
-				}
-			} else {
-				PrettyPrintCalc(resp)
+				writeJSONOutput(cmd, resp)
+				return
+			}
+
+			if !resp.Valid {
+				fmt.Fprintln(os.Stderr, "Error:", resp.ErrorMsg)
+				os.Exit(1)
 			}
+			for _, issue := range resp.Issues {
+				fmt.Fprintln(os.Stderr, "Warning:", issue.Message)
+			}
+			if outputPath == "" {
+				fmt.Print(resp.Captions)
+				return
+			}
+			if err := os.WriteFile(outputPath, []byte(resp.Captions), 0644); err != nil {
+				fmt.Println("Error writing captions:", err)
+				os.Exit(1)
+			}
+			fmt.Printf("Wrote %s (%d cues)\n", outputPath, resp.CueCount)
+		},
+	}
+	captionsRetimeCmd.Flags().BoolVar(&jsonOutput, "json-output", false, "Output as JSON")
+	captionsRetimeCmd.Flags().BoolVar(&prettyPrintJsonOutput, "pretty-print", false, "Output indented JSON")
+	captionsRetimeCmd.Flags().StringVar(&fps, "fps", "29.97", "Frame rate of the video, or of the SCC timecode. Accepts decimals (29.97), fractions (30000/1001) and a DF/NDF suffix (29.97DF)")
+	captionsRetimeCmd.Flags().StringVar(&captionFormat, "format", "", "Format of the file: srt, vtt or scc. Worked out from the file when not set")
+	captionsRetimeCmd.Flags().StringVar(&targetFps, "to-fps", "", "Frame rate to convert to. Accepts the same formats as --fps")
+	captionsRetimeCmd.Flags().StringVar(&captionStrategy, "strategy", "frames", "How cues are mapped by --to-fps: frames, realtime, realtime-floor, realtime-ceil, label or pulldown")
+	captionsRetimeCmd.Flags().StringVar(&captionOffset, "offset", "", "Moves every cue by a Go duration, timecode or frame count. Prefix with - to move back")
+	captionsRetimeCmd.Flags().StringVarP(&outputPath, "output", "o", "", "File to write the captions to, rather than printing them")
+	captionsRetimeCmd.Flags().StringVar(&keyOutput, "key", "", "Specifies the key of which the sole value will be returned.")
+	captionsCmd.AddCommand(captionsRetimeCmd)
 
+	batchCmd := &cobra.Command{
+		Use:   "batch --fps=29.97 [flags] < jobs.csv",
+		Short: "Runs validate, span and calculate jobs read from stdin.",
+		Args:  cobra.NoArgs,
+		Long: "Runs validate, span and calculate jobs read from stdin and writes one JSON response per line, in the same order as the jobs. " +
+			"A job that fails doesn't stop the batch, and the last line is a summary ({\"summary\": {\"jobs\": 3, \"valid\": 2, \"failed\": 1}}). " +
+			"The --fps flag is used for jobs that don't have their own. Jobs can be CSV or JSON Lines:" +
+			"\n  CSV         command,fps,args...  ie span,25,01:00:00:00,01:00:10:00" +
+			"\n  JSON Lines  {\"command\":\"span\",\"fps\":\"25\",\"args\":[\"01:00:00:00\",\"01:00:10:00\"],\"excludeLastTimecode\":true}" +
+			"\nThe format is detected from the input unless --format is set.",
+		Run: func(cmd *cobra.Command, args []string) {
+			_, err := timecodetool.RunBatch(os.Stdin, os.Stdout, timecodetool.BatchOptions{
+				Format:              batchFormat,
+				Fps:                 fps,
+				ExcludeLastTimecode: excludeLastTimecode,
+			})
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Error running batch:", err)
+				os.Exit(1)
+			}
 		},
 	}
-	calcCmd.Flags().BoolVar(&jsonOutput, "json-output", false, "Output as JSON")
-	calcCmd.Flags().BoolVar(&prettyPrintJsonOutput, "pretty-print", false, "Output indented JSON")
-	calcCmd.Flags().BoolVarP(&excludeLastTimecode, "exclude-last-timecode", "e", false, `When entering a timecode to be added or subtracted, the calculations will be based off the timecode, minus one frame. This typically make it easier to read and enter timecode."`)
-	calcCmd.Flags().Float64Var(&fps, "fps", 29.97, "Frame rate of timecodes")
-	calcCmd.Flags().StringVar(&keyOutput, "key", "", "Specifies the key of which the sole value will be returned.")
-	calcCmd.MarkFlagsOneRequired("fps")
+	batchCmd.Flags().StringVar(&fps, "fps", "29.97", "Frame rate of jobs without their own. Accepts decimals (29.97), fractions (30000/1001) and a DF/NDF suffix (29.97DF)")
+	batchCmd.Flags().StringVar(&batchFormat, "format", "auto", "Format of the jobs: auto, csv or jsonl")
+	batchCmd.Flags().BoolVarP(&excludeLastTimecode, "exclude-last-timecode", "e", false, "Sets --exclude-last-timecode for every span and calculate job.")
+
+	replCmd := &cobra.Command{
+		Use:   "repl --fps=29.97",
+		Short: "An interactive calculator that keeps the frame rate and a running result.",
+		Args:  cobra.NoArgs,
+		Long: "An interactive calculator that keeps the frame rate and a running result between lines. " +
+			"Enter a timecode to start from, then add and subtract anything calculate takes. Example session:" +
+			"\n  01:00:00:00" +
+			"\n  + 00:00:10:00" +
+			"\n  set act1 = 01:20:00:00" +
+			"\n  + (act1 - 01:00:00:00) * 2" +
+			"\n  =" +
+			"\nType help in the REPL for all of the commands. Commands can also be piped in, one per line.",
+		Run: func(cmd *cobra.Command, args []string) {
+			// Only prompt when someone is typing.
+			stat, err := os.Stdin.Stat()
+			interactive := err == nil && stat.Mode()&os.ModeCharDevice != 0
+
+			if err := runRepl(os.Stdin, fps, filmGauge, excludeLastTimecode, interactive); err != nil {
+				fmt.Fprintln(os.Stderr, "Error:", err)
+				os.Exit(1)
+			}
+		},
+	}
+	replCmd.Flags().StringVar(&fps, "fps", "29.97", "Frame rate to start with. Accepts decimals (29.97), fractions (30000/1001) and a DF/NDF suffix (29.97DF)")
+	replCmd.Flags().StringVar(&filmGauge, "film-gauge", "", "Allows feet+frames (ie 123+08): 35mm-4perf, 35mm-3perf or 16mm")
+	replCmd.Flags().BoolVarP(&excludeLastTimecode, "exclude-last-timecode", "e", false, "When entering a timecode to be added or subtracted, the calculations will be based off the timecode, minus one frame.")
+
+	serveCmd := &cobra.Command{
+		Use:   "serve [flags]",
+		Short: "Serves every command other than ltc and mtc over HTTP.",
+		Args:  cobra.NoArgs,
+		Long: "Serves /validate, /span, /calculate, /convert, /fix, /edl, /conform, /fcpxml, /otio, /todclock and /captions over HTTP. " +
+			"Each takes GET query parameters or a POST JSON body and returns the same JSON as the --json-output flag of the command. " +
+			"Files are passed as their contents. ltc and mtc read and write audio and MIDI bytes, so they aren't served. " +
+			"The OpenAPI document is served at /openapi.json. Examples:" +
+			"\n  curl 'localhost:8080/span?firstTimecode=01:00:00:00&lastTimecode=01:00:10:00&fps=25'" +
+			"\n  curl localhost:8080/calculate -d '{\"timecode\":\"01:00:00:00\",\"operations\":[\"+\",\"00:00:10:00\"],\"fps\":\"25\"}'",
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Printf("%s serving on %s\n", title, serveAddr)
+			if err := http.ListenAndServe(serveAddr, timecodetool.NewServer()); err != nil {
+				fmt.Println("Error serving:", err)
+				os.Exit(1)
+			}
+		},
+	}
+	serveCmd.Flags().StringVar(&serveAddr, "addr", ":8080", "Address to listen on")
+
+	schemaExamples := ""
+	quotedSchemaNames := make([]string, len(timecodetool.SchemaNames))
+	for i, name := range timecodetool.SchemaNames {
+		schemaExamples += "\n  TimecodeTool schema " + name
+		quotedSchemaNames[i] = strconv.Quote(name)
+	}
 
 	outputSchema := &cobra.Command{
-		Use:   "schema [validate|span|calculate]",
-		Short: "Returns a valid json schema that describes the json output for each tool in the CLI",
-		Long: "Returns a valid json schema that describes the json output of each of the tools in the CLI. Examples:" +
-			"\n  TimecodeTool schema validate" +
-			"\n  TimecodeTool schema span" +
-			"\n  TimecodeTool schema calculate",
+		Use:       "schema [" + strings.Join(timecodetool.SchemaNames, "|") + "]",
+		Short:     "Returns a valid json schema that describes the json output for each tool in the CLI",
+		Long:      "Returns a valid json schema that describes the json output of each of the tools in the CLI. Examples:" + schemaExamples,
 		Args:      cobra.ExactArgs(1), // Expect exactly one argument
-		ValidArgs: []string{"validate", "span", "calculate"},
+		ValidArgs: timecodetool.SchemaNames,
 		Run: func(cmd *cobra.Command, args []string) {
-			var r *jsonschema.Schema
-
-			switch args[0] {
-			case "validate":
-				r = jsonschema.Reflect(&timecodetool.ValidateResponse{})
-			case "span":
-				r = jsonschema.Reflect(&timecodetool.SpanResponse{})
-			case "calculate":
-				r = jsonschema.Reflect(&timecodetool.CalcResponse{})
-			default:
+			r, err := timecodetool.NewSchema(args[0])
+			if err != nil {
 				// Handle invalid argument, could return an error or show a message
-				fmt.Println(`Invalid argument. Valid options are: "validate", "span", "calculate"`)
+				fmt.Println("Invalid argument. Valid options are: " + strings.Join(quotedSchemaNames, ", "))
 				return
 			}
 
@@ -264,7 +1112,7 @@ func main() {
 	// I don't want it to be confusing.
 	docsCmd.Hidden = true
 
-	rootCmd.AddCommand(validateCmd, spanCmd, calcCmd, outputSchema, docsCmd)
+	rootCmd.AddCommand(validateCmd, spanCmd, calcCmd, convertCmd, fixCmd, edlCmd, conformCmd, fcpxmlCmd, otioCmd, ltcCmd, mtcCmd, todClockCmd, captionsCmd, batchCmd, replCmd, serveCmd, outputSchema, docsCmd)
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Printf("Exec error: %s", err)
@@ -296,7 +1144,7 @@ func PrettyPrintValidate(r *timecodetool.ValidateResponse) {
 	fmt.Println(title + " Validate")
 	printSeparator()
 	fmt.Printf("Input Timecode:   %s\n", r.InputTimecode)
-	fmt.Printf("Frame Rate (FPS): %.2f\n", r.InputFps)
+	fmt.Printf("Frame Rate (FPS): %s\n", r.InputFps)
 
 	if r.Valid {
 		dfIndicator := ""
@@ -306,6 +1154,12 @@ func PrettyPrintValidate(r *timecodetool.ValidateResponse) {
 		fmt.Printf("Valid Timecode:   ✅  Yes%s\n", dfIndicator)
 		fmt.Printf("Frame Index:      %d\n", r.FrameIdx)
 		fmt.Printf("Next Timecode:    %s\n", r.NextTimecode)
+		if r.FieldIdx != nil {
+			fmt.Printf("Field Index:      %d\n", *r.FieldIdx)
+		}
+		if r.SmpteWord != "" {
+			fmt.Printf("SMPTE 12M Word:   %s\n", r.SmpteWord)
+		}
 	} else {
 		fmt.Printf("Valid Timecode:   ❌  No\n")
 		fmt.Printf("Error:            %s\n", r.ErrorMsg)
@@ -330,7 +1184,7 @@ func PrettyPrintSpan(r *timecodetool.SpanResponse) {
 	// Print First and Last Timecodes
 	fmt.Printf("First Timecode:    %s\n", printInvalidTimecode(r.InputFirstTimecode))
 	fmt.Printf("Last Timecode:     %s\n", printInvalidTimecode(r.InputLastTimecode))
-	fmt.Printf("Frame Rate (FPS):  %.2f\n", r.InputFps)
+	fmt.Printf("Frame Rate (FPS):  %s\n", r.InputFps)
 
 	// Output based on the validity of the span
 	if r.Valid {
@@ -341,6 +1195,18 @@ func PrettyPrintSpan(r *timecodetool.SpanResponse) {
 		fmt.Printf("Length (Real Time):   %s\n", r.LengthTime)
 		fmt.Printf("Length (Seconds):     %.2f\n", r.LengthSeconds)
 		fmt.Printf("Length (Timecode):    %s\n", r.LengthTimecode)
+		if r.LengthFeetFrames != "" {
+			fmt.Printf("Length (Feet+Frames): %s (%s)\n", r.LengthFeetFrames, r.FilmGauge)
+		}
+		if r.LengthFields != 0 {
+			fmt.Printf("Length (Fields):      %d\n", r.LengthFields)
+		}
+		if r.SampleRate != 0 {
+			fmt.Printf("Length (Samples):     %d (%d Hz)\n", r.LengthSamples, r.SampleRate)
+		}
+		if r.CrossesMidnight {
+			fmt.Printf("Crosses Midnight:     🌙  Yes\n")
+		}
 		fmt.Printf("Next Timecode:        %s\n", r.NextTimecode)
 	} else {
 		fmt.Printf("Valid Span:        ❌  No\n")
@@ -361,36 +1227,510 @@ func PrettyPrintCalc(c *timecodetool.CalcResponse) {
 	// Starting timecode and frames
 	fmt.Printf(" 🎬 Starting Timecode:      %s (Index %d)\n", c.InputFirstTimecode, c.StartFrameIdx)
 
+	// A plain list of additions and subtractions is shown one timecode at a
+	// time. Anything else is shown as each part of the expression.
+	simple := true
+	for _, step := range steps {
+		if step.Operation == "*" || step.Operation == "/" || strings.Contains(step.Expression, "(") {
+			simple = false
+		}
+	}
+
 	// Process each step
 	for _, step := range steps {
-		if step.Operation == "+" {
+		switch {
+		case !simple:
+			fmt.Printf("   🧮  %s = %s (%d frames)\n", step.Expression, step.ResultTimecode, step.Result)
+		case step.Operation == "+":
 			fmt.Printf("   ➕  Add Timecode:         %s (%d frames)\n", step.Timecode, step.Frames)
-		} else if step.Operation == "-" {
+		case step.Operation == "-":
 			fmt.Printf("   ➖  Sub Timecode:         %s (%d frames)\n", step.Timecode, step.Frames)
 		}
 	}
 
+	if !c.Valid {
+		printSeparator()
+		fmt.Printf(" ❌  Error:                 %s\n", c.ErrorMsg)
+		printSeparator()
+		return
+	}
+
 	// Resulting timecode and frames
 	printSeparator()
 	fmt.Printf(" 🟰  Resulting Timecode:    %s (%d total frames)\n", c.LastTimecode, c.LengthFrames)
+	if c.LengthFeetFrames != "" {
+		fmt.Printf(" 🎞️  Feet+Frames:           %s (%s)\n", c.LengthFeetFrames, c.FilmGauge)
+	}
 	fmt.Printf("%d ➡️ %d frame indexes\n", c.StartFrameIdx, c.LastFrameIdx)
 	printSeparator()
 }
 
+// PrettyPrintConvert will display the friendly text output of the Convert command
+func PrettyPrintConvert(r *timecodetool.ConvertResponse) {
+	fmt.Println(title + " Convert")
+	printSeparator()
+	fmt.Printf("Input Timecode:       %s\n", r.InputTimecode)
+	if r.InputLastTimecode != "" {
+		fmt.Printf("Input Last Timecode:  %s\n", r.InputLastTimecode)
+	}
+	fmt.Printf("Frame Rate (FPS):     %s ➡️ %s\n", r.InputFps, r.TargetFps)
+	fmt.Printf("Strategy:             %s\n", r.Strategy)
+
+	if r.Valid {
+		fmt.Printf("Timecode:             %s (Index %d ➡️ %d)\n", r.Timecode, r.FrameIdx, r.TargetFrameIdx)
+		fmt.Printf("Rounding Error:       %.6fs (%.3f frames)\n", r.RoundingErrorSeconds, r.RoundingErrorFrames)
+		if r.InputLastTimecode != "" {
+			fmt.Printf("Last Timecode:        %s (Index %d ➡️ %d)\n", r.LastTimecode, r.LastFrameIdx, r.TargetLastFrameIdx)
+			fmt.Printf("Last Rounding Error:  %.6fs (%.3f frames)\n", r.LastRoundingErrorSeconds, r.LastRoundingErrorFrames)
+			fmt.Printf("Length (Frames):      %d ➡️ %d\n", r.LengthFrames, r.TargetLengthFrames)
+		}
+	} else {
+		fmt.Printf("Valid:                ❌  No\n")
+		fmt.Printf("Error:                %s\n", r.ErrorMsg)
+	}
+
+	printSeparator()
+}
+
+// PrettyPrintFix will display the friendly text output of the Fix command
+func PrettyPrintFix(r *timecodetool.FixResponse) {
+	fmt.Println(title + " Fix")
+	printSeparator()
+	fmt.Printf("Input Timecode:   %s\n", r.InputTimecode)
+	fmt.Printf("Frame Rate (FPS): %s\n", r.InputFps)
+	fmt.Printf("Policy:           %s\n", r.Policy)
+
+	if r.Valid {
+		if r.WasValid {
+			fmt.Printf("Needed Fixing:    ✅  No\n")
+		} else {
+			fmt.Printf("Needed Fixing:    🔧  Yes\n")
+			for _, issue := range r.Issues {
+				fmt.Printf("   ⚠️  %s: %s\n", issue.Kind, issue.Message)
+			}
+		}
+		fmt.Printf("Fixed Timecode:   %s\n", r.Timecode)
+		fmt.Printf("Frame Index:      %d\n", r.FrameIdx)
+	} else {
+		fmt.Printf("Fixable:          ❌  No\n")
+		fmt.Printf("Error:            %s\n", r.ErrorMsg)
+	}
+
+	printSeparator()
+}
+
+// PrettyPrintEdl will display the friendly text output of the Edl command
+func PrettyPrintEdl(r *timecodetool.EdlResponse) {
+	fmt.Println(title + " EDL")
+	printSeparator()
+
+	if !r.Valid {
+		fmt.Printf("Frame Rate (FPS): %s\n", r.InputFps)
+		fmt.Printf("Valid EDL:        ❌  No\n")
+		fmt.Printf("Error:            %s\n", r.ErrorMsg)
+		printSeparator()
+		return
+	}
+
+	dfIndicator := ""
+	if r.IsDf {
+		dfIndicator = " (Drop Frame)"
+	}
+	fmt.Printf("Title:            %s\n", r.Title)
+	fmt.Printf("Frame Rate (FPS): %s%s\n", r.InputFps, dfIndicator)
+	fmt.Printf("Events:           %d\n", r.EventCount)
+	printSeparator()
+
+	for _, e := range r.Events {
+		transition := e.Transition
+		if e.TransitionFrames > 0 {
+			transition = fmt.Sprintf("%s %03d", e.Transition, e.TransitionFrames)
+		}
+		fmt.Printf("%03d  %-8s %-4s %-7s %s %s ➡️ %s %s  (%d frames)\n",
+			e.Number, e.Reel, e.Track, transition, e.SourceIn, e.SourceOut, e.RecordIn, e.RecordOut, e.LengthFrames)
+		if e.ClipName != "" {
+			fmt.Printf("     🎬  %s\n", e.ClipName)
+		}
+		if e.SpeedChange != nil {
+			fmt.Printf("     ⏩  %.1f fps (%.0f%%) from %s\n", e.SpeedChange.Speed, e.SpeedChange.Percent, e.SpeedChange.EntryTimecode)
+		}
+	}
+
+	printSeparator()
+	fmt.Printf("Record In:          %s\n", r.RecordStartTimecode)
+	fmt.Printf("Record Out:         %s\n", r.RecordEndTimecode)
+	fmt.Printf("Length (Frames):    %d\n", r.LengthFrames)
+	fmt.Printf("Length (Real Time): %s\n", r.LengthTime)
+	fmt.Printf("Length (Timecode):  %s\n", r.LengthTimecode)
+
+	if len(r.Gaps) == 0 && len(r.Overlaps) == 0 {
+		fmt.Printf("Gaps/Overlaps:      ✅  None\n")
+	}
+	for _, g := range r.Gaps {
+		fmt.Printf("   ⚠️  Gap on %s between events %03d and %03d: %s - %s (%d frames)\n", g.Track, g.AfterEvent, g.BeforeEvent, g.StartTimecode, g.LastTimecode, g.LengthFrames)
+	}
+	for _, o := range r.Overlaps {
+		fmt.Printf("   ⚠️  Overlap on %s between events %03d and %03d: %s - %s (%d frames)\n", o.Track, o.AfterEvent, o.BeforeEvent, o.StartTimecode, o.LastTimecode, o.LengthFrames)
+	}
+
+	printSeparator()
+}
+
+// PrettyPrintFcpxml will display the friendly text output of the fcpxml command
+func PrettyPrintFcpxml(r *timecodetool.FcpxmlResponse) {
+	fmt.Println(title + " FCPXML")
+	printSeparator()
+
+	if !r.Valid {
+		fmt.Printf("Valid FCPXML:     ❌  No\n")
+		fmt.Printf("Error:            %s\n", r.ErrorMsg)
+		printSeparator()
+		return
+	}
+
+	fmt.Printf("Version:          %s\n", r.Version)
+	fmt.Printf("Sequences:        %d\n", r.SequenceCount)
+
+	for _, s := range r.Sequences {
+		printSeparator()
+		dfIndicator := ""
+		if s.IsDf {
+			dfIndicator = " (Drop Frame)"
+		}
+		fmt.Printf("Project:          %s\n", s.Name)
+		fmt.Printf("Frame Rate (FPS): %s%s\n", s.FrameRate, dfIndicator)
+		fmt.Printf("Clips:            %d\n", s.ClipCount)
+		printSeparator()
+
+		for i, c := range s.Clips {
+			fmt.Printf("%03d  %-10s %3d  %s %s ➡️ %s %s  (%d frames)\n",
+				i+1, c.Kind, c.Lane, c.SourceIn, c.SourceOut, c.RecordIn, c.RecordOut, c.LengthFrames)
+			if c.Name != "" {
+				fmt.Printf("     🎬  %s\n", c.Name)
+			}
+			if !c.FrameAligned {
+				fmt.Printf("     ⚠️  Not on a frame boundary: %s\n", strings.Join(c.UnalignedEdits, ", "))
+			}
+		}
+
+		printSeparator()
+		fmt.Printf("Record In:          %s\n", s.StartTimecode)
+		fmt.Printf("Record Out:         %s\n", s.EndTimecode)
+		fmt.Printf("Length (Frames):    %d\n", s.LengthFrames)
+		fmt.Printf("Length (Timecode):  %s\n", s.LengthTimecode)
+		if s.UnalignedCount == 0 {
+			fmt.Printf("Frame Aligned:      ✅  Yes\n")
+		} else {
+			fmt.Printf("Frame Aligned:      ⚠️  %d clips have edits off a frame boundary\n", s.UnalignedCount)
+		}
+	}
+
+	printSeparator()
+}
+
+// PrettyPrintOtio will display the friendly text output of the otio command
+func PrettyPrintOtio(r *timecodetool.OtioResponse) {
+	fmt.Println(title + " OTIO")
+	printSeparator()
+
+	if !r.Valid {
+		if r.InputFps != "" {
+			fmt.Printf("Frame Rate (FPS): %s\n", r.InputFps)
+		}
+		fmt.Printf("Valid OTIO:       ❌  No\n")
+		fmt.Printf("Error:            %s\n", r.ErrorMsg)
+		printSeparator()
+		return
+	}
+
+	dfIndicator := ""
+	if r.IsDf {
+		dfIndicator = " (Drop Frame)"
+	}
+	fmt.Printf("Timeline:         %s\n", r.Name)
+	fmt.Printf("Frame Rate (FPS): %s%s\n", r.FrameRate, dfIndicator)
+	fmt.Printf("Start:            %s\n", r.StartTimecode)
+	fmt.Printf("Tracks:           %d\n", r.TrackCount)
+	fmt.Printf("Clips:            %d\n", r.ClipCount)
+	printSeparator()
+
+	spanText := func(s *timecodetool.SpanResponse) string {
+		if s == nil {
+			return "(no frames)"
+		}
+		return fmt.Sprintf("%s %s", s.InputFirstTimecode, s.InputLastTimecode)
+	}
+	for i, c := range r.Clips {
+		length := 0
+		if c.RecordSpan != nil {
+			length = c.RecordSpan.LengthFrames
+		}
+		fmt.Printf("%03d  %-4s %s ➡️ %s  (%d frames)\n", i+1, c.Track, spanText(c.SourceSpan), spanText(c.RecordSpan), length)
+		if c.Name != "" {
+			fmt.Printf("     🎬  %s\n", c.Name)
+		}
+		if c.RateMismatch != "" {
+			fmt.Printf("     ⚠️  %s\n", c.RateMismatch)
+		}
+	}
+	for _, t := range r.Transitions {
+		fmt.Printf("     🔀  %s on %s: %s\n", t.TransitionType, t.Track, spanText(t.RecordSpan))
+	}
+
+	printSeparator()
+	if len(r.RateMismatches) == 0 {
+		fmt.Printf("Rate Mismatches:  ✅  None\n")
+	} else {
+		fmt.Printf("Rate Mismatches:  ⚠️  %d\n", len(r.RateMismatches))
+	}
+	printSeparator()
+}
+
+// PrettyPrintLtcDecode will display the friendly text output of the ltc decode command
+func PrettyPrintLtcDecode(r *timecodetool.LtcDecodeResponse) {
+	fmt.Println(title + " LTC Decode")
+	printSeparator()
+
+	if !r.Valid {
+		fmt.Printf("Frame Rate (FPS): %s\n", r.InputFps)
+		fmt.Printf("Valid:            ❌  No\n")
+		fmt.Printf("Error:            %s\n", r.ErrorMsg)
+		printSeparator()
+		return
+	}
+
+	fmt.Printf("Frame Rate (FPS): %s\n", r.InputFps)
+	fmt.Printf("Sample Rate:      %d Hz\n", r.SampleRate)
+	fmt.Printf("Channel:          %d of %d\n", r.Channel, r.Channels)
+	fmt.Printf("Frames:           %d\n", r.FrameCount)
+	if r.FrameCount == 0 {
+		fmt.Printf("LTC:              ❌  None found\n")
+		printSeparator()
+		return
+	}
+	printSeparator()
+
+	// The first frame, then only the frames that break the count, so a long
+	// file stays readable.
+	var previous *timecodetool.LtcFrame
+	for i := range r.Frames {
+		f := &r.Frames[i]
+		if previous != nil && previous.Reverse == f.Reverse && (f.FrameIdx-previous.FrameIdx == 1 || f.FrameIdx-previous.FrameIdx == -1) && i != len(r.Frames)-1 {
+			previous = f
+			continue
+		}
+		direction := "▶️"
+		if f.Reverse {
+			direction = "◀️"
+		}
+		flags := ""
+		if f.IsDf {
+			flags += " DF"
+		}
+		if f.ColorFrame {
+			flags += " CF"
+		}
+		fmt.Printf("%10.3fs  %s  %s  UB %s%s\n", f.Seconds, direction, f.Timecode, f.UserBits, flags)
+		previous = f
+	}
+
+	printSeparator()
+	fmt.Printf("First Timecode:   %s\n", r.FirstTimecode)
+	fmt.Printf("Last Timecode:    %s\n", r.LastTimecode)
+	fmt.Printf("Direction:        %s\n", r.Direction)
+	if r.Discontinuities == 0 {
+		fmt.Printf("Discontinuities:  ✅  None\n")
+	} else {
+		fmt.Printf("Discontinuities:  ⚠️  %d\n", r.Discontinuities)
+	}
+	printSeparator()
+}
+
+// PrettyPrintLtcEncode will display the friendly text output of the ltc encode command
+func PrettyPrintLtcEncode(r *timecodetool.LtcEncodeResponse, path string) {
+	fmt.Println(title + " LTC Encode")
+	printSeparator()
+
+	if !r.Valid {
+		fmt.Printf("Timecode:         %s\n", r.InputTimecode)
+		fmt.Printf("Frame Rate (FPS): %s\n", r.InputFps)
+		fmt.Printf("Valid:            ❌  No\n")
+		fmt.Printf("Error:            %s\n", r.ErrorMsg)
+		printSeparator()
+		return
+	}
+
+	dfIndicator := ""
+	if r.IsDf {
+		dfIndicator = " (Drop Frame)"
+	}
+	fmt.Printf("Wrote:            %s\n", path)
+	fmt.Printf("Frame Rate (FPS): %s%s\n", r.InputFps, dfIndicator)
+	fmt.Printf("Audio:            %d Hz, %d bit mono\n", r.SampleRate, r.BitsPerSample)
+	fmt.Printf("First Timecode:   %s\n", r.StartTimecode)
+	fmt.Printf("Last Timecode:    %s\n", r.LastTimecode)
+	fmt.Printf("Frames:           %d\n", r.FrameCount)
+	fmt.Printf("Length (Seconds): %.3f\n", r.LengthSeconds)
+	fmt.Printf("User Bits:        %s\n", r.UserBits)
+	printSeparator()
+}
+
+// PrettyPrintMtcDump will display the friendly text output of the mtc dump command
+func PrettyPrintMtcDump(r *timecodetool.MtcDumpResponse) {
+	fmt.Println(title + " MTC Dump")
+	printSeparator()
+
+	if !r.Valid {
+		fmt.Printf("Frame Rate (FPS): %s\n", r.InputFps)
+		fmt.Printf("Valid:            ❌  No\n")
+		fmt.Printf("Error:            %s\n", r.ErrorMsg)
+		printSeparator()
+		return
+	}
+
+	fmt.Printf("Full Frames:      %d\n", r.FullFrames)
+	fmt.Printf("Quarter Frames:   %d sets of 8\n", r.QuarterFrames)
+	if r.EventCount == 0 {
+		fmt.Printf("MTC:              ❌  None found\n")
+		printSeparator()
+		return
+	}
+	printSeparator()
+
+	// Full frames, then only the quarter frames that start a run or break
+	// the count, so a long capture stays readable.
+	for i, e := range r.Events {
+		if e.Type == "quarter-frame" && i > 0 && i != len(r.Events)-1 {
+			previous := r.Events[i-1]
+			step := 2
+			if e.Direction == "reverse" {
+				step = -2
+			}
+			if previous.Type == e.Type && previous.Direction == e.Direction && e.FrameIdx-previous.FrameIdx == step {
+				continue
+			}
+		}
+		icon := "⏩"
+		switch {
+		case e.Type == "full-frame":
+			icon = "📍"
+		case e.Direction == "reverse":
+			icon = "⏪"
+		}
+		fmt.Printf("%8d  %s  %s  %s fps  %s\n", e.Offset, icon, e.Timecode, e.Fps, e.Type)
+	}
+
+	printSeparator()
+	fmt.Printf("First Timecode:   %s\n", r.FirstTimecode)
+	fmt.Printf("Last Timecode:    %s\n", r.LastTimecode)
+	if r.Direction != "" {
+		fmt.Printf("Direction:        %s\n", r.Direction)
+	}
+	if r.Discontinuities == 0 {
+		fmt.Printf("Discontinuities:  ✅  None\n")
+	} else {
+		fmt.Printf("Discontinuities:  ⚠️  %d\n", r.Discontinuities)
+	}
+	if r.Invalid > 0 {
+		fmt.Printf("Invalid:          ⚠️  %d messages\n", r.Invalid)
+	}
+	printSeparator()
+}
+
+// PrettyPrintMtcEncode will display the friendly text output of the mtc encode command
+func PrettyPrintMtcEncode(r *timecodetool.MtcEncodeResponse, path string) {
+	fmt.Println(title + " MTC Encode")
+	printSeparator()
+
+	if !r.Valid {
+		fmt.Printf("Timecode:         %s\n", r.InputTimecode)
+		fmt.Printf("Frame Rate (FPS): %s\n", r.InputFps)
+		fmt.Printf("Valid:            ❌  No\n")
+		fmt.Printf("Error:            %s\n", r.ErrorMsg)
+		printSeparator()
+		return
+	}
+
+	dfIndicator := ""
+	if r.IsDf {
+		dfIndicator = " (Drop Frame)"
+	}
+	fmt.Printf("Wrote:            %s (%d bytes)\n", path, r.ByteCount)
+	fmt.Printf("Frame Rate (FPS): %s%s, rate code %d\n", r.InputFps, dfIndicator, r.RateCode)
+	fmt.Printf("First Timecode:   %s\n", r.StartTimecode)
+	fmt.Printf("Last Timecode:    %s\n", r.LastTimecode)
+	fmt.Printf("Frames:           %d\n", r.FrameCount)
+	if r.Reverse {
+		fmt.Printf("Direction:        reverse\n")
+	}
+	printSeparator()
+}
+
+// PrettyPrintTodClock will display the friendly text output of the todclock command
+func PrettyPrintTodClock(r *timecodetool.TodClockResponse) {
+	fmt.Println(title + " Time of Day")
+	printSeparator()
+	fmt.Printf("Input:            %s\n", r.Input)
+	fmt.Printf("Frame Rate (FPS): %s\n", r.InputFps)
+
+	if !r.Valid {
+		fmt.Printf("Valid:            ❌  No\n")
+		fmt.Printf("Error:            %s\n", r.ErrorMsg)
+		printSeparator()
+		return
+	}
+
+	dfIndicator := ""
+	if r.IsDf {
+		dfIndicator = " (Drop Frame)"
+	}
+	fmt.Printf("Timecode:         %s%s\n", r.Timecode, dfIndicator)
+	fmt.Printf("Clock:            %s (%s)\n", r.TimeOfDay, r.Zone)
+	fmt.Printf("Date and Time:    %s\n", r.Clock)
+	fmt.Printf("Drift:            %+.3fs (%+.3fs a day)\n", r.DriftSeconds, r.DriftPerDaySeconds)
+	printSeparator()
+}
+
 // hasJsonField will check to see if a particular field exists.
-// this is used to check if a requested key is valid.
+// this is used to check if a requested key is valid. Options such as
+// omitempty after the name are ignored.
 func hasJSONField(s interface{}, fieldName string) bool {
 	t := reflect.TypeOf(s)
 	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
-		tag := field.Tag.Get("json")
-		if tag == fieldName {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == fieldName {
 			return true
 		}
 	}
 	return false
 }
 
+// writeJSONOutput prints a response for --json-output, indented with
+// --pretty-print, or only one of its values when --key is set.
+func writeJSONOutput(cmd *cobra.Command, resp interface{}) {
+	if cmd.Flags().Changed("key") {
+		key, _ := cmd.Flags().GetString("key")
+		value, err := GetValueFromStruct(resp, key)
+		if err != nil {
+			panic(err)
+		}
+		if value == nil {
+			value = "null"
+		}
+		fmt.Println(value)
+	} else if prettyPrint, _ := cmd.Flags().GetBool("pretty-print"); prettyPrint {
+		prettyJSON, err := json.MarshalIndent(resp, "", "  ")
+		if err != nil {
+			fmt.Println("Error encoding JSON:", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(prettyJSON))
+	} else if err := json.NewEncoder(os.Stdout).Encode(resp); err != nil {
+		panic("Error encoding json")
+	}
+}
+
 // GetValueFromStruct retrieves a value from a struct by its JSON key.
 func GetValueFromStruct(input interface{}, key string) (interface{}, error) {
 	// Marshal the struct to JSON
@@ -408,6 +1748,10 @@ func GetValueFromStruct(input interface{}, key string) (interface{}, error) {
 	// Retrieve the value for the specified key
 	value, ok := m[key]
 	if !ok {
+		// An empty omitempty field is left out of the JSON, but is still a key.
+		if v := reflect.Indirect(reflect.ValueOf(input)); v.Kind() == reflect.Struct && hasJSONField(v.Interface(), key) {
+			return nil, nil
+		}
 		return nil, errors.New("key not found in struct")
 	}
 
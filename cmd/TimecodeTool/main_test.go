@@ -0,0 +1,92 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/marcrleonard/TimecodeTool/pkg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyOutput(t *testing.T) {
+	tests := []struct {
+		name     string
+		resp     any
+		key      string
+		expected any
+	}{
+		{
+			"Plain field",
+			timecodetool.NewSpan("01:00:00:00", "01:00:10:00", "25", timecodetool.SpanOptions{}),
+			"lengthFrames",
+			float64(251),
+		},
+		{
+			"omitempty field",
+			timecodetool.NewSpan("01:00:00:00", "01:00:10:00", "25", timecodetool.SpanOptions{FilmGauge: "35mm-4perf"}),
+			"lengthFeetFrames",
+			"15+11",
+		},
+		{
+			"Empty omitempty field",
+			timecodetool.NewSpan("01:00:00:00", "01:00:10:00", "25", timecodetool.SpanOptions{}),
+			"lengthFeetFrames",
+			nil,
+		},
+		{
+			"Span length in samples",
+			timecodetool.NewSpan("01:00:00:00", "01:00:10:00", "25", timecodetool.SpanOptions{SampleRate: 48000}),
+			"lengthSamples",
+			float64(481920),
+		},
+		{
+			"SMPTE word",
+			timecodetool.NewValidateTimecode("01:00:00:00", "25"),
+			"smpteWord",
+			"01000000",
+		},
+		{
+			"No SMPTE word above 30 fps",
+			timecodetool.NewValidateTimecode("01:00:00:00", "50"),
+			"smpteWord",
+			nil,
+		},
+		{
+			"Field index 0",
+			timecodetool.NewValidateTimecode("00:00:00:00.0", "25"),
+			"fieldIdx",
+			float64(0),
+		},
+		{
+			"No field index",
+			timecodetool.NewValidateTimecode("00:00:00:00", "25"),
+			"fieldIdx",
+			nil,
+		},
+		{
+			"Span length in fields",
+			timecodetool.NewSpan("01:00:00:00.1", "01:00:00:01.0", "25", timecodetool.SpanOptions{Fields: true}),
+			"lengthFields",
+			float64(2),
+		},
+		{
+			"Span sample rate",
+			timecodetool.NewSpan("01:00:00:00", "01:00:10:00", "25", timecodetool.SpanOptions{SampleRate: 48000}),
+			"sampleRate",
+			float64(48000),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.True(t, hasJSONField(reflect.Indirect(reflect.ValueOf(tt.resp)).Interface(), tt.key))
+			value, err := GetValueFromStruct(tt.resp, tt.key)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, value)
+		})
+	}
+
+	require.False(t, hasJSONField(timecodetool.SpanResponse{}, "lengthFeet"))
+	_, err := GetValueFromStruct(timecodetool.SpanResponse{}, "lengthFeet")
+	require.EqualError(t, err, "key not found in struct")
+}
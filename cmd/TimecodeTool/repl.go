@@ -0,0 +1,433 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/marcrleonard/TimecodeTool/internal"
+	"github.com/marcrleonard/TimecodeTool/pkg"
+)
+
+const replHelp = `Commands:
+  01:00:00:00            Start from a timecode (or a variable)
+  + 00:00:10:00          Add to the running result. Anything calculate takes works, ie - (1h + 30s) / 2
+  =                      Show the calculation from the start timecode to the running result
+  fps 23.976             Change the frame rate (29.97DF for drop frame)
+  df on|off              Turn drop frame on or off
+  set act1 = 01:00:00:00 Name a timecode. Leave out "= ..." to name the running result
+  vars                   List the variables
+  undo                   Undo the last change
+  history                List what has been entered. !3 runs line 3 again and !! the last line
+  clear                  Start again
+  help                   Show this help
+  quit                   Leave (or Ctrl-D)`
+
+var replVariableRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// replWordRe finds the words of an expression that could be a variable. Units
+// (ie 30s) start with a digit, so they don't match.
+var replWordRe = regexp.MustCompile(`\b[A-Za-z_][A-Za-z0-9_]*\b`)
+
+var replCommands = map[string]bool{
+	"fps": true, "df": true, "set": true, "vars": true, "undo": true, "history": true,
+	"clear": true, "help": true, "quit": true, "exit": true,
+}
+
+// replState is everything undo can go back to. The running result isn't kept,
+// it is worked out again from the start timecode and the operations.
+type replState struct {
+	rate       internal.FrameRate
+	dropFrame  bool
+	start      string
+	operations []string
+	vars       map[string]string
+}
+
+func (s replState) clone() replState {
+	c := s
+	c.operations = append([]string(nil), s.operations...)
+	c.vars = make(map[string]string, len(s.vars))
+	for name, value := range s.vars {
+		c.vars[name] = value
+	}
+	return c
+}
+
+// fps is the frame rate as the pkg handlers take it, ie "29.97DF".
+func (s replState) fps() string {
+	if s.dropFrame {
+		return s.rate.String() + "DF"
+	}
+	return s.rate.String()
+}
+
+type repl struct {
+	state               replState
+	undo                []replState
+	history             []string
+	excludeLastTimecode bool
+	filmGauge           string
+	gauge               internal.FilmGauge
+}
+
+// runRepl reads commands from in until it runs out or quit is entered. The
+// prompt is only shown when interactive, so a file of commands can be piped in.
+func runRepl(in io.Reader, fps string, filmGauge string, excludeLastTimecode bool, interactive bool) error {
+	rate, df, err := internal.ParseFrameRate(fps)
+	if err != nil {
+		return err
+	}
+	var gauge internal.FilmGauge
+	if filmGauge != "" {
+		if gauge, err = internal.ParseFilmGauge(filmGauge); err != nil {
+			return err
+		}
+	}
+	r := &repl{
+		state:               replState{rate: rate, dropFrame: df, vars: map[string]string{}},
+		excludeLastTimecode: excludeLastTimecode,
+		filmGauge:           filmGauge,
+		gauge:               gauge,
+	}
+
+	if interactive {
+		fmt.Printf("%s REPL at %s fps. Type help for the commands.\n", title, r.state.fps())
+	}
+
+	scanner := bufio.NewScanner(in)
+	for {
+		if interactive {
+			fmt.Print(r.prompt())
+		}
+		if !scanner.Scan() {
+			break
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// History recall runs the line again as if it had been typed.
+		if strings.HasPrefix(line, "!") {
+			recalled, err := r.recall(line)
+			if err != nil {
+				fmt.Printf("❌  %s\n", err)
+				continue
+			}
+			fmt.Println(recalled)
+			line = recalled
+		}
+		if line != "history" {
+			r.history = append(r.history, line)
+		}
+
+		if line == "quit" || line == "exit" {
+			return nil
+		}
+		if err := r.run(line); err != nil {
+			fmt.Printf("❌  %s\n", err)
+		}
+	}
+	if interactive {
+		fmt.Println()
+	}
+	return scanner.Err()
+}
+
+func (r *repl) prompt() string {
+	current, err := r.current()
+	if err != nil || current == nil {
+		return fmt.Sprintf("%s> ", r.state.fps())
+	}
+	return fmt.Sprintf("%s %s> ", r.state.fps(), current.LastTimecode.GetTimecode())
+}
+
+func (r *repl) recall(line string) (string, error) {
+	if len(r.history) == 0 {
+		return "", fmt.Errorf("There is no history yet")
+	}
+	if line == "!!" {
+		return r.history[len(r.history)-1], nil
+	}
+	n, err := strconv.Atoi(line[1:])
+	if err != nil || n < 1 || n > len(r.history) {
+		return "", fmt.Errorf("%s is not in the history. Use !1 to !%d, or !! for the last line", line, len(r.history))
+	}
+	return r.history[n-1], nil
+}
+
+func (r *repl) run(line string) error {
+	command, rest, _ := strings.Cut(line, " ")
+	rest = strings.TrimSpace(rest)
+
+	switch {
+	case line == "help":
+		fmt.Println(replHelp)
+	case line == "history":
+		for i, entry := range r.history {
+			fmt.Printf("%4d  %s\n", i+1, entry)
+		}
+	case line == "vars":
+		r.printVars()
+	case line == "undo":
+		if len(r.undo) == 0 {
+			return fmt.Errorf("There is nothing to undo")
+		}
+		r.state = r.undo[len(r.undo)-1]
+		r.undo = r.undo[:len(r.undo)-1]
+		r.printCurrent()
+	case line == "clear":
+		return r.change(func(s *replState) error {
+			s.start = ""
+			s.operations = nil
+			return nil
+		})
+	case line == "=":
+		return r.show()
+	case command == "fps":
+		return r.change(func(s *replState) error {
+			rate, df, err := internal.ParseFrameRate(rest)
+			if err != nil {
+				return err
+			}
+			s.rate, s.dropFrame = rate, df
+			return s.convertStart()
+		})
+	case command == "df":
+		return r.change(func(s *replState) error {
+			switch rest {
+			case "on":
+				if !s.rate.SupportsDropFrame() {
+					return internal.NewError(internal.ErrInvalidDropFrame, "%s is not a valid framerate for drop frame timecode", s.rate)
+				}
+				s.dropFrame = true
+			case "off":
+				s.dropFrame = false
+			default:
+				return fmt.Errorf("Use df on or df off")
+			}
+			return s.convertStart()
+		})
+	case command == "set":
+		return r.set(rest)
+	case strings.HasPrefix(line, "+") || strings.HasPrefix(line, "-"):
+		return r.change(func(s *replState) error {
+			if s.start == "" {
+				return fmt.Errorf("Enter a timecode to start from first, ie 01:00:00:00")
+			}
+			s.operations = append(s.operations, line)
+			return nil
+		})
+	default:
+		return r.change(func(s *replState) error {
+			start, err := s.timecode(line)
+			if err != nil {
+				return err
+			}
+			s.start = start.GetTimecode()
+			s.operations = nil
+			return nil
+		})
+	}
+	return nil
+}
+
+// change makes a change to a copy of the state, and only keeps it (and an
+// undo step) when the running result can still be worked out.
+func (r *repl) change(f func(s *replState) error) error {
+	next := r.state.clone()
+	if err := f(&next); err != nil {
+		return err
+	}
+	previous := r.state
+	r.state = next
+	if _, err := r.current(); err != nil {
+		r.state = previous
+		return err
+	}
+	r.undo = append(r.undo, previous)
+	r.printCurrent()
+	return nil
+}
+
+// timecode parses a timecode or a variable at the current frame rate.
+func (s replState) timecode(in string) (*internal.Timecode, error) {
+	if value, ok := s.vars[in]; ok {
+		in = value
+	}
+	tc, err := internal.NewTimecodeFromString(in, s.rate)
+	if err != nil {
+		if replVariableRe.MatchString(in) {
+			return nil, fmt.Errorf("%s isn't a variable or a command. Type help for the commands", in)
+		}
+		return nil, err
+	}
+	// The frame rate decides drop frame, whichever delimiter was typed.
+	tc.DropFrame = s.dropFrame
+	if err := tc.Validate(); err != nil {
+		return nil, err
+	}
+	return tc, nil
+}
+
+// convertStart keeps the start timecode when the frame rate changes, as
+// long as it is still a valid timecode.
+func (s *replState) convertStart() error {
+	if s.start == "" {
+		return nil
+	}
+	tc, err := s.timecode(s.start)
+	if err != nil {
+		return fmt.Errorf("%s isn't valid at %s: %w", s.start, s.fps(), err)
+	}
+	s.start = tc.GetTimecode()
+	return nil
+}
+
+// expression replaces the variables of an operation with their timecodes.
+func (s replState) expression(operation string) string {
+	return replWordRe.ReplaceAllStringFunc(operation, func(word string) string {
+		if value, ok := s.vars[word]; ok {
+			return value
+		}
+		return word
+	})
+}
+
+// current works out the running result the same as calculate, with the
+// operations as one expression added to the start timecode, so it can cross
+// midnight. It is nil when there is no start timecode.
+func (r *repl) current() (*internal.TimecodeSpan, error) {
+	s := r.state
+	if s.start == "" {
+		return nil, nil
+	}
+	start, err := s.timecode(s.start)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := r.offset(s.operations)
+	if err != nil {
+		return nil, err
+	}
+	return internal.NewTimecodeSpanFromOffset(start, offset)
+}
+
+// offset is the number of frames the operations add up to.
+func (r *repl) offset(operations []string) (int64, error) {
+	if len(operations) == 0 {
+		return 0, nil
+	}
+	var expression []string
+	for _, operation := range operations {
+		expression = append(expression, r.state.expression(operation))
+	}
+	expr, err := internal.ParseExpression(strings.Join(expression, " "))
+	if err != nil {
+		return 0, err
+	}
+	frames, _, err := expr.Evaluate(r.state.rate, r.state.dropFrame, r.excludeLastTimecode, r.gauge)
+	return frames, err
+}
+
+func (r *repl) printCurrent() {
+	current, err := r.current()
+	switch {
+	case err != nil:
+		fmt.Printf("❌  %s\n", err)
+	case current == nil:
+		fmt.Printf("   %s fps\n", r.state.fps())
+	case len(r.state.operations) == 0:
+		fmt.Printf("   %s (Index %d) at %s fps\n", current.LastTimecode.GetTimecode(), current.LastTimecode.GetFrameIdx(), r.state.fps())
+	default:
+		// The frames of the last line are what it added to the lines before it.
+		operations := r.state.operations
+		before, _ := r.offset(operations[:len(operations)-1])
+		total, _ := r.offset(operations)
+		days := ""
+		switch {
+		case current.Days == 1 || current.Days == -1:
+			days = fmt.Sprintf(" (%+d day)", current.Days)
+		case current.CrossesMidnight():
+			days = fmt.Sprintf(" (%+d days)", current.Days)
+		}
+		fmt.Printf("   %s  🟰  %s%s (%+d frames)\n", operations[len(operations)-1], current.LastTimecode.GetTimecode(), days, total-before)
+	}
+}
+
+// show prints the whole calculation the same as the calculate command.
+func (r *repl) show() error {
+	s := r.state
+	if s.start == "" {
+		return fmt.Errorf("Enter a timecode to start from first, ie 01:00:00:00")
+	}
+	if len(s.operations) == 0 {
+		PrettyPrintValidate(timecodetool.NewValidateTimecode(s.start, s.fps()))
+		return nil
+	}
+	var operations []string
+	for _, operation := range s.operations {
+		operations = append(operations, s.expression(operation))
+	}
+	PrettyPrintCalc(timecodetool.NewCalculate(s.start, operations, s.fps(), timecodetool.CalculateOptions{
+		ExcludeLastTimecode: r.excludeLastTimecode,
+		FilmGauge:           r.filmGauge,
+	}))
+	return nil
+}
+
+// set handles "set name = timecode" and "set name", which names the running result.
+func (r *repl) set(args string) error {
+	name, value, hasValue := strings.Cut(args, "=")
+	name = strings.TrimSpace(name)
+	value = strings.TrimSpace(value)
+
+	if !replVariableRe.MatchString(name) {
+		return fmt.Errorf("%q isn't a valid name. Use letters, digits and _, starting with a letter", name)
+	}
+	if replCommands[name] {
+		return fmt.Errorf("%s is a command, so it can't be a variable", name)
+	}
+
+	var tc *internal.Timecode
+	if hasValue {
+		var err error
+		if tc, err = r.state.timecode(value); err != nil {
+			return err
+		}
+	} else {
+		current, err := r.current()
+		if err != nil {
+			return err
+		}
+		if current == nil {
+			return fmt.Errorf("There is no running result to name. Use set %s = 01:00:00:00", name)
+		}
+		tc = current.LastTimecode
+	}
+
+	r.undo = append(r.undo, r.state.clone())
+	r.state.vars[name] = tc.GetTimecode()
+	fmt.Printf("   %s = %s\n", name, tc.GetTimecode())
+	return nil
+}
+
+func (r *repl) printVars() {
+	if len(r.state.vars) == 0 {
+		fmt.Println("   There are no variables. Use set act1 = 01:00:00:00")
+		return
+	}
+	names := make([]string, 0, len(r.state.vars))
+	for name := range r.state.vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("   %s = %s\n", name, r.state.vars[name])
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// captureStdout returns what f prints, as the REPL prints straight to stdout.
+func captureStdout(t *testing.T, f func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	output := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		output <- string(b)
+	}()
+	f()
+	require.NoError(t, w.Close())
+	return <-output
+}
+
+func TestRepl(t *testing.T) {
+	tests := []struct {
+		name     string
+		script   string
+		fps      string
+		expected []string
+	}{
+		{
+			"Variables, undo and history",
+			"fps 29.97\ndf on\n00:59:59;29\nset act1 = 00:00:10;00\n+ act1\nundo\n!5\n+ 1m\nset end\n",
+			"25",
+			[]string{
+				"   29.97 fps",
+				"   29.97DF fps",
+				"   00:59:59;29 (Index 107891) at 29.97DF fps",
+				"   act1 = 00:00:10;00",
+				"   + act1  🟰  01:00:10;00 (+301 frames)",
+				"   00:59:59;29 (Index 107891) at 29.97DF fps",
+				"+ act1",
+				"   + act1  🟰  01:00:10;00 (+301 frames)",
+				"   + 1m  🟰  01:01:10;00 (+1798 frames)",
+				"   end = 01:01:10;00",
+			},
+		},
+		{
+			// The same as calculate, the running result keeps counting past
+			// midnight and only the first timecode counts its last frame.
+			"Running result crosses midnight",
+			"23:00:00:00\n+ 2h\n+ 00:00:10:00\n+ 00:00:05:00\n- 26h\n",
+			"24",
+			[]string{
+				"   23:00:00:00 (Index 1987200) at 24 fps",
+				"   + 2h  🟰  01:00:00:00 (+1 day) (+172800 frames)",
+				"   + 00:00:10:00  🟰  01:00:10:01 (+1 day) (+241 frames)",
+				"   + 00:00:05:00  🟰  01:00:15:01 (+1 day) (+120 frames)",
+				"   - 26h  🟰  23:00:15:01 (-1 day) (-2246400 frames)",
+			},
+		},
+		{
+			"Errors leave the running result alone",
+			"+ 5\n01:00:00:00\ndf on\n+ nope\n!9\nundo\nundo\n",
+			"25",
+			[]string{
+				"❌  Enter a timecode to start from first, ie 01:00:00:00",
+				"   01:00:00:00 (Index 90000) at 25 fps",
+				"❌  25 is not a valid framerate for drop frame timecode",
+				"❌  \"nope\" at position 3 is not a timecode, frame count, feet+frames, unit (ie 1h, 2m, 30s or 12f) or duration (ie 1h2m3.5s)",
+				"❌  !9 is not in the history. Use !1 to !4, or !! for the last line",
+				"   25 fps",
+				"❌  There is nothing to undo",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := captureStdout(t, func() {
+				require.NoError(t, runRepl(strings.NewReader(tt.script), tt.fps, "", false, false))
+			})
+			require.Equal(t, tt.expected, strings.Split(strings.TrimSuffix(output, "\n"), "\n"))
+		})
+	}
+}
@@ -3,36 +3,156 @@
 package main
 
 import (
-	"bytes"
 	"encoding/json"
-	"fmt"
+	"errors"
+	"strings"
 	"syscall/js"
 
-	"TimecodeTool/handlers"
+	"github.com/marcrleonard/TimecodeTool/internal"
+	"github.com/marcrleonard/TimecodeTool/pkg"
 )
 
-func ProcessInput(this js.Value, args []js.Value) interface{} {
-	if len(args) == 0 {
-		return js.ValueOf("Error: no input provided")
+// defaultFps is the same default as the --fps flag of the CLI.
+const defaultFps = "29.97"
+
+// options are read from the options object passed as the last argument of
+// each function, ie {fps: "25", excludeLastTimecode: true}.
+type options struct {
+	fps                 string
+	excludeLastTimecode bool
+	signed              bool
+}
+
+func readOptions(args []js.Value, i int) options {
+	o := options{fps: defaultFps}
+	if len(args) <= i || args[i].Type() != js.TypeObject {
+		return o
+	}
+	if fps := args[i].Get("fps"); fps.Type() == js.TypeString || fps.Type() == js.TypeNumber {
+		o.fps = jsString(fps)
 	}
-	input := args[0].String()
-	fmt.Println("Input received:", input) // Log the input to the console
+	o.excludeLastTimecode = args[i].Get("excludeLastTimecode").Truthy()
+	o.signed = args[i].Get("signed").Truthy()
+	return o
+}
+
+// jsString converts a string or a number (ie fps: 25) to a string.
+func jsString(v js.Value) string {
+	if v.Type() == js.TypeNumber {
+		return js.Global().Get("String").Invoke(v).String()
+	}
+	return v.String()
+}
 
-	resp := handlers.ValidateTimecode(input, 29.97)
+// toJS converts a response to a plain JS object by way of its JSON tags, so
+// the fields are the same as the JSON output of the CLI.
+func toJS(v any) js.Value {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return errorResult(err)
+	}
+	var out any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return errorResult(err)
+	}
+	return js.ValueOf(out)
+}
 
-	var buf bytes.Buffer
+func errorResult(err error) js.Value {
+	code := timecodetool.ErrorCodeMalformed
+	if errors.Is(err, timecodetool.ErrInvalidOption) {
+		code = timecodetool.ErrorCodeInvalidOption
+	}
+	return toJS(&timecodetool.ErrorResponse{ErrorMsg: err.Error(), ErrorCode: code})
+}
 
-	// Pass the pointer to the buffer to the encoder
-	if err := json.NewEncoder(&buf).Encode(resp); err != nil {
-		return js.ValueOf("Error encoding JSON")
+// stringArgs checks that the first n arguments are strings.
+func stringArgs(name string, args []js.Value, n int) ([]string, error) {
+	if len(args) < n {
+		return nil, internal.NewError(internal.ErrMalformed, "%s needs %d arguments, got %d", name, n, len(args))
+	}
+	out := make([]string, n)
+	for i := 0; i < n; i++ {
+		if args[i].Type() != js.TypeString {
+			return nil, internal.NewError(internal.ErrMalformed, "argument %d of %s must be a string", i+1, name)
+		}
+		out[i] = args[i].String()
 	}
+	return out, nil
+}
 
-	return js.ValueOf(buf.String())
+// validate(timecode, {fps})
+func validate(this js.Value, args []js.Value) any {
+	in, err := stringArgs("validate", args, 1)
+	if err != nil {
+		return errorResult(err)
+	}
+	o := readOptions(args, 1)
+	return toJS(timecodetool.NewValidateTimecode(in[0], o.fps))
+}
+
+// span(firstTimecode, lastTimecode, {fps, excludeLastTimecode, signed})
+func span(this js.Value, args []js.Value) any {
+	in, err := stringArgs("span", args, 2)
+	if err != nil {
+		return errorResult(err)
+	}
+	o := readOptions(args, 2)
+	if o.signed {
+		return toJS(timecodetool.NewSignedSpanTimecode(in[0], in[1], o.fps, o.excludeLastTimecode))
+	}
+	return toJS(timecodetool.NewSpanTimecode(in[0], in[1], o.fps, o.excludeLastTimecode))
+}
+
+// calculate(timecode, operations, {fps, excludeLastTimecode}). The operations
+// are an array (["+", "00:00:10:00"]) or a string ("+ 00:00:30:00 * 4").
+func calculate(this js.Value, args []js.Value) any {
+	in, err := stringArgs("calculate", args, 1)
+	if err != nil {
+		return errorResult(err)
+	}
+
+	var operations []string
+	if len(args) > 1 {
+		switch {
+		case args[1].Type() == js.TypeString:
+			operations = strings.Fields(args[1].String())
+		case args[1].InstanceOf(js.Global().Get("Array")):
+			for i := 0; i < args[1].Length(); i++ {
+				operations = append(operations, jsString(args[1].Index(i)))
+			}
+		}
+	}
+	if len(operations) == 0 {
+		return errorResult(internal.NewError(internal.ErrMalformed, "calculate needs operations, ie [\"+\", \"00:00:10:00\"]"))
+	}
+
+	o := readOptions(args, 2)
+	return toJS(timecodetool.NewCalculateTimecodes(in[0], operations, o.fps, o.excludeLastTimecode))
+}
+
+// schema(name) returns the JSON schema of a function's result, ie schema("span").
+func schema(this js.Value, args []js.Value) any {
+	in, err := stringArgs("schema", args, 1)
+	if err != nil {
+		return errorResult(err)
+	}
+	s, err := timecodetool.NewSchema(in[0])
+	if err != nil {
+		return errorResult(err)
+	}
+	return toJS(s)
 }
 
 func main() {
-	// Expose the `processInput` function to JavaScript
-	js.Global().Set("processInput", js.FuncOf(ProcessInput))
+	// Everything is exposed on a single global, ie timecodeTool.span(...)
+	js.Global().Set("timecodeTool", js.ValueOf(map[string]any{
+		"version":   strings.TrimSpace(timecodetool.VERSION),
+		"validate":  js.FuncOf(validate),
+		"span":      js.FuncOf(span),
+		"calculate": js.FuncOf(calculate),
+		"schema":    js.FuncOf(schema),
+	}))
 
 	// Keep the Go program running
 	select {}
@@ -0,0 +1,117 @@
+// Runs the WebAssembly build under Node and checks the functions it exposes.
+//
+//   make test_wasm
+//
+// or by hand:
+//
+//   GOOS=js GOARCH=wasm go build -o dist/timecodetool.wasm ./cmd/wasm
+//   node cmd/wasm/test.js dist/timecodetool.wasm
+"use strict";
+
+const assert = require("node:assert/strict");
+const { execSync } = require("node:child_process");
+const fs = require("node:fs");
+const path = require("node:path");
+
+// wasm_exec.js moved from misc/wasm to lib/wasm in Go 1.24.
+const goroot = process.env.GOROOT || execSync("go env GOROOT").toString().trim();
+const wasmExec = ["lib/wasm/wasm_exec.js", "misc/wasm/wasm_exec.js"]
+    .map((p) => path.join(goroot, p))
+    .find((p) => fs.existsSync(p));
+require(wasmExec);
+
+const tests = [];
+function test(name, fn) {
+    tests.push({ name, fn });
+}
+
+test("version", (tt) => {
+    assert.match(tt.version, /^\d+\.\d+\.\d+/);
+});
+
+test("validate", (tt) => {
+    const r = tt.validate("01:00:00;00", { fps: "29.97" });
+    assert.equal(r.valid, true);
+    assert.equal(r.isDf, true);
+    assert.equal(r.frameIdx, 107892);
+    assert.equal(r.nextTimecode, "01:00:00;01");
+});
+
+test("validate with a numeric fps", (tt) => {
+    const r = tt.validate("00:00:00:24", { fps: 25 });
+    assert.equal(r.valid, true);
+    assert.equal(r.frameRate, "25/1");
+});
+
+test("validate defaults to 29.97", (tt) => {
+    assert.equal(tt.validate("00:00:00:00").inputFps, "29.97");
+});
+
+test("validate failure", (tt) => {
+    const r = tt.validate("00:00:00:25", { fps: "25" });
+    assert.equal(r.valid, false);
+    assert.equal(r.errorCode, "frame_out_of_range");
+});
+
+test("span", (tt) => {
+    const r = tt.span("00:00:00:00", "00:00:01:00", { fps: "25", excludeLastTimecode: true });
+    assert.equal(r.valid, true);
+    assert.equal(r.lengthFrames, 25);
+    assert.equal(r.lengthTimecode, "00:00:01:00");
+});
+
+test("signed span", (tt) => {
+    const r = tt.span("00:00:01:00", "00:00:00:00", { fps: "25", signed: true });
+    assert.equal(r.lengthFrames, -26);
+});
+
+test("calculate with an array", (tt) => {
+    const r = tt.calculate("01:00:00:00", ["+", "00:00:10:00", "-", "5"], { fps: "25" });
+    assert.equal(r.valid, true);
+    assert.equal(r.lastTimecode, "01:00:09:21");
+    assert.equal(r.Steps.length, 2);
+});
+
+test("calculate with a string", (tt) => {
+    const r = tt.calculate("01:00:00:00", "+ 00:00:10:00", { fps: "25", excludeLastTimecode: true });
+    assert.equal(r.lastTimecode, "01:00:10:00");
+});
+
+test("bad arguments", (tt) => {
+    const r = tt.span("00:00:00:00");
+    assert.equal(r.valid, false);
+    assert.equal(r.errorCode, "malformed");
+});
+
+test("schema", (tt) => {
+    const s = tt.schema("span");
+    assert.equal(s.$ref, "#/$defs/SpanResponse");
+    assert.ok(s.$defs.SpanResponse.properties.lengthFrames);
+    assert.equal(tt.schema("nope").errorCode, "invalid_option");
+});
+
+async function main() {
+    const wasm = process.argv[2] || "dist/timecodetool.wasm";
+    const go = new Go();
+    const { instance } = await WebAssembly.instantiate(fs.readFileSync(wasm), go.importObject);
+    // main never returns, but the functions are set up by the time it blocks.
+    go.run(instance);
+
+    let failed = 0;
+    for (const { name, fn } of tests) {
+        try {
+            fn(globalThis.timecodeTool);
+            console.log(`ok    ${name}`);
+        } catch (err) {
+            failed++;
+            console.log(`FAIL  ${name}\n${err.message}`);
+        }
+    }
+    console.log(`${tests.length - failed}/${tests.length} passed`);
+    process.exit(failed ? 1 : 0);
+}
+
+main().catch((err) => {
+    console.error(err);
+    process.exit(1);
+});
@@ -0,0 +1,173 @@
+package internal
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// webVTTTimestampRe matches the timestamps inside WebVTT cue text, ie
+// "<00:00:01.500>" in karaoke style captions.
+var webVTTTimestampRe = regexp.MustCompile(`<((?:[0-9]+:)?[0-5][0-9]:[0-5][0-9]\.[0-9]{3})>`)
+
+// WriteCaptions writes the captions out in their format. SRT cues without a
+// number are numbered by their position.
+func (c *Captions) WriteCaptions(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	switch c.Format {
+	case CaptionSRT:
+		for i, cue := range c.Cues {
+			id := cue.ID
+			if id == "" {
+				id = strconv.Itoa(i + 1)
+			}
+			fmt.Fprintln(bw, id)
+			c.writeTiming(bw, cue)
+			fmt.Fprintf(bw, "%s\n\n", cue.Text)
+		}
+
+	case CaptionWebVTT:
+		for _, line := range c.Header {
+			fmt.Fprintln(bw, line)
+		}
+		for _, cue := range c.Cues {
+			for _, block := range cue.Blocks {
+				fmt.Fprintf(bw, "\n%s\n", block)
+			}
+			fmt.Fprintln(bw)
+			if cue.ID != "" {
+				fmt.Fprintln(bw, cue.ID)
+			}
+			c.writeTiming(bw, cue)
+			fmt.Fprintln(bw, cue.Text)
+		}
+		for _, block := range c.Trailer {
+			fmt.Fprintf(bw, "\n%s\n", block)
+		}
+
+	case CaptionSCC:
+		for _, line := range c.Header {
+			fmt.Fprintln(bw, line)
+		}
+		for _, cue := range c.Cues {
+			fmt.Fprintf(bw, "\n%s\t%s\n", cue.Timecode.GetTimecode(), cue.Text)
+		}
+
+	default:
+		return NewError(ErrInvalidOption, "%s is not a valid caption format. Valid options are: srt, vtt and scc", c.Format)
+	}
+
+	return bw.Flush()
+}
+
+func (c *Captions) writeTiming(w io.Writer, cue *Cue) {
+	fmt.Fprintf(w, "%s %s %s", c.FormatTime(cue.Start), cueArrow, c.FormatTime(cue.End))
+	if cue.Settings != "" {
+		fmt.Fprintf(w, " %s", cue.Settings)
+	}
+	fmt.Fprintln(w)
+}
+
+// Offset moves every cue by d, which can be negative. SCC cues move by the
+// nearest whole number of frames and roll over at midnight. SRT and WebVTT
+// cues can't be moved before the start of the video.
+func (c *Captions) Offset(d time.Duration) error {
+	if c.Format == CaptionSCC {
+		frames := c.FrameRate.DurationToFrames(d, RoundNearest)
+		for _, cue := range c.Cues {
+			cue.Timecode.AddFrames(int(frames))
+		}
+		return nil
+	}
+
+	return c.retimeCues(func(t time.Duration) (time.Duration, error) {
+		if t+d < 0 {
+			return 0, NewError(ErrFrameOutOfRange, "%s is before the start of the video after an offset of %s", c.FormatTime(t), d)
+		}
+		return t + d, nil
+	})
+}
+
+// ConvertCaptions maps every cue to a new frame rate (or between drop frame
+// and non drop frame) using the given strategy. SRT and WebVTT times are put
+// on the nearest frame at the old rate, converted, and moved to the start of
+// the new frame. ConvertFrameCount keeps each cue on the same frame of
+// video, so it follows the video when it is sped up or slowed down, ie 25 to
+// 23.976. SCC stays at 29.97 or 30.
+func (c *Captions) ConvertCaptions(to FrameRate, toDropFrame bool, strategy ConvertStrategy) error {
+	if toDropFrame && !to.SupportsDropFrame() {
+		return NewError(ErrInvalidDropFrame, "%s is not a valid framerate for drop frame timecode", to)
+	}
+
+	if c.Format == CaptionSCC {
+		if to.Timebase() != 30 {
+			return NewError(ErrUnsupportedRate, "SCC is timed at 29.97 or 30, not %s", to)
+		}
+		for i, cue := range c.Cues {
+			conversion, err := ConvertTimecode(cue.Timecode, to, toDropFrame, strategy)
+			if err != nil {
+				return fmt.Errorf("cue %d: %w", i+1, err)
+			}
+			cue.Timecode = conversion.Timecode
+		}
+	} else {
+		from, fromDropFrame := c.FrameRate, c.DropFrame
+		err := c.retimeCues(func(t time.Duration) (time.Duration, error) {
+			tc, err := NewTimecodeFromDuration(t, from, fromDropFrame, RoundNearest)
+			if err != nil {
+				return 0, err
+			}
+			conversion, err := ConvertTimecode(tc, to, toDropFrame, strategy)
+			if err != nil {
+				return 0, err
+			}
+			return conversion.Timecode.GetDuration(), nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	c.FrameRate = to
+	c.DropFrame = toDropFrame
+	return nil
+}
+
+// retimeCues maps the start and end of every SRT or WebVTT cue, along with
+// the timestamps inside WebVTT cue text.
+func (c *Captions) retimeCues(retime func(time.Duration) (time.Duration, error)) error {
+	for i, cue := range c.Cues {
+		start, err := retime(cue.Start)
+		if err != nil {
+			return fmt.Errorf("cue %d: %w", i+1, err)
+		}
+		end, err := retime(cue.End)
+		if err != nil {
+			return fmt.Errorf("cue %d: %w", i+1, err)
+		}
+
+		if c.Format == CaptionWebVTT {
+			var textErr error
+			cue.Text = webVTTTimestampRe.ReplaceAllStringFunc(cue.Text, func(s string) string {
+				t, err := parseCueTime(s[1 : len(s)-1])
+				if err == nil {
+					t, err = retime(t)
+				}
+				if err != nil {
+					textErr = err
+					return s
+				}
+				return "<" + c.FormatTime(t) + ">"
+			})
+			if textErr != nil {
+				return fmt.Errorf("cue %d: %w", i+1, textErr)
+			}
+		}
+		cue.Start, cue.End = start, end
+	}
+	return nil
+}
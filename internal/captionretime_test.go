@@ -0,0 +1,98 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeCaptions(t *testing.T, c *Captions) string {
+	t.Helper()
+	var out strings.Builder
+	require.NoError(t, c.WriteCaptions(&out))
+	return out.String()
+}
+
+func TestWriteCaptions(t *testing.T) {
+	srt, err := ParseCaptions(strings.NewReader(testSRT), "", FrameRateFromFloat(25), false)
+	require.NoError(t, err)
+	require.Equal(t, strings.ReplaceAll(testSRT, "\r\n", "\n")+"\n", writeCaptions(t, srt))
+
+	vtt, err := ParseCaptions(strings.NewReader(testWebVTT), "", FrameRateFromFloat(25), false)
+	require.NoError(t, err)
+	require.Equal(t, `WEBVTT - Promo
+Kind: captions
+
+STYLE
+::cue { color: yellow }
+
+intro
+00:00:01.000 --> 00:00:04.000 align:start
+Hello <00:02.500>there.
+
+NOTE the next cue is in hours
+
+01:00:00.000 --> 01:00:02.000
+One hour in.
+
+NOTE end
+`, writeCaptions(t, vtt))
+
+	scc, err := ParseCaptions(strings.NewReader(testSCC), "", FrameRateFromFloat(29.97), false)
+	require.NoError(t, err)
+	require.Equal(t, testSCC, writeCaptions(t, scc))
+}
+
+func TestOffsetCaptions(t *testing.T) {
+	vtt, err := ParseCaptions(strings.NewReader(testWebVTT), "", FrameRateFromFloat(25), false)
+	require.NoError(t, err)
+	require.NoError(t, vtt.Offset(-500*time.Millisecond))
+	require.Equal(t, 500*time.Millisecond, vtt.Cues[0].Start)
+	require.Equal(t, "Hello <00:00:02.000>there.", vtt.Cues[0].Text)
+	require.Equal(t, time.Hour-500*time.Millisecond, vtt.Cues[1].Start)
+
+	srt, err := ParseCaptions(strings.NewReader(testSRT), "", FrameRateFromFloat(25), false)
+	require.NoError(t, err)
+	err = srt.Offset(-2 * time.Second)
+	require.ErrorIs(t, err, ErrFrameOutOfRange)
+	require.Equal(t, "cue 1: 00:00:01,000 is before the start of the video after an offset of -2s", err.Error())
+
+	// SCC moves by frames and rolls over at midnight.
+	scc, err := ParseCaptions(strings.NewReader(testSCC), "", FrameRateFromFloat(29.97), false)
+	require.NoError(t, err)
+	require.NoError(t, scc.Offset(-FrameRateFromFloat(29.97).FramesToDuration(30)))
+	require.Equal(t, "23:59:59;22", scc.Cues[0].Timecode.GetTimecode())
+	require.Equal(t, "00:00:01;00", scc.Cues[1].Timecode.GetTimecode())
+}
+
+func TestConvertCaptions(t *testing.T) {
+	// Captions for a 25 fps master moved to the 23.976 master stay on the
+	// same frames, so they get 4.27% later.
+	srt, err := ParseCaptions(strings.NewReader(testSRT), "", FrameRateFromFloat(25), false)
+	require.NoError(t, err)
+	require.NoError(t, srt.ConvertCaptions(FrameRateFromFloat(23.976), false, ConvertFrameCount))
+	require.Equal(t, "00:00:01,043", srt.FormatTime(srt.Cues[0].Start))
+	require.Equal(t, "00:00:03,670", srt.FormatTime(srt.Cues[1].Start))
+	require.Equal(t, "00:00:06,256", srt.FormatTime(srt.Cues[1].End))
+	require.Equal(t, FrameRateFromFloat(23.976), srt.FrameRate)
+
+	// Going to drop frame keeps the clock time, on the nearest frame.
+	vtt, err := ParseCaptions(strings.NewReader(testWebVTT), "", FrameRateFromFloat(29.97), false)
+	require.NoError(t, err)
+	require.NoError(t, vtt.ConvertCaptions(FrameRateFromFloat(29.97), true, ConvertRealtimeNearest))
+	require.True(t, vtt.DropFrame)
+	require.Equal(t, "00:59:59.996", vtt.FormatTime(vtt.Cues[1].Start))
+	require.Equal(t, "Hello <00:00:02.503>there.", vtt.Cues[0].Text)
+
+	// Drop frame to non drop frame SCC keeps the same frames.
+	scc, err := ParseCaptions(strings.NewReader(testSCC), "", FrameRateFromFloat(29.97), false)
+	require.NoError(t, err)
+	require.NoError(t, scc.ConvertCaptions(FrameRateFromFloat(29.97), false, ConvertFrameCount))
+	require.False(t, scc.DropFrame)
+	require.Equal(t, "00:00:02:01", scc.Cues[2].Timecode.GetTimecode())
+
+	err = scc.ConvertCaptions(FrameRateFromFloat(25), false, ConvertFrameCount)
+	require.ErrorIs(t, err, ErrUnsupportedRate)
+}
@@ -0,0 +1,451 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CaptionFormat is a caption or subtitle file format.
+type CaptionFormat string
+
+const (
+	// CaptionSRT is SubRip, timed in milliseconds.
+	CaptionSRT CaptionFormat = "srt"
+	// CaptionWebVTT is WebVTT, timed in milliseconds.
+	CaptionWebVTT CaptionFormat = "vtt"
+	// CaptionSCC is Scenarist SCC, timed in 29.97 (usually drop frame)
+	// timecode.
+	CaptionSCC CaptionFormat = "scc"
+)
+
+// CaptionFormats lists every format in the order they are documented.
+var CaptionFormats = []CaptionFormat{CaptionSRT, CaptionWebVTT, CaptionSCC}
+
+// ParseCaptionFormat will match a format name or file extension, ie "vtt"
+// or ".srt".
+func ParseCaptionFormat(in string) (CaptionFormat, error) {
+	f := CaptionFormat(strings.TrimPrefix(strings.ToLower(strings.TrimSpace(in)), "."))
+	if f == "webvtt" {
+		return CaptionWebVTT, nil
+	}
+	for _, format := range CaptionFormats {
+		if f == format {
+			return format, nil
+		}
+	}
+	return "", NewError(ErrInvalidOption, "%s is not a valid caption format. Valid options are: srt, vtt and scc", in)
+}
+
+// DetectCaptionFormat works out the format from the start of a caption file.
+func DetectCaptionFormat(text string) (CaptionFormat, error) {
+	text = strings.TrimLeft(strings.TrimPrefix(text, "\ufeff"), " \t\r\n")
+	switch {
+	case strings.HasPrefix(text, "WEBVTT"):
+		return CaptionWebVTT, nil
+	case strings.HasPrefix(text, sccHeader):
+		return CaptionSCC, nil
+	case strings.Contains(text, cueArrow):
+		return CaptionSRT, nil
+	}
+	return "", NewError(ErrMalformed, "Could not tell the caption format. Expected SRT, WebVTT or SCC")
+}
+
+// Captions is a parsed caption file. SRT and WebVTT cues are timed in
+// milliseconds, SCC cues in timecode.
+type Captions struct {
+	Format CaptionFormat
+	// FrameRate and DropFrame are the rate of the SCC timecode. For SRT and
+	// WebVTT they are the rate of the video, which cues are placed on when
+	// they are checked or converted.
+	FrameRate FrameRate
+	DropFrame bool
+	// Header is the WEBVTT or Scenarist_SCC line and the header lines after it.
+	Header []string
+	Cues   []*Cue
+	// Trailer is the WebVTT NOTE, STYLE and REGION blocks after the last cue.
+	Trailer []string
+}
+
+// Cue is one caption of a caption file.
+type Cue struct {
+	// ID is the SRT number or the WebVTT cue identifier.
+	ID string
+	// Start and End are the times of an SRT or WebVTT cue from the start of
+	// the video. End is exclusive.
+	Start time.Duration
+	End   time.Duration
+	// Timecode is when an SCC cue is sent. SCC has no end times, the caption
+	// data takes a frame for each word and stays up until it is replaced.
+	Timecode *Timecode
+	// Settings follow the times of an SRT or WebVTT cue, ie "align:start".
+	Settings string
+	// Text is the text of an SRT or WebVTT cue, or the hex words of an SCC cue.
+	Text string
+	// Blocks are the WebVTT NOTE, STYLE and REGION blocks before the cue.
+	Blocks []string
+	// Line is the line number of the cue in the file.
+	Line int
+}
+
+// CaptionIssueKind identifies a problem found with the cues of a caption file.
+type CaptionIssueKind string
+
+const (
+	// CaptionZeroLength is a cue that isn't on screen for a frame, or an SCC
+	// cue with no caption data.
+	CaptionZeroLength CaptionIssueKind = "zero-length"
+	// CaptionOverlap is a cue that starts before the one before it ends. For
+	// SCC it is caption data that is still being sent when the next cue's
+	// timecode comes around.
+	CaptionOverlap CaptionIssueKind = "overlap"
+)
+
+// CaptionIssue is a problem with a cue. Cue and Other are positions in Cues,
+// counting from 1.
+type CaptionIssue struct {
+	Kind CaptionIssueKind
+	Cue  int
+	// Other is the earlier cue of an overlap.
+	Other int
+	// Span is the frames where the cues overlap. It is nil for zero length cues.
+	Span    *TimecodeSpan
+	Message string
+}
+
+const (
+	cueArrow  = "-->"
+	sccHeader = "Scenarist_SCC"
+)
+
+var (
+	cueTimingRe = regexp.MustCompile(`^(\S+)\s+` + cueArrow + `\s+(\S+)\s*(.*)$`)
+	cueTimeRe   = regexp.MustCompile(`^(?:([0-9]+):)?([0-5][0-9]):([0-5][0-9])[,.]([0-9]{1,3})$`)
+	sccWordRe   = regexp.MustCompile(`^[0-9a-fA-F]{4}$`)
+)
+
+// captionBlock is a run of lines with no blank line in it.
+type captionBlock struct {
+	lines []string
+	line  int
+}
+
+// ParseCaptions will read an SRT, WebVTT or SCC file. An empty format is
+// worked out from the file. SRT and WebVTT don't carry a frame rate, so it
+// has to be given. SCC is timed at 29.97 or 30, and drop frame comes from
+// its timecodes unless dropFrame forces it. Errors are prefixed with the line
+// number they were found on.
+func ParseCaptions(r io.Reader, format CaptionFormat, rate FrameRate, dropFrame bool) (*Captions, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, NewError(ErrMalformed, "could not read captions: %s", err)
+	}
+	text := strings.TrimPrefix(strings.ReplaceAll(string(data), "\r\n", "\n"), "\ufeff")
+
+	if format == "" {
+		if format, err = DetectCaptionFormat(text); err != nil {
+			return nil, err
+		}
+	}
+	if dropFrame && !rate.SupportsDropFrame() {
+		return nil, NewError(ErrInvalidDropFrame, "%s is not a valid framerate for drop frame timecode", rate)
+	}
+
+	c := &Captions{Format: format, FrameRate: rate, DropFrame: dropFrame}
+	blocks := splitCaptionBlocks(text)
+	switch format {
+	case CaptionSRT:
+		err = c.parseSRT(blocks)
+	case CaptionWebVTT:
+		err = c.parseWebVTT(blocks)
+	case CaptionSCC:
+		err = c.parseSCC(blocks, dropFrame)
+	default:
+		err = NewError(ErrInvalidOption, "%s is not a valid caption format. Valid options are: srt, vtt and scc", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func splitCaptionBlocks(text string) []captionBlock {
+	var blocks []captionBlock
+	var current *captionBlock
+	for i, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			current = nil
+			continue
+		}
+		if current == nil {
+			blocks = append(blocks, captionBlock{line: i + 1})
+			current = &blocks[len(blocks)-1]
+		}
+		current.lines = append(current.lines, strings.TrimRight(line, " \t"))
+	}
+	return blocks
+}
+
+func (c *Captions) parseSRT(blocks []captionBlock) error {
+	for _, block := range blocks {
+		cue, err := parseTimedCue(block)
+		if err != nil {
+			return err
+		}
+		c.Cues = append(c.Cues, cue)
+	}
+	return nil
+}
+
+func (c *Captions) parseWebVTT(blocks []captionBlock) error {
+	if len(blocks) == 0 || !strings.HasPrefix(blocks[0].lines[0], "WEBVTT") {
+		return NewError(ErrMalformed, "WebVTT must start with WEBVTT")
+	}
+	c.Header = blocks[0].lines
+
+	var pending []string
+	for _, block := range blocks[1:] {
+		if isWebVTTBlock(block.lines[0]) {
+			pending = append(pending, strings.Join(block.lines, "\n"))
+			continue
+		}
+		cue, err := parseTimedCue(block)
+		if err != nil {
+			return err
+		}
+		cue.Blocks = pending
+		pending = nil
+		c.Cues = append(c.Cues, cue)
+	}
+	c.Trailer = pending
+	return nil
+}
+
+// isWebVTTBlock reports whether a block starting with line is a NOTE, STYLE
+// or REGION block rather than a cue.
+func isWebVTTBlock(line string) bool {
+	for _, keyword := range []string{"NOTE", "STYLE", "REGION"} {
+		if rest, ok := strings.CutPrefix(line, keyword); ok && (rest == "" || rest[0] == ' ' || rest[0] == '\t') {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTimedCue parses an SRT or WebVTT cue. The ID line is optional.
+func parseTimedCue(block captionBlock) (*Cue, error) {
+	cue := &Cue{Line: block.line}
+	lines := block.lines
+	if !strings.Contains(lines[0], cueArrow) {
+		cue.ID = lines[0]
+		lines = lines[1:]
+	}
+	if len(lines) == 0 || !strings.Contains(lines[0], cueArrow) {
+		return nil, fmt.Errorf("line %d: %w", block.line, NewError(ErrMalformed, "Cue is missing its times. Expected start --> end"))
+	}
+	timingLine := block.line + len(block.lines) - len(lines)
+
+	m := cueTimingRe.FindStringSubmatch(strings.TrimSpace(lines[0]))
+	if m == nil {
+		return nil, fmt.Errorf("line %d: %w", timingLine, NewError(ErrMalformed, "%s is not a valid cue timing. Expected start --> end", lines[0]))
+	}
+	var err error
+	if cue.Start, err = parseCueTime(m[1]); err != nil {
+		return nil, fmt.Errorf("line %d: %w", timingLine, err)
+	}
+	if cue.End, err = parseCueTime(m[2]); err != nil {
+		return nil, fmt.Errorf("line %d: %w", timingLine, err)
+	}
+	cue.Settings = m[3]
+	cue.Text = strings.Join(lines[1:], "\n")
+	return cue, nil
+}
+
+// parseCueTime parses hh:mm:ss,mmm (SRT) or hh:mm:ss.mmm (WebVTT). WebVTT
+// hours are optional.
+func parseCueTime(s string) (time.Duration, error) {
+	m := cueTimeRe.FindStringSubmatch(s)
+	if m == nil {
+		return 0, NewError(ErrMalformed, "%s is not a valid cue time. Expected hh:mm:ss,mmm or hh:mm:ss.mmm", s)
+	}
+	hours, _ := strconv.Atoi(m[1])
+	minutes, _ := strconv.Atoi(m[2])
+	seconds, _ := strconv.Atoi(m[3])
+	// A short fraction is tenths or hundredths, ie ".5" is 500 ms.
+	ms, _ := strconv.Atoi((m[4] + "00")[:3])
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute +
+		time.Duration(seconds)*time.Second + time.Duration(ms)*time.Millisecond, nil
+}
+
+func (c *Captions) parseSCC(blocks []captionBlock, dropFrame bool) error {
+	if c.FrameRate.Timebase() != 30 {
+		return NewError(ErrUnsupportedRate, "SCC is timed at 29.97 or 30, not %s", c.FrameRate)
+	}
+	if len(blocks) == 0 || !strings.HasPrefix(blocks[0].lines[0], sccHeader) {
+		return NewError(ErrMalformed, "SCC must start with %s V1.0", sccHeader)
+	}
+	c.Header = blocks[0].lines[:1]
+
+	for i, block := range blocks {
+		for j, line := range block.lines {
+			if i == 0 && j == 0 {
+				continue
+			}
+			lineNo := block.line + j
+			fields := strings.Fields(line)
+			tc, err := parseEDLTimecode(fields[0], c.FrameRate, dropFrame)
+			if err != nil {
+				return fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			for _, word := range fields[1:] {
+				if !sccWordRe.MatchString(word) {
+					return fmt.Errorf("line %d: %w", lineNo, NewError(ErrMalformed, "%s is not a valid SCC word. Expected 4 hex digits", word))
+				}
+			}
+			if len(c.Cues) == 0 {
+				c.DropFrame = tc.DropFrame
+			}
+			c.Cues = append(c.Cues, &Cue{Timecode: tc, Text: strings.Join(fields[1:], " "), Line: lineNo})
+		}
+	}
+	return nil
+}
+
+// cueFrames returns the first frame of the cue and the frame after its last.
+// An SCC cue is on the frames its caption data is sent in.
+func (c *Captions) cueFrames(cue *Cue) (in, out int64) {
+	if c.Format == CaptionSCC {
+		in = int64(cue.Timecode.GetFrameIdx())
+		return in, in + int64(len(strings.Fields(cue.Text)))
+	}
+	return c.FrameRate.DurationToFrames(cue.Start, RoundNearest), c.FrameRate.DurationToFrames(cue.End, RoundNearest)
+}
+
+// CueTimes returns the real time the cue starts and ends at, from the start
+// of the video. The end is exclusive.
+func (c *Captions) CueTimes(cue *Cue) (start, end time.Duration) {
+	if c.Format == CaptionSCC {
+		in, out := c.cueFrames(cue)
+		return c.FrameRate.FramesToDuration(in), c.FrameRate.FramesToDuration(out)
+	}
+	return cue.Start, cue.End
+}
+
+// CueTimecodes returns the timecode of the first frame of the cue and of the
+// frame after its last. SRT and WebVTT times go to the nearest frame.
+func (c *Captions) CueTimecodes(cue *Cue) (in, out *Timecode, err error) {
+	inIdx, outIdx := c.cueFrames(cue)
+	perDay := c.FrameRate.FramesPerDay(c.DropFrame)
+	_, inIdx = floorDivmod(inIdx, perDay)
+	_, outIdx = floorDivmod(outIdx, perDay)
+	if in, err = NewTimecodeFromFrames(inIdx, c.FrameRate, c.DropFrame); err != nil {
+		return nil, nil, err
+	}
+	if out, err = NewTimecodeFromFrames(outIdx, c.FrameRate, c.DropFrame); err != nil {
+		return nil, nil, err
+	}
+	return in, out, nil
+}
+
+// CueSpan is the span of frames the cue is on. It is nil when the cue isn't
+// on any frames.
+func (c *Captions) CueSpan(cue *Cue) (*TimecodeSpan, error) {
+	in, out := c.cueFrames(cue)
+	return c.frameSpan(in, out)
+}
+
+// frameSpan is the span from frame in up to, but not including, out. It is
+// nil when out isn't after in.
+func (c *Captions) frameSpan(in, out int64) (*TimecodeSpan, error) {
+	if out <= in {
+		return nil, nil
+	}
+	_, first := floorDivmod(in, c.FrameRate.FramesPerDay(c.DropFrame))
+	firstTimecode, err := NewTimecodeFromFrames(first, c.FrameRate, c.DropFrame)
+	if err != nil {
+		return nil, err
+	}
+	return NewTimecodeSpanFromOffset(firstTimecode, out-in-1)
+}
+
+// FormatTime formats a cue time the way the file writes it, hh:mm:ss,mmm for
+// SRT and hh:mm:ss.mmm otherwise. It is rounded to the nearest millisecond.
+func (c *Captions) FormatTime(d time.Duration) string {
+	separator := "."
+	if c.Format == CaptionSRT {
+		separator = ","
+	}
+	sign := ""
+	if d < 0 {
+		sign = "-"
+		d = -d
+	}
+	ms := roundDiv(int64(d), int64(time.Millisecond))
+	secs, ms := divmod(ms, 1000)
+	minutes, secs := divmod(secs, 60)
+	hours, minutes := divmod(minutes, 60)
+	return fmt.Sprintf("%s%02d:%02d:%02d%s%03d", sign, hours, minutes, secs, separator, ms)
+}
+
+// Issues finds cues that aren't on screen for a frame, and cues that overlap
+// an earlier cue. Cues are compared in start order.
+func (c *Captions) Issues() ([]CaptionIssue, error) {
+	type placed struct {
+		number  int
+		in, out int64
+	}
+	var issues []CaptionIssue
+	var cues []placed
+	for i, cue := range c.Cues {
+		in, out := c.cueFrames(cue)
+		if out <= in {
+			message := fmt.Sprintf("Cue %d is not on screen for a frame", i+1)
+			if c.Format == CaptionSCC {
+				message = fmt.Sprintf("Cue %d has no caption data", i+1)
+			} else if cue.End < cue.Start {
+				message = fmt.Sprintf("Cue %d ends before it starts", i+1)
+			}
+			issues = append(issues, CaptionIssue{Kind: CaptionZeroLength, Cue: i + 1, Message: message})
+			continue
+		}
+		cues = append(cues, placed{number: i + 1, in: in, out: out})
+	}
+
+	if len(cues) == 0 {
+		return issues, nil
+	}
+	sort.SliceStable(cues, func(i, j int) bool {
+		return cues[i].in < cues[j].in
+	})
+	latest := cues[0]
+	for i := 1; i < len(cues); i++ {
+		next := cues[i]
+		// Only the cue that ends last so far needs checking, as any other
+		// overlap is inside it.
+		if next.in < latest.out {
+			span, err := c.frameSpan(next.in, min(latest.out, next.out))
+			if err != nil {
+				return nil, err
+			}
+			issues = append(issues, CaptionIssue{
+				Kind:    CaptionOverlap,
+				Cue:     next.number,
+				Other:   latest.number,
+				Span:    span,
+				Message: fmt.Sprintf("Cue %d overlaps cue %d by %d frames", next.number, latest.number, span.GetTotalFrames()),
+			})
+		}
+		if next.out > latest.out {
+			latest = next
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Cue < issues[j].Cue
+	})
+	return issues, nil
+}
@@ -0,0 +1,190 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testSRT = "1\r\n00:00:01,000 --> 00:00:04,000\r\nHello there.\r\n\r\n" +
+	"2\r\n00:00:03,500 --> 00:00:06,000 X1:100 X2:500\r\nTwo lines\r\nof text.\r\n\r\n" +
+	"3\r\n00:00:07,000 --> 00:00:07,010\r\nToo short.\r\n"
+
+const testWebVTT = `WEBVTT - Promo
+Kind: captions
+
+STYLE
+::cue { color: yellow }
+
+intro
+00:01.000 --> 00:04.000 align:start
+Hello <00:02.500>there.
+
+NOTE the next cue is in hours
+
+01:00:00.000 --> 01:00:02.000
+One hour in.
+
+NOTE end
+`
+
+const testSCC = `Scenarist_SCC V1.0
+
+00:00:00;22	9420 9420 94ae 94ae 9452 9452 97a1 97a1 c1e3 f4e9 efee
+
+00:00:02;00	942c 942c
+
+00:00:02;01	942f 942f
+`
+
+func TestDetectCaptionFormat(t *testing.T) {
+	for text, expected := range map[string]CaptionFormat{
+		testSRT:                CaptionSRT,
+		"\ufeff" + testWebVTT:  CaptionWebVTT,
+		"\n" + testSCC:         CaptionSCC,
+		"1\n00:00:01.000 --> ": CaptionSRT,
+	} {
+		format, err := DetectCaptionFormat(text)
+		require.NoError(t, err)
+		require.Equal(t, expected, format)
+	}
+
+	_, err := DetectCaptionFormat("just some text")
+	require.ErrorIs(t, err, ErrMalformed)
+
+	format, err := ParseCaptionFormat(".WebVTT")
+	require.NoError(t, err)
+	require.Equal(t, CaptionWebVTT, format)
+	_, err = ParseCaptionFormat("ttml")
+	require.ErrorIs(t, err, ErrInvalidOption)
+}
+
+func TestParseSRT(t *testing.T) {
+	captions, err := ParseCaptions(strings.NewReader(testSRT), "", FrameRateFromFloat(25), false)
+	require.NoError(t, err)
+	require.Equal(t, CaptionSRT, captions.Format)
+	require.Len(t, captions.Cues, 3)
+
+	cue := captions.Cues[1]
+	require.Equal(t, "2", cue.ID)
+	require.Equal(t, 3500*time.Millisecond, cue.Start)
+	require.Equal(t, 6*time.Second, cue.End)
+	require.Equal(t, "X1:100 X2:500", cue.Settings)
+	require.Equal(t, "Two lines\nof text.", cue.Text)
+	require.Equal(t, 5, cue.Line)
+
+	in, out, err := captions.CueTimecodes(cue)
+	require.NoError(t, err)
+	require.Equal(t, "00:00:03:13", in.GetTimecode())
+	require.Equal(t, "00:00:06:00", out.GetTimecode())
+	span, err := captions.CueSpan(cue)
+	require.NoError(t, err)
+	require.Equal(t, 62, span.GetTotalFrames())
+	require.Equal(t, "00:00:03,500", captions.FormatTime(cue.Start))
+}
+
+func TestParseWebVTT(t *testing.T) {
+	captions, err := ParseCaptions(strings.NewReader(testWebVTT), CaptionWebVTT, FrameRateFromFloat(23.976), false)
+	require.NoError(t, err)
+	require.Equal(t, []string{"WEBVTT - Promo", "Kind: captions"}, captions.Header)
+	require.Len(t, captions.Cues, 2)
+
+	first := captions.Cues[0]
+	require.Equal(t, "intro", first.ID)
+	require.Equal(t, time.Second, first.Start)
+	require.Equal(t, "align:start", first.Settings)
+	require.Equal(t, []string{"STYLE\n::cue { color: yellow }"}, first.Blocks)
+
+	second := captions.Cues[1]
+	require.Equal(t, "", second.ID)
+	require.Equal(t, time.Hour, second.Start)
+	require.Equal(t, []string{"NOTE the next cue is in hours"}, second.Blocks)
+	require.Equal(t, []string{"NOTE end"}, captions.Trailer)
+
+	// An hour of real time is 3.6 seconds short of 01:00:00:00 at 23.976.
+	in, _, err := captions.CueTimecodes(second)
+	require.NoError(t, err)
+	require.Equal(t, "00:59:56:10", in.GetTimecode())
+}
+
+func TestParseSCC(t *testing.T) {
+	captions, err := ParseCaptions(strings.NewReader(testSCC), "", FrameRateFromFloat(29.97), false)
+	require.NoError(t, err)
+	require.Equal(t, CaptionSCC, captions.Format)
+	require.True(t, captions.DropFrame)
+	require.Equal(t, []string{"Scenarist_SCC V1.0"}, captions.Header)
+	require.Len(t, captions.Cues, 3)
+
+	first := captions.Cues[0]
+	require.Equal(t, "00:00:00;22", first.Timecode.GetTimecode())
+	require.Equal(t, 3, first.Line)
+
+	// The caption data is sent a word a frame.
+	start, end := captions.CueTimes(first)
+	require.Equal(t, captions.FrameRate.FramesToDuration(22), start)
+	require.Equal(t, captions.FrameRate.FramesToDuration(33), end)
+	span, err := captions.CueSpan(first)
+	require.NoError(t, err)
+	require.Equal(t, 11, span.GetTotalFrames())
+}
+
+func TestParseCaptionsErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		format   CaptionFormat
+		fps      float64
+		expected error
+		message  string
+	}{
+		{"Missing times", "1\nHello\n", CaptionSRT, 25, ErrMalformed, "line 1: Cue is missing its times. Expected start --> end"},
+		{"Bad time", "1\n00:00:01,000 --> 00:00:61,000\nHello\n", CaptionSRT, 25, ErrMalformed, "line 2: 00:00:61,000 is not a valid cue time. Expected hh:mm:ss,mmm or hh:mm:ss.mmm"},
+		{"No WEBVTT", "00:01.000 --> 00:02.000\nHello\n", CaptionWebVTT, 25, ErrMalformed, "WebVTT must start with WEBVTT"},
+		{"SCC at 25", testSCC, CaptionSCC, 25, ErrUnsupportedRate, "SCC is timed at 29.97 or 30, not 25"},
+		{"Bad SCC word", "Scenarist_SCC V1.0\n\n00:00:00;00\t9420 94g0\n", CaptionSCC, 29.97, ErrMalformed, "line 3: 94g0 is not a valid SCC word. Expected 4 hex digits"},
+		{"Bad SCC timecode", "Scenarist_SCC V1.0\n\n00:01:00;00\t9420\n", CaptionSCC, 29.97, ErrInvalidDropFrame, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCaptions(strings.NewReader(tt.input), tt.format, FrameRateFromFloat(tt.fps), false)
+			require.ErrorIs(t, err, tt.expected)
+			if tt.message != "" {
+				require.Equal(t, tt.message, err.Error())
+			}
+		})
+	}
+}
+
+func TestCaptionIssues(t *testing.T) {
+	captions, err := ParseCaptions(strings.NewReader(testSRT), "", FrameRateFromFloat(25), false)
+	require.NoError(t, err)
+	captions.Cues = append(captions.Cues, &Cue{Start: 9 * time.Second, End: 8 * time.Second})
+
+	issues, err := captions.Issues()
+	require.NoError(t, err)
+	require.Len(t, issues, 3)
+
+	require.Equal(t, CaptionOverlap, issues[0].Kind)
+	require.Equal(t, 2, issues[0].Cue)
+	require.Equal(t, 1, issues[0].Other)
+	require.Equal(t, "00:00:03:13", issues[0].Span.StartTimecode.GetTimecode())
+	require.Equal(t, "00:00:03:24", issues[0].Span.LastTimecode.GetTimecode())
+	require.Equal(t, "Cue 2 overlaps cue 1 by 12 frames", issues[0].Message)
+
+	require.Equal(t, CaptionZeroLength, issues[1].Kind)
+	require.Nil(t, issues[1].Span)
+	require.Equal(t, "Cue 3 is not on screen for a frame", issues[1].Message)
+	require.Equal(t, "Cue 4 ends before it starts", issues[2].Message)
+
+	// The first SCC cue is still being sent at 00:00:01;00.
+	scc, err := ParseCaptions(strings.NewReader(strings.Replace(testSCC, "00:00:02;00", "00:00:01;00", 1)), "", FrameRateFromFloat(29.97), false)
+	require.NoError(t, err)
+	issues, err = scc.Issues()
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	require.Equal(t, "Cue 2 overlaps cue 1 by 2 frames", issues[0].Message)
+	require.Equal(t, "00:00:01;00", issues[0].Span.StartTimecode.GetTimecode())
+}
@@ -0,0 +1,154 @@
+package internal
+
+import (
+	"strings"
+)
+
+// ConvertStrategy decides how a timecode is mapped from one framerate to another.
+type ConvertStrategy string
+
+const (
+	// ConvertRealtimeNearest keeps the real time position (from 00:00:00:00)
+	// and picks the nearest frame at the new rate.
+	ConvertRealtimeNearest ConvertStrategy = "realtime"
+	// ConvertRealtimeFloor keeps the real time position and picks the frame at or before it.
+	ConvertRealtimeFloor ConvertStrategy = "realtime-floor"
+	// ConvertRealtimeCeil keeps the real time position and picks the frame at or after it.
+	ConvertRealtimeCeil ConvertStrategy = "realtime-ceil"
+	// ConvertFrameCount keeps the frame index, so the real time position changes.
+	ConvertFrameCount ConvertStrategy = "frames"
+	// ConvertLabel keeps the hh:mm:ss of the timecode and scales the frames field.
+	ConvertLabel ConvertStrategy = "label"
+	// ConvertPulldown maps 23.976 <-> 29.97 (or 24 <-> 30) through a 2:3 pulldown cadence.
+	ConvertPulldown ConvertStrategy = "pulldown"
+)
+
+// ConvertStrategies lists every strategy in the order they are documented.
+var ConvertStrategies = []ConvertStrategy{
+	ConvertRealtimeNearest,
+	ConvertRealtimeFloor,
+	ConvertRealtimeCeil,
+	ConvertFrameCount,
+	ConvertLabel,
+	ConvertPulldown,
+}
+
+// pulldownVideoOffsets is the first video frame (in a group of 5) whose
+// first field belongs to each film frame (A, B, C, D) in a 2:3 cadence.
+var pulldownVideoOffsets = [4]int64{0, 1, 3, 4}
+
+// pulldownFilmOffsets is the film frame (in a group of 4) that provides the
+// first field of each video frame in a 2:3 cadence.
+var pulldownFilmOffsets = [5]int64{0, 1, 1, 2, 3}
+
+// ParseConvertStrategy will match a strategy name, ie "realtime-floor".
+func ParseConvertStrategy(in string) (ConvertStrategy, error) {
+	s := ConvertStrategy(strings.ToLower(strings.TrimSpace(in)))
+	for _, strategy := range ConvertStrategies {
+		if s == strategy {
+			return strategy, nil
+		}
+	}
+	return "", NewError(ErrInvalidOption, "%s is not a valid conversion strategy", in)
+}
+
+// Conversion is the result of converting a timecode to another framerate.
+type Conversion struct {
+	Timecode *Timecode
+	// ErrorSeconds is the real time position of the converted timecode
+	// minus the real time position of the source timecode.
+	ErrorSeconds float64
+	// ErrorFrames is ErrorSeconds expressed in frames at the target rate.
+	ErrorFrames float64
+}
+
+// ConvertTimecode will map a timecode to a new framerate using the given strategy.
+func ConvertTimecode(tc *Timecode, to FrameRate, toDropFrame bool, strategy ConvertStrategy) (*Conversion, error) {
+	if !to.valid() {
+		return nil, NewError(ErrUnsupportedRate, "%d/%d is not a valid framerate", to.Num, to.Den)
+	}
+	if toDropFrame && !to.SupportsDropFrame() {
+		return nil, NewError(ErrInvalidDropFrame, "%s is not a valid framerate for drop frame timecode", to)
+	}
+
+	from := tc.FrameRate
+	srcIdx := int64(tc.GetFrameIdx())
+
+	var (
+		converted *Timecode
+		err       error
+	)
+
+	switch strategy {
+	case ConvertRealtimeNearest, ConvertRealtimeFloor, ConvertRealtimeCeil:
+		// idx / (fromNum/fromDen) seconds == newIdx / (toNum/toDen) seconds
+		num := srcIdx * from.Den * to.Num
+		den := from.Num * to.Den
+		var newIdx int64
+		switch strategy {
+		case ConvertRealtimeFloor:
+			newIdx = num / den
+		case ConvertRealtimeCeil:
+			newIdx = (num + den - 1) / den
+		default:
+			newIdx = roundDiv(num, den)
+		}
+		converted, err = NewTimecodeFromFrames(newIdx, to, toDropFrame)
+	case ConvertFrameCount:
+		converted, err = NewTimecodeFromFrames(srcIdx, to, toDropFrame)
+	case ConvertLabel:
+		converted, err = convertLabel(tc, to, toDropFrame)
+	case ConvertPulldown:
+		converted, err = convertPulldown(srcIdx, from, to, toDropFrame)
+	default:
+		return nil, NewError(ErrInvalidOption, "%s is not a valid conversion strategy", strategy)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The error is (newIdx * toDen / toNum) - (srcIdx * fromDen / fromNum) seconds.
+	newIdx := int64(converted.GetFrameIdx())
+	errNum := newIdx*to.Den*from.Num - srcIdx*from.Den*to.Num
+	errSeconds := float64(errNum) / float64(to.Num*from.Num)
+
+	return &Conversion{
+		Timecode:     converted,
+		ErrorSeconds: errSeconds,
+		ErrorFrames:  errSeconds * to.Float64(),
+	}, nil
+}
+
+func convertLabel(tc *Timecode, to FrameRate, toDropFrame bool) (*Timecode, error) {
+	frames := tc._frames * to.Timebase() / tc.FrameRate.Timebase()
+
+	// Drop frame skips the first frame labels of most minutes. In that case
+	// we snap forward to the first label that exists.
+	dropFrames := to.DropFramesPerMinute()
+	if toDropFrame && tc._secs == 0 && tc._mins%10 != 0 && frames < dropFrames {
+		frames = dropFrames
+	}
+
+	label := formatTimecode(int64(tc._hours), int64(tc._mins), int64(tc._secs), int64(frames), toDropFrame, to)
+	return NewTimecodeFromString(label, to)
+}
+
+func convertPulldown(srcIdx int64, from, to FrameRate, toDropFrame bool) (*Timecode, error) {
+	if from.Den != to.Den {
+		return nil, NewError(ErrUnsupportedRate, "pulldown conversion is only supported between 23.976 and 29.97, or 24 and 30")
+	}
+
+	var newIdx int64
+	switch {
+	case from.Timebase() == 24 && to.Timebase() == 30:
+		group, pos := divmod(srcIdx, 4)
+		newIdx = group*5 + pulldownVideoOffsets[pos]
+	case from.Timebase() == 30 && to.Timebase() == 24:
+		group, pos := divmod(srcIdx, 5)
+		newIdx = group*4 + pulldownFilmOffsets[pos]
+	default:
+		return nil, NewError(ErrUnsupportedRate, "pulldown conversion is only supported between 23.976 and 29.97, or 24 and 30")
+	}
+
+	return NewTimecodeFromFrames(newIdx, to, toDropFrame)
+}
@@ -0,0 +1,85 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertTimecode(t *testing.T) {
+	tests := []struct {
+		name         string
+		timecode     string
+		fromFps      string
+		toFps        string
+		strategy     ConvertStrategy
+		expected     string
+		errorSeconds float64
+	}{
+		{"Realtime 25 to 24", "00:00:01:00", "25", "24", ConvertRealtimeNearest, "00:00:01:00", 0},
+		{"Realtime nearest", "00:00:00:03", "25", "24", ConvertRealtimeNearest, "00:00:00:03", 1.0/24*3 - 3.0/25},
+		{"Realtime floor", "00:00:00:13", "25", "24", ConvertRealtimeFloor, "00:00:00:12", 12.0/24 - 13.0/25},
+		{"Realtime ceil", "00:00:00:13", "25", "24", ConvertRealtimeCeil, "00:00:00:13", 13.0/24 - 13.0/25},
+		{"Realtime DF to NDF", "01:00:00;00", "29.97DF", "30000/1001", ConvertRealtimeNearest, "00:59:56:12", 0},
+		{"Realtime NDF to DF", "00:59:56:12", "29.97", "29.97DF", ConvertRealtimeNearest, "01:00:00;00", 0},
+		{"Frame count", "00:00:01:00", "25", "24", ConvertFrameCount, "00:00:01:01", 25.0/24 - 1},
+		{"Label", "01:00:10:20", "25", "24", ConvertLabel, "01:00:10:19", 3610 + 19.0/24 - (3610 + 20.0/25)},
+		{"Label snaps to valid DF", "00:01:00:00", "30", "29.97DF", ConvertLabel, "00:01:00;02", 1800.0*1001/30000 - 60},
+		{"Pulldown A frame", "00:00:00:04", "23.976", "29.97", ConvertPulldown, "00:00:00:05", 0},
+		{"Pulldown C frame", "00:00:00:06", "23.976", "29.97", ConvertPulldown, "00:00:00:08", 8.0/30 - 6.0/24},
+		{"Pulldown reverse", "00:00:00:08", "29.97", "23.976", ConvertPulldown, "00:00:00:06", 6.0/24 - 8.0/30},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, fromDf, err := ParseFrameRate(tt.fromFps)
+			require.NoError(t, err)
+			to, toDf, err := ParseFrameRate(tt.toFps)
+			require.NoError(t, err)
+
+			tc, err := NewTimecodeFromString(tt.timecode, from)
+			require.NoError(t, err)
+			tc.DropFrame = tc.DropFrame || fromDf
+
+			c, err := ConvertTimecode(tc, to, toDf, tt.strategy)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, c.Timecode.GetTimecode())
+			require.NoError(t, c.Timecode.Validate())
+			// The 1001 rates make the expectations above slightly off, so this
+			// only needs to be within a millisecond.
+			require.InDelta(t, tt.errorSeconds, c.ErrorSeconds, 0.001)
+		})
+	}
+}
+
+func TestConvertPulldownRoundTrip(t *testing.T) {
+	film := FrameRate{24000, 1001}
+	video := FrameRate{30000, 1001}
+
+	for i := int64(0); i < 240; i++ {
+		tc, err := NewTimecodeFromFrames(i, film, false)
+		require.NoError(t, err)
+		c, err := ConvertTimecode(tc, video, false, ConvertPulldown)
+		require.NoError(t, err)
+		back, err := ConvertTimecode(c.Timecode, film, false, ConvertPulldown)
+		require.NoError(t, err)
+		require.Equal(t, tc.GetTimecode(), back.Timecode.GetTimecode())
+	}
+}
+
+func TestConvertErrors(t *testing.T) {
+	tc, err := NewTimecodeFromString("00:00:01:00", FrameRate{25, 1})
+	require.NoError(t, err)
+
+	_, err = ConvertTimecode(tc, FrameRate{24, 1}, true, ConvertRealtimeNearest)
+	require.EqualError(t, err, "24 is not a valid framerate for drop frame timecode")
+
+	_, err = ConvertTimecode(tc, FrameRate{30, 1}, false, ConvertPulldown)
+	require.Error(t, err)
+
+	_, err = ConvertTimecode(tc, FrameRate{0, 1001}, false, ConvertRealtimeNearest)
+	require.ErrorIs(t, err, ErrUnsupportedRate)
+
+	_, err = ParseConvertStrategy("sideways")
+	require.EqualError(t, err, "sideways is not a valid conversion strategy")
+}
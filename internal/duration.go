@@ -0,0 +1,136 @@
+package internal
+
+import (
+	"strings"
+	"time"
+)
+
+// Rounding decides which frame a time between two frames goes to.
+type Rounding string
+
+const (
+	// RoundNearest picks the nearest frame, with halves going away from zero.
+	RoundNearest Rounding = "nearest"
+	// RoundFloor picks the frame at or before the time.
+	RoundFloor Rounding = "floor"
+	// RoundCeil picks the frame at or after the time.
+	RoundCeil Rounding = "ceil"
+)
+
+// Roundings lists every rounding in the order they are documented.
+var Roundings = []Rounding{RoundNearest, RoundFloor, RoundCeil}
+
+// ParseRounding will match a rounding name, ie "floor".
+func ParseRounding(in string) (Rounding, error) {
+	r := Rounding(strings.ToLower(strings.TrimSpace(in)))
+	for _, rounding := range Roundings {
+		if r == rounding {
+			return rounding, nil
+		}
+	}
+	return "", NewError(ErrInvalidOption, "%s is not a valid rounding. Valid options are: nearest, floor and ceil", in)
+}
+
+// DurationToFrames converts real time to a number of frames, rounded to a
+// whole frame. It is done with integer math, so it is exact at any length.
+func (r FrameRate) DurationToFrames(d time.Duration, rounding Rounding) int64 {
+	// Whole seconds and nanoseconds are done separately, as nanoseconds
+	// times the rate would overflow.
+	secs, ns := floorDivmod(int64(d), int64(time.Second))
+	frames, rem := floorDivmod(secs*r.Num, r.Den)
+	whole, frac := floorDivmod(rem*int64(time.Second)+ns*r.Num, r.Den*int64(time.Second))
+	frames += whole
+
+	if frac == 0 {
+		return frames
+	}
+	switch rounding {
+	case RoundFloor:
+	case RoundCeil:
+		frames++
+	default:
+		// frames + frac is negative when frames is, so a half goes down.
+		half := r.Den * int64(time.Second)
+		if frames >= 0 && 2*frac >= half || frames < 0 && 2*frac > half {
+			frames++
+		}
+	}
+	return frames
+}
+
+// GetDuration returns the real time of the timecode from 00:00:00:00,
+// rounded to the nearest nanosecond. A field, subframes or a sample offset
+// move it into the frame.
+func (t *Timecode) GetDuration() time.Duration {
+	idx := int64(t.GetFrameIdx())
+	if t._sampleRate != 0 {
+		samples := t.FrameRate.FramesToSamples(idx, t._sampleRate) + t._sampleOffset
+		secs, rem := divmod(samples, int64(t._sampleRate))
+		return time.Duration(secs)*time.Second + time.Duration(roundDiv(rem*int64(time.Second), int64(t._sampleRate)))
+	}
+	n, d := t.framePosition()
+	return t.FrameRate.FramesToDuration(idx) + time.Duration(roundDiv(n*t.FrameRate.Den*int64(time.Second), d*t.FrameRate.Num))
+}
+
+// NewTimecodeFromDuration will create a Timecode for the frame at a real time
+// from 00:00:00:00. The time rolls over at midnight in either direction.
+func NewTimecodeFromDuration(d time.Duration, frameRate FrameRate, isDropframe bool, rounding Rounding) (*Timecode, error) {
+	if isDropframe && !frameRate.SupportsDropFrame() {
+		return nil, NewError(ErrInvalidDropFrame, "%s is not a valid framerate for drop frame timecode", frameRate)
+	}
+	_, frames := floorDivmod(frameRate.DurationToFrames(d, rounding), frameRate.FramesPerDay(isDropframe))
+	return NewTimecodeFromFrames(frames, frameRate, isDropframe)
+}
+
+// GetDuration returns the real time length of the span, rounded to the
+// nearest nanosecond. It is negative for a negative span.
+func (t *TimecodeSpan) GetDuration() time.Duration {
+	return t.Framerate.FramesToDuration(int64(t.GetTotalFrames()))
+}
+
+// NewTimecodeSpanFromDuration will create a span from the first timecode that
+// lasts a real time, rounded to a whole frame. A negative duration goes back
+// from the first timecode.
+func NewTimecodeSpanFromDuration(firstTimecode *Timecode, d time.Duration, rounding Rounding) (*TimecodeSpan, error) {
+	if firstTimecode == nil {
+		return nil, NewError(ErrMalformed, "A span needs a first timecode")
+	}
+	frames := firstTimecode.FrameRate.DurationToFrames(d, rounding)
+	switch {
+	case frames > 0:
+		return NewTimecodeSpanFromOffset(firstTimecode, frames-1)
+	case frames < 0:
+		return NewTimecodeSpanFromOffset(firstTimecode, frames+1)
+	}
+	return nil, NewError(ErrEmptySpan, "%s is less than a frame at %s", d, firstTimecode.FrameRate)
+}
+
+// parseUnits parses a length in hours, minutes and seconds written the way
+// Go writes durations, ie "30s", "1h30m", "1.5s" or "500ms". Upper case units
+// such as "30S" work too.
+func parseUnits(in string) (time.Duration, bool) {
+	d, err := time.ParseDuration(strings.ToLower(in))
+	if err != nil {
+		return 0, false
+	}
+	return d, true
+}
+
+// unitsToFrames counts hours, minutes and seconds as timecode rather than
+// real time, so 1h is as long as 01:00:00:00 and 30s as 00:00:30:00 at every
+// rate. In drop frame, a minute is 1798 frames (at 29.97) except every tenth
+// minute, so 10m is 17982 frames. Part of a second is rounded to the nearest
+// frame of the timebase, with halves going away from zero.
+func unitsToFrames(d time.Duration, rate FrameRate, dropFrame bool) int64 {
+	sign := int64(1)
+	if d < 0 {
+		sign, d = -1, -d
+	}
+	hours, rem := divmod(int64(d/time.Second), 3600)
+	minutes, seconds := divmod(rem, 60)
+	tc := &Timecode{FrameRate: rate, DropFrame: dropFrame, _hours: int(hours), _mins: int(minutes), _secs: int(seconds)}
+
+	second := int64(time.Second)
+	fraction := (2*int64(d%time.Second)*int64(rate.Timebase()) + second) / (2 * second)
+	return sign * (int64(tc.GetFrameIdx()) + fraction)
+}
@@ -0,0 +1,80 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDurationToFrames(t *testing.T) {
+	tests := []struct {
+		name     string
+		duration time.Duration
+		fps      float64
+		rounding Rounding
+		expected int64
+	}{
+		{"Exact", time.Second, 25, RoundNearest, 25},
+		{"Nearest down", 50 * time.Millisecond, 24, RoundNearest, 1},
+		{"Nearest up", 63 * time.Millisecond, 24, RoundNearest, 2},
+		{"Half goes up", 20 * time.Millisecond, 25, RoundNearest, 1},
+		{"Floor", 79 * time.Millisecond, 25, RoundFloor, 1},
+		{"Ceil", 41 * time.Millisecond, 25, RoundCeil, 2},
+		{"Ceil of a whole frame", 40 * time.Millisecond, 25, RoundCeil, 1},
+		{"An hour at 29.97", time.Hour, 29.97, RoundNearest, 107892},
+		{"A frame at 29.97", 33366667, 29.97, RoundFloor, 1},
+		{"A nanosecond short at 29.97", 33366666, 29.97, RoundFloor, 0},
+		{"Negative half goes down", -20 * time.Millisecond, 25, RoundNearest, -1},
+		{"Negative floor", -time.Millisecond, 25, RoundFloor, -1},
+		{"Negative ceil", -time.Millisecond, 25, RoundCeil, 0},
+		{"A year at 119.88 does not overflow", 365 * 24 * time.Hour, 119.88, RoundNearest, 3780539461},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, FrameRateFromFloat(tt.fps).DurationToFrames(tt.duration, tt.rounding))
+		})
+	}
+}
+
+func TestNewTimecodeFromDuration(t *testing.T) {
+	rate := FrameRateFromFloat(29.97)
+	tc, err := NewTimecodeFromDuration(time.Hour, rate, true, RoundNearest)
+	require.NoError(t, err)
+	require.Equal(t, "01:00:00;00", tc.GetTimecode())
+	require.Equal(t, time.Hour-3600*time.Microsecond, tc.GetDuration())
+
+	tc, err = NewTimecodeFromDuration(-time.Second, FrameRateFromFloat(25), false, RoundNearest)
+	require.NoError(t, err)
+	require.Equal(t, "23:59:59:00", tc.GetTimecode())
+
+	_, err = NewTimecodeFromDuration(time.Second, FrameRateFromFloat(25), true, RoundNearest)
+	require.ErrorIs(t, err, ErrInvalidDropFrame)
+
+	rounding, err := ParseRounding(" Floor")
+	require.NoError(t, err)
+	require.Equal(t, RoundFloor, rounding)
+	_, err = ParseRounding("up")
+	require.ErrorIs(t, err, ErrInvalidOption)
+}
+
+func TestSpanDuration(t *testing.T) {
+	rate := FrameRateFromFloat(25)
+	first, err := NewTimecodeFromString("23:59:00:00", rate)
+	require.NoError(t, err)
+
+	span, err := NewTimecodeSpanFromDuration(first, 2*time.Minute+10*time.Millisecond, RoundNearest)
+	require.NoError(t, err)
+	require.Equal(t, 3000, span.GetTotalFrames())
+	require.Equal(t, "00:00:59:24", span.LastTimecode.GetTimecode())
+	require.Equal(t, 2*time.Minute, span.GetDuration())
+
+	span, err = NewTimecodeSpanFromDuration(first, -time.Second, RoundNearest)
+	require.NoError(t, err)
+	require.Equal(t, -25, span.GetTotalFrames())
+	require.Equal(t, -time.Second, span.GetDuration())
+
+	_, err = NewTimecodeSpanFromDuration(first, 10*time.Millisecond, RoundFloor)
+	require.ErrorIs(t, err, ErrEmptySpan)
+}
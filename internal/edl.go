@@ -0,0 +1,334 @@
+package internal
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EDL is a parsed CMX3600 edit decision list.
+type EDL struct {
+	Title string
+	// DropFrame is the first FCM header of the list. Each event keeps the
+	// FCM that was in effect for it.
+	DropFrame bool
+	FrameRate FrameRate
+	// Comments are the comment lines before the first event.
+	Comments []string
+	Events   []*EDLEvent
+}
+
+// EDLEvent is a single event line of an EDL, along with the comment and
+// motion effect lines that follow it. The out points are exclusive, as
+// they are in the EDL itself.
+type EDLEvent struct {
+	Number int
+	Reel   string
+	// Track is the channel field, ie "V", "A", "A2", "AA" or "B".
+	Track string
+	// Transition is the edit type, ie "C", "D", "W001" or "K B".
+	Transition       string
+	TransitionFrames int
+	DropFrame        bool
+	SourceIn         *Timecode
+	SourceOut        *Timecode
+	RecordIn         *Timecode
+	RecordOut        *Timecode
+	ClipName         string
+	Comments         []string
+	Speed            *EDLSpeed
+	// Line is the line number of the event in the EDL.
+	Line int
+}
+
+// EDLSpeed is an M2 motion effect line.
+type EDLSpeed struct {
+	Reel string
+	// Speed is the playback speed in frames per second. It is negative for
+	// reverse motion.
+	Speed         float64
+	EntryTimecode *Timecode
+}
+
+// EDLDiscontinuity is a gap or an overlap between two consecutive events of
+// the same track, on the record side.
+type EDLDiscontinuity struct {
+	Track string
+	// After and Before are the event numbers either side of it.
+	After  int
+	Before int
+	Span   *TimecodeSpan
+}
+
+const clipNameComment = "FROM CLIP NAME:"
+
+// ParseEDL will read a CMX3600 EDL. The EDL doesn't say what rate it is in,
+// so it has to be given. dropFrame forces drop frame counting, otherwise it
+// is taken from the FCM headers and the timecode delimiters. Errors are
+// prefixed with the line number they were found on.
+func ParseEDL(r io.Reader, rate FrameRate, dropFrame bool) (*EDL, error) {
+	edl := &EDL{FrameRate: rate, DropFrame: dropFrame}
+	fcmDropFrame := dropFrame
+	seenFCM := false
+
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+
+		var last *EDLEvent
+		if len(edl.Events) > 0 {
+			last = edl.Events[len(edl.Events)-1]
+		}
+
+		switch {
+		case strings.HasPrefix(line, "TITLE:"):
+			edl.Title = strings.TrimSpace(strings.TrimPrefix(line, "TITLE:"))
+
+		case strings.HasPrefix(line, "FCM:"):
+			switch strings.ToUpper(strings.TrimSpace(strings.TrimPrefix(line, "FCM:"))) {
+			case "DROP FRAME":
+				if !rate.SupportsDropFrame() {
+					return nil, fmt.Errorf("line %d: %w", lineNo, NewError(ErrInvalidDropFrame, "%s is not a valid framerate for drop frame timecode", rate))
+				}
+				fcmDropFrame = true
+			case "NON-DROP FRAME":
+				fcmDropFrame = dropFrame
+			default:
+				return nil, fmt.Errorf("line %d: %w", lineNo, NewError(ErrMalformed, "%s is not a valid FCM", line))
+			}
+			if !seenFCM {
+				edl.DropFrame = fcmDropFrame
+				seenFCM = true
+			}
+
+		case strings.HasPrefix(line, "*"):
+			comment := strings.TrimSpace(strings.TrimPrefix(line, "*"))
+			if last == nil {
+				edl.Comments = append(edl.Comments, comment)
+				continue
+			}
+			last.Comments = append(last.Comments, comment)
+			if name, ok := strings.CutPrefix(comment, clipNameComment); ok && last.ClipName == "" {
+				last.ClipName = strings.TrimSpace(name)
+			}
+
+		case fields[0] == "M2":
+			if last == nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, NewError(ErrMalformed, "M2 motion effect is not after an event"))
+			}
+			speed, err := parseEDLSpeed(fields, rate, fcmDropFrame)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			// The effect belongs to the event of the same reel, which for a
+			// dissolve might be the line before last.
+			target := last
+			for i := len(edl.Events) - 1; i >= 0 && edl.Events[i].Number == last.Number; i-- {
+				if edl.Events[i].Reel == speed.Reel {
+					target = edl.Events[i]
+					break
+				}
+			}
+			target.Speed = speed
+
+		case isEDLEventNumber(fields[0]):
+			event, err := parseEDLEvent(fields, rate, fcmDropFrame)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			event.Line = lineNo
+			edl.Events = append(edl.Events, event)
+
+		default:
+			// Anything else (SPLIT, AUD etc) is kept as a note on the event.
+			if last == nil {
+				edl.Comments = append(edl.Comments, line)
+			} else {
+				last.Comments = append(last.Comments, line)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, NewError(ErrMalformed, "could not read EDL: %s", err)
+	}
+
+	return edl, nil
+}
+
+func isEDLEventNumber(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseEDLEvent parses "001  AX  V  C  00:00:00:00 00:00:05:00 01:00:00:00 01:00:05:00".
+// The transition can have a modifier and a duration, ie "D 030" or "K B 015".
+func parseEDLEvent(fields []string, rate FrameRate, dropFrame bool) (*EDLEvent, error) {
+	if len(fields) < 8 {
+		return nil, NewError(ErrMalformed, "Event is malformed. Expected event, reel, track, transition and four timecodes")
+	}
+	number, _ := strconv.Atoi(fields[0])
+
+	event := &EDLEvent{
+		Number:     number,
+		Reel:       fields[1],
+		Track:      fields[2],
+		Transition: fields[3],
+		DropFrame:  dropFrame,
+	}
+
+	hasDuration := false
+	for _, extra := range fields[4 : len(fields)-4] {
+		if frames, err := strconv.Atoi(extra); err == nil {
+			event.TransitionFrames = frames
+			hasDuration = true
+		} else {
+			event.Transition += " " + extra
+		}
+	}
+	if event.Transition != "C" && !hasDuration {
+		return nil, NewError(ErrMalformed, "Transition %s needs a duration", event.Transition)
+	}
+
+	timecodes := make([]*Timecode, 4)
+	names := []string{"Source in", "Source out", "Record in", "Record out"}
+	for i, s := range fields[len(fields)-4:] {
+		tc, err := parseEDLTimecode(s, rate, dropFrame)
+		if err != nil {
+			return nil, fmt.Errorf("%s error: %w", names[i], err)
+		}
+		timecodes[i] = tc
+	}
+	event.SourceIn, event.SourceOut, event.RecordIn, event.RecordOut = timecodes[0], timecodes[1], timecodes[2], timecodes[3]
+	event.DropFrame = event.RecordIn.DropFrame
+
+	return event, nil
+}
+
+// parseEDLSpeed parses "M2   AX       050.0                00:00:00:00".
+func parseEDLSpeed(fields []string, rate FrameRate, dropFrame bool) (*EDLSpeed, error) {
+	if len(fields) != 4 {
+		return nil, NewError(ErrMalformed, "M2 motion effect is malformed. Expected reel, speed and entry timecode")
+	}
+	speed, err := strconv.ParseFloat(fields[2], 64)
+	if err != nil {
+		return nil, NewError(ErrMalformed, "%s is not a valid M2 speed", fields[2])
+	}
+	tc, err := parseEDLTimecode(fields[3], rate, dropFrame)
+	if err != nil {
+		return nil, err
+	}
+	return &EDLSpeed{Reel: fields[1], Speed: speed, EntryTimecode: tc}, nil
+}
+
+func parseEDLTimecode(s string, rate FrameRate, dropFrame bool) (*Timecode, error) {
+	tc, err := NewTimecodeFromString(s, rate)
+	if err != nil {
+		return nil, err
+	}
+	if dropFrame {
+		tc.DropFrame = true
+	}
+	if err := tc.Validate(); err != nil {
+		return nil, err
+	}
+	return tc, nil
+}
+
+// edlSpan is the span from in up to, but not including, out. It is nil for
+// zero length events, such as the outgoing side of a dissolve.
+func edlSpan(in, out *Timecode) (*TimecodeSpan, error) {
+	if in.GetFrameIdx() == out.GetFrameIdx() {
+		return nil, nil
+	}
+	last, err := NewTimecodeFromFrames(int64(out.GetFrameIdx())-1, out.FrameRate, out.DropFrame)
+	if err != nil {
+		return nil, err
+	}
+	return NewTimecodeSpan(in, last)
+}
+
+// SourceSpan is the span of the source side of the event. It is nil for zero
+// length events.
+func (e *EDLEvent) SourceSpan() (*TimecodeSpan, error) {
+	return edlSpan(e.SourceIn, e.SourceOut)
+}
+
+// RecordSpan is the span of the record side of the event. It is nil for zero
+// length events.
+func (e *EDLEvent) RecordSpan() (*TimecodeSpan, error) {
+	return edlSpan(e.RecordIn, e.RecordOut)
+}
+
+// RecordSpan is the span from the earliest record in to the latest record out
+// of all events. It is nil if the EDL has no events with a length.
+func (e *EDL) RecordSpan() (*TimecodeSpan, error) {
+	var first, last *Timecode
+	for _, event := range e.Events {
+		if first == nil || event.RecordIn.GetFrameIdx() < first.GetFrameIdx() {
+			first = event.RecordIn
+		}
+		if last == nil || event.RecordOut.GetFrameIdx() > last.GetFrameIdx() {
+			last = event.RecordOut
+		}
+	}
+	if first == nil {
+		return nil, nil
+	}
+	return edlSpan(first, last)
+}
+
+// Discontinuities finds the gaps and overlaps between consecutive events on
+// the record side. Events are compared with the other events on the same
+// track, in record order. Zero length events are skipped.
+func (e *EDL) Discontinuities() (gaps []EDLDiscontinuity, overlaps []EDLDiscontinuity, err error) {
+	tracks := map[string][]*EDLEvent{}
+	var trackOrder []string
+	for _, event := range e.Events {
+		if event.RecordIn.GetFrameIdx() == event.RecordOut.GetFrameIdx() {
+			continue
+		}
+		if _, ok := tracks[event.Track]; !ok {
+			trackOrder = append(trackOrder, event.Track)
+		}
+		tracks[event.Track] = append(tracks[event.Track], event)
+	}
+
+	for _, track := range trackOrder {
+		events := tracks[track]
+		sort.SliceStable(events, func(i, j int) bool {
+			return events[i].RecordIn.GetFrameIdx() < events[j].RecordIn.GetFrameIdx()
+		})
+
+		for i := 1; i < len(events); i++ {
+			prev, next := events[i-1], events[i]
+			var span *TimecodeSpan
+			switch {
+			case prev.RecordOut.GetFrameIdx() < next.RecordIn.GetFrameIdx():
+				if span, err = edlSpan(prev.RecordOut, next.RecordIn); err != nil {
+					return nil, nil, err
+				}
+				gaps = append(gaps, EDLDiscontinuity{Track: track, After: prev.Number, Before: next.Number, Span: span})
+			case prev.RecordOut.GetFrameIdx() > next.RecordIn.GetFrameIdx():
+				if span, err = edlSpan(next.RecordIn, prev.RecordOut); err != nil {
+					return nil, nil, err
+				}
+				overlaps = append(overlaps, EDLDiscontinuity{Track: track, After: prev.Number, Before: next.Number, Span: span})
+			}
+		}
+	}
+
+	return gaps, overlaps, nil
+}
@@ -0,0 +1,132 @@
+package internal
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testEDL = `TITLE: PROMO_V3
+FCM: NON-DROP FRAME
+* EXPORTED FROM AVID
+
+001  TAPE01   V     C        01:00:00:00 01:00:05:00 10:00:00:00 10:00:05:00
+* FROM CLIP NAME: INTERVIEW_A
+
+002  TAPE02   V     C        02:00:10:00 02:00:10:00 10:00:05:00 10:00:05:00
+002  TAPE03   V     D    025 03:00:00:00 03:00:04:00 10:00:05:00 10:00:09:00
+* FROM CLIP NAME: BROLL_CITY
+* TO CLIP NAME: BROLL_NIGHT
+
+003  TAPE04   V     C        04:00:00:00 04:00:02:00 10:00:10:00 10:00:12:00
+M2   TAPE04       050.0                04:00:00:00
+
+004  TAPE05   V     C        05:00:00:00 05:00:03:00 10:00:11:12 10:00:14:12
+005  TAPE01   A     C        01:00:00:00 01:00:14:12 10:00:00:00 10:00:14:12
+`
+
+func TestParseEDL(t *testing.T) {
+	edl, err := ParseEDL(strings.NewReader(testEDL), FrameRateFromFloat(25), false)
+	require.NoError(t, err)
+
+	require.Equal(t, "PROMO_V3", edl.Title)
+	require.False(t, edl.DropFrame)
+	require.Equal(t, []string{"EXPORTED FROM AVID"}, edl.Comments)
+	require.Len(t, edl.Events, 6)
+
+	first := edl.Events[0]
+	require.Equal(t, 1, first.Number)
+	require.Equal(t, "TAPE01", first.Reel)
+	require.Equal(t, "V", first.Track)
+	require.Equal(t, "C", first.Transition)
+	require.Equal(t, "INTERVIEW_A", first.ClipName)
+	require.Equal(t, "10:00:05:00", first.RecordOut.GetTimecode())
+	require.Equal(t, 5, first.Line)
+
+	span, err := first.RecordSpan()
+	require.NoError(t, err)
+	require.Equal(t, 125, span.GetTotalFrames())
+
+	// The outgoing side of the dissolve has no length.
+	span, err = edl.Events[1].RecordSpan()
+	require.NoError(t, err)
+	require.Nil(t, span)
+
+	dissolve := edl.Events[2]
+	require.Equal(t, "D", dissolve.Transition)
+	require.Equal(t, 25, dissolve.TransitionFrames)
+	require.Equal(t, "BROLL_CITY", dissolve.ClipName)
+	require.Len(t, dissolve.Comments, 2)
+
+	speed := edl.Events[3].Speed
+	require.NotNil(t, speed)
+	require.Equal(t, "TAPE04", speed.Reel)
+	require.Equal(t, 50.0, speed.Speed)
+	require.Equal(t, "04:00:00:00", speed.EntryTimecode.GetTimecode())
+
+	total, err := edl.RecordSpan()
+	require.NoError(t, err)
+	require.Equal(t, "00:00:14:12", total.GetSpanTimecode())
+}
+
+func TestEDLDiscontinuities(t *testing.T) {
+	edl, err := ParseEDL(strings.NewReader(testEDL), FrameRateFromFloat(25), false)
+	require.NoError(t, err)
+
+	gaps, overlaps, err := edl.Discontinuities()
+	require.NoError(t, err)
+
+	require.Len(t, gaps, 1)
+	require.Equal(t, "V", gaps[0].Track)
+	require.Equal(t, 2, gaps[0].After)
+	require.Equal(t, 3, gaps[0].Before)
+	require.Equal(t, 25, gaps[0].Span.GetTotalFrames())
+	require.Equal(t, "10:00:09:00", gaps[0].Span.StartTimecode.GetTimecode())
+
+	require.Len(t, overlaps, 1)
+	require.Equal(t, 3, overlaps[0].After)
+	require.Equal(t, 4, overlaps[0].Before)
+	require.Equal(t, 13, overlaps[0].Span.GetTotalFrames())
+}
+
+func TestParseEDLDropFrame(t *testing.T) {
+	in := "FCM: DROP FRAME\n001  AX V C 00:00:59:29 00:01:00:02 01:00:59:29 01:01:00:02\n"
+	edl, err := ParseEDL(strings.NewReader(in), FrameRateFromFloat(29.97), false)
+	require.NoError(t, err)
+	require.True(t, edl.DropFrame)
+
+	event := edl.Events[0]
+	require.True(t, event.DropFrame)
+	require.Equal(t, "01:01:00;02", event.RecordOut.GetTimecode())
+	span, err := event.RecordSpan()
+	require.NoError(t, err)
+	require.Equal(t, 1, span.GetTotalFrames())
+}
+
+func TestParseEDLErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		fps      float64
+		expected error
+		line     string
+	}{
+		{"Too few fields", "001 AX V C 01:00:00:00 01:00:01:00\n", 25, ErrMalformed, "line 1:"},
+		{"Bad timecode", "TITLE: X\n001 AX V C 01:00:00:00 01:00:01:00 01:00:00:00 01:00:01:25\n", 25, ErrFrameOutOfRange, "line 2:"},
+		{"Dissolve without duration", "001 AX V D 01:00:00:00 01:00:01:00 01:00:00:00 01:00:01:00\n", 25, ErrMalformed, "line 1:"},
+		{"Drop frame at 25", "FCM: DROP FRAME\n", 25, ErrInvalidDropFrame, "line 1:"},
+		{"Illegal DF label", "FCM: DROP FRAME\n001 AX V C 01:00:00:00 01:00:01:00 01:01:00:00 01:01:01:00\n", 29.97, ErrInvalidDropFrame, "line 2:"},
+		{"Orphan M2", "M2 AX 050.0 01:00:00:00\n", 25, ErrMalformed, "line 1:"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseEDL(strings.NewReader(tt.input), FrameRateFromFloat(tt.fps), false)
+			require.Error(t, err)
+			require.True(t, errors.Is(err, tt.expected), err.Error())
+			require.True(t, strings.HasPrefix(err.Error(), tt.line), err.Error())
+		})
+	}
+}
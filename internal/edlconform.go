@@ -0,0 +1,275 @@
+package internal
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+// WriteEDL writes the EDL out as CMX3600. An FCM header is written at the top
+// and again whenever the drop frame counting of the events changes.
+func (e *EDL) WriteEDL(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "TITLE: %s\n", e.Title)
+	dropFrame := e.DropFrame
+	fmt.Fprintln(bw, fcmHeader(dropFrame))
+	for _, comment := range e.Comments {
+		fmt.Fprintf(bw, "* %s\n", comment)
+	}
+
+	for i, event := range e.Events {
+		if event.DropFrame != dropFrame {
+			dropFrame = event.DropFrame
+			fmt.Fprintln(bw, fcmHeader(dropFrame))
+		}
+		// Events are separated by a blank line, except the lines of a dissolve.
+		if i == 0 || e.Events[i-1].Number != event.Number {
+			fmt.Fprintln(bw)
+		}
+
+		duration := "   "
+		if event.TransitionFrames > 0 || event.Transition != "C" {
+			duration = fmt.Sprintf("%03d", event.TransitionFrames)
+		}
+		fmt.Fprintf(bw, "%03d  %-8s %-5s %-4s %s %s %s %s %s\n",
+			event.Number,
+			event.Reel,
+			event.Track,
+			event.Transition,
+			duration,
+			event.SourceIn.GetTimecode(),
+			event.SourceOut.GetTimecode(),
+			event.RecordIn.GetTimecode(),
+			event.RecordOut.GetTimecode(),
+		)
+
+		hasClipName := false
+		for _, comment := range event.Comments {
+			if strings.HasPrefix(comment, clipNameComment) {
+				hasClipName = true
+			}
+		}
+		if event.ClipName != "" && !hasClipName {
+			fmt.Fprintf(bw, "* %s %s\n", clipNameComment, event.ClipName)
+		}
+		for _, comment := range event.Comments {
+			fmt.Fprintf(bw, "* %s\n", comment)
+		}
+
+		if event.Speed != nil {
+			speed := fmt.Sprintf("%05.1f", math.Abs(event.Speed.Speed))
+			if event.Speed.Speed < 0 {
+				speed = "-" + speed
+			}
+			fmt.Fprintf(bw, "M2   %-8s %-21s%s\n", event.Speed.Reel, speed, event.Speed.EntryTimecode.GetTimecode())
+		}
+	}
+
+	return bw.Flush()
+}
+
+func fcmHeader(dropFrame bool) string {
+	if dropFrame {
+		return "FCM: DROP FRAME"
+	}
+	return "FCM: NON-DROP FRAME"
+}
+
+// Clone returns a deep copy of the EDL, so it can be changed without
+// changing the original.
+func (e *EDL) Clone() *EDL {
+	c := *e
+	c.Comments = append([]string(nil), e.Comments...)
+	c.Events = make([]*EDLEvent, len(e.Events))
+	for i, event := range e.Events {
+		c.Events[i] = event.clone()
+	}
+	return &c
+}
+
+func (e *EDLEvent) clone() *EDLEvent {
+	c := *e
+	c.SourceIn = cloneTimecode(e.SourceIn)
+	c.SourceOut = cloneTimecode(e.SourceOut)
+	c.RecordIn = cloneTimecode(e.RecordIn)
+	c.RecordOut = cloneTimecode(e.RecordOut)
+	c.Comments = append([]string(nil), e.Comments...)
+	if e.Speed != nil {
+		speed := *e.Speed
+		speed.EntryTimecode = cloneTimecode(e.Speed.EntryTimecode)
+		c.Speed = &speed
+	}
+	return &c
+}
+
+func cloneTimecode(tc *Timecode) *Timecode {
+	if tc == nil {
+		return nil
+	}
+	c := *tc
+	return &c
+}
+
+// Offset moves the record side of every event by the given amount of frames,
+// which can be negative. Record timecodes roll over at midnight.
+func (e *EDL) Offset(frames int64) {
+	for _, event := range e.Events {
+		event.RecordIn.AddFrames(int(frames))
+		event.RecordOut.AddFrames(int(frames))
+	}
+}
+
+// Renumber numbers the events from start, in the order they are listed. The
+// lines of a dissolve (a zero length line followed by a line with the same
+// number) keep sharing a number.
+func (e *EDL) Renumber(start int) {
+	numbers := make([]int, len(e.Events))
+	number := start - 1
+	for i, event := range e.Events {
+		if i == 0 || !isDissolvePair(e.Events[i-1], event) {
+			number++
+		}
+		numbers[i] = number
+	}
+	for i, event := range e.Events {
+		event.Number = numbers[i]
+	}
+}
+
+// isDissolvePair reports whether next is the incoming side of a dissolve
+// (or wipe or key) from prev.
+func isDissolvePair(prev, next *EDLEvent) bool {
+	return prev.Number == next.Number &&
+		prev.RecordIn.GetFrameIdx() == prev.RecordOut.GetFrameIdx() &&
+		prev.RecordOut.GetFrameIdx() == next.RecordIn.GetFrameIdx()
+}
+
+// ConvertEDL maps every timecode of the EDL to a new framerate (or between
+// drop frame and non drop frame) using the given strategy. Transition
+// durations are scaled to the new rate and M2 speeds keep their percentage.
+func (e *EDL) ConvertEDL(to FrameRate, toDropFrame bool, strategy ConvertStrategy) error {
+	if toDropFrame && !to.SupportsDropFrame() {
+		return NewError(ErrInvalidDropFrame, "%s is not a valid framerate for drop frame timecode", to)
+	}
+	from := e.FrameRate
+
+	convert := func(tc *Timecode) (*Timecode, error) {
+		conversion, err := ConvertTimecode(tc, to, toDropFrame, strategy)
+		if err != nil {
+			return nil, err
+		}
+		return conversion.Timecode, nil
+	}
+
+	for _, event := range e.Events {
+		for _, tc := range []**Timecode{&event.SourceIn, &event.SourceOut, &event.RecordIn, &event.RecordOut} {
+			converted, err := convert(*tc)
+			if err != nil {
+				return fmt.Errorf("event %03d: %w", event.Number, err)
+			}
+			*tc = converted
+		}
+		event.DropFrame = toDropFrame
+		// transition frames * (toNum/toDen) / (fromNum/fromDen)
+		event.TransitionFrames = int(roundDiv(int64(event.TransitionFrames)*to.Num*from.Den, to.Den*from.Num))
+
+		if event.Speed != nil {
+			entry, err := convert(event.Speed.EntryTimecode)
+			if err != nil {
+				return fmt.Errorf("event %03d: %w", event.Number, err)
+			}
+			event.Speed.EntryTimecode = entry
+			event.Speed.Speed = event.Speed.Speed * to.Float64() / from.Float64()
+		}
+	}
+
+	e.FrameRate = to
+	e.DropFrame = toDropFrame
+	return nil
+}
+
+// SplitEDL splits the EDL at a record timecode. Events that end at or before
+// it go in the first EDL, events that start at or after it go in the second.
+// An event that spans it is cut in two, with the source in of the second
+// half moved along to match.
+func SplitEDL(e *EDL, at *Timecode) (*EDL, *EDL, error) {
+	if at.FrameRate != e.FrameRate {
+		return nil, nil, NewError(ErrUnsupportedRate, "The split timecode must have the same framerate as the EDL")
+	}
+	atIdx := int64(at.GetFrameIdx())
+
+	before := e.Clone()
+	before.Events = nil
+	after := e.Clone()
+	after.Events = nil
+
+	for _, original := range e.Events {
+		event := original.clone()
+		inIdx := int64(event.RecordIn.GetFrameIdx())
+		outIdx := int64(event.RecordOut.GetFrameIdx())
+
+		switch {
+		case outIdx <= atIdx && inIdx < atIdx:
+			before.Events = append(before.Events, event)
+		case inIdx >= atIdx:
+			after.Events = append(after.Events, event)
+		default:
+			recordFrames := atIdx - inIdx
+			sourceFrames := recordFrames
+			if event.Speed != nil {
+				sourceFrames = int64(math.Round(float64(recordFrames) * event.Speed.Speed / e.FrameRate.Float64()))
+			}
+
+			head := event.clone()
+			recordOut, err := NewTimecodeFromFrames(atIdx, e.FrameRate, event.RecordIn.DropFrame)
+			if err != nil {
+				return nil, nil, err
+			}
+			head.RecordOut = recordOut
+			head.SourceOut = cloneTimecode(event.SourceIn)
+			head.SourceOut.AddFrames(int(sourceFrames))
+			before.Events = append(before.Events, head)
+
+			// The transition happens at the start of the event, so the
+			// second half is a cut.
+			tail := event
+			tail.Transition = "C"
+			tail.TransitionFrames = 0
+			tail.RecordIn = cloneTimecode(head.RecordOut)
+			tail.SourceIn = cloneTimecode(head.SourceOut)
+			if tail.Speed != nil {
+				tail.Speed.EntryTimecode = cloneTimecode(tail.SourceIn)
+			}
+			after.Events = append(after.Events, tail)
+		}
+	}
+
+	return before, after, nil
+}
+
+// MergeEDLs combines the events of several EDLs into one, sorted by record in.
+// Event numbers are kept, so use Renumber afterwards. The EDLs must share a
+// framerate. The title and header comments are taken from the first EDL.
+func MergeEDLs(edls ...*EDL) (*EDL, error) {
+	if len(edls) == 0 {
+		return nil, NewError(ErrMalformed, "There are no EDLs to merge")
+	}
+
+	merged := edls[0].Clone()
+	for _, edl := range edls[1:] {
+		if edl.FrameRate != merged.FrameRate {
+			return nil, NewError(ErrUnsupportedRate, "EDLs at %s and %s can't be merged", merged.FrameRate, edl.FrameRate)
+		}
+		merged.Events = append(merged.Events, edl.Clone().Events...)
+	}
+
+	sort.SliceStable(merged.Events, func(i, j int) bool {
+		return merged.Events[i].RecordIn.GetFrameIdx() < merged.Events[j].RecordIn.GetFrameIdx()
+	})
+
+	return merged, nil
+}
@@ -0,0 +1,155 @@
+package internal
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func parseTestEDL(t *testing.T, in string, fps float64) *EDL {
+	t.Helper()
+	edl, err := ParseEDL(strings.NewReader(in), FrameRateFromFloat(fps), false)
+	require.NoError(t, err)
+	return edl
+}
+
+func writeTestEDL(t *testing.T, edl *EDL) string {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, edl.WriteEDL(&buf))
+	return buf.String()
+}
+
+func TestWriteEDL(t *testing.T) {
+	edl := parseTestEDL(t, testEDL, 25)
+	out := writeTestEDL(t, edl)
+
+	require.Contains(t, out, "TITLE: PROMO_V3\nFCM: NON-DROP FRAME\n* EXPORTED FROM AVID\n")
+	require.Contains(t, out, "001  TAPE01   V     C        01:00:00:00 01:00:05:00 10:00:00:00 10:00:05:00\n* FROM CLIP NAME: INTERVIEW_A\n")
+	require.Contains(t, out, "002  TAPE03   V     D    025 03:00:00:00 03:00:04:00 10:00:05:00 10:00:09:00\n")
+	require.Contains(t, out, "M2   TAPE04   050.0                04:00:00:00\n")
+
+	// Writing what was read gives the same EDL back.
+	reread := parseTestEDL(t, out, 25)
+	require.Equal(t, out, writeTestEDL(t, reread))
+	require.Len(t, reread.Events, len(edl.Events))
+}
+
+func TestEDLOffset(t *testing.T) {
+	edl := parseTestEDL(t, testEDL, 25)
+	clone := edl.Clone()
+	clone.Offset(-10 * 60 * 60 * 25)
+
+	require.Equal(t, "00:00:00:00", clone.Events[0].RecordIn.GetTimecode())
+	require.Equal(t, "00:00:05:00", clone.Events[0].RecordOut.GetTimecode())
+	require.Equal(t, "01:00:00:00", clone.Events[0].SourceIn.GetTimecode())
+	// The original is left alone.
+	require.Equal(t, "10:00:00:00", edl.Events[0].RecordIn.GetTimecode())
+
+	clone.Offset(-25)
+	require.Equal(t, "23:59:59:00", clone.Events[0].RecordIn.GetTimecode())
+}
+
+func TestEDLRenumber(t *testing.T) {
+	edl := parseTestEDL(t, testEDL, 25)
+	edl.Renumber(10)
+
+	var numbers []int
+	for _, event := range edl.Events {
+		numbers = append(numbers, event.Number)
+	}
+	require.Equal(t, []int{10, 11, 11, 12, 13, 14}, numbers)
+}
+
+func TestConvertEDL(t *testing.T) {
+	in := "TITLE: DF\nFCM: DROP FRAME\n" +
+		"001  AX V C 00:59:59;29 01:00:10;00 01:00:00;00 01:00:10;01\n" +
+		"002  BX V D 030 00:10:00;00 00:10:05;00 01:00:10;01 01:00:15;01\n"
+
+	t.Run("DF to NDF", func(t *testing.T) {
+		edl := parseTestEDL(t, in, 29.97)
+		require.NoError(t, edl.ConvertEDL(edl.FrameRate, false, ConvertRealtimeNearest))
+
+		require.False(t, edl.DropFrame)
+		require.Equal(t, "00:59:56:12", edl.Events[0].RecordIn.GetTimecode())
+		require.Equal(t, 30, edl.Events[1].TransitionFrames)
+
+		span, err := edl.Events[0].RecordSpan()
+		require.NoError(t, err)
+		require.Equal(t, 301, span.GetTotalFrames())
+
+		require.Contains(t, writeTestEDL(t, edl), "FCM: NON-DROP FRAME\n")
+	})
+
+	t.Run("29.97 to 25", func(t *testing.T) {
+		edl := parseTestEDL(t, in, 29.97)
+		require.NoError(t, edl.ConvertEDL(FrameRateFromFloat(25), false, ConvertLabel))
+		require.Equal(t, "01:00:00:00", edl.Events[0].RecordIn.GetTimecode())
+		require.Equal(t, 25, edl.Events[1].TransitionFrames)
+	})
+
+	t.Run("DF at 25", func(t *testing.T) {
+		edl := parseTestEDL(t, in, 29.97)
+		require.Error(t, edl.ConvertEDL(FrameRateFromFloat(25), true, ConvertRealtimeNearest))
+	})
+}
+
+func TestSplitEDL(t *testing.T) {
+	edl := parseTestEDL(t, testEDL, 25)
+	at, err := NewTimecodeFromString("10:00:07:00", edl.FrameRate)
+	require.NoError(t, err)
+
+	before, after, err := SplitEDL(edl, at)
+	require.NoError(t, err)
+	require.Len(t, before.Events, 4)
+	require.Len(t, after.Events, 4)
+
+	head := before.Events[2]
+	require.Equal(t, "D", head.Transition)
+	require.Equal(t, "03:00:02:00", head.SourceOut.GetTimecode())
+	require.Equal(t, "10:00:07:00", head.RecordOut.GetTimecode())
+
+	tail := after.Events[0]
+	require.Equal(t, 2, tail.Number)
+	require.Equal(t, "C", tail.Transition)
+	require.Equal(t, "03:00:02:00", tail.SourceIn.GetTimecode())
+	require.Equal(t, "10:00:07:00", tail.RecordIn.GetTimecode())
+	require.Equal(t, "10:00:09:00", tail.RecordOut.GetTimecode())
+
+	// The audio event spans the split too.
+	require.Equal(t, "A", after.Events[3].Track)
+	require.Equal(t, "01:00:07:00", after.Events[3].SourceIn.GetTimecode())
+
+	at, err = NewTimecodeFromString("10:00:11:00", edl.FrameRate)
+	require.NoError(t, err)
+	_, after, err = SplitEDL(edl, at)
+	require.NoError(t, err)
+	speed := after.Events[0].Speed
+	require.Equal(t, "04:00:02:00", speed.EntryTimecode.GetTimecode())
+	require.Equal(t, "04:00:02:00", after.Events[0].SourceIn.GetTimecode())
+}
+
+func TestMergeEDLs(t *testing.T) {
+	a := parseTestEDL(t, "TITLE: A\n001 AX V C 01:00:00:00 01:00:01:00 10:00:01:00 10:00:02:00\n", 25)
+	b := parseTestEDL(t, "TITLE: B\n001 BX V C 02:00:00:00 02:00:01:00 10:00:00:00 10:00:01:00\n", 25)
+
+	merged, err := MergeEDLs(a, b)
+	require.NoError(t, err)
+	require.Equal(t, "A", merged.Title)
+	require.Len(t, merged.Events, 2)
+	require.Equal(t, "BX", merged.Events[0].Reel)
+	merged.Renumber(1)
+	require.Equal(t, 1, merged.Events[0].Number)
+	require.Equal(t, 2, merged.Events[1].Number)
+
+	gaps, overlaps, err := merged.Discontinuities()
+	require.NoError(t, err)
+	require.Empty(t, gaps)
+	require.Empty(t, overlaps)
+
+	c := parseTestEDL(t, "001 CX V C 02:00:00:00 02:00:01:00 10:00:00:00 10:00:01:00\n", 24)
+	_, err = MergeEDLs(a, c)
+	require.Error(t, err)
+}
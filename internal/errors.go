@@ -0,0 +1,46 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors returned (wrapped) by the constructors and operations in
+// this package. Check them with errors.Is.
+var (
+	// ErrMalformed is returned when an input can't be parsed.
+	ErrMalformed = errors.New("malformed input")
+	// ErrInvalidDropFrame is returned for drop frame labels that don't exist
+	// (ie 00:01:00;00) and for drop frame at a rate that doesn't support it.
+	ErrInvalidDropFrame = errors.New("invalid drop frame timecode")
+	// ErrFrameOutOfRange is returned when a field of a timecode is too high
+	// for its rate (ie 00:00:00:25 at 25 fps, or 24:00:00:00).
+	ErrFrameOutOfRange = errors.New("frame out of range")
+	// ErrUnsupportedRate is returned for framerates that can't be used, or
+	// can't be used for the requested operation.
+	ErrUnsupportedRate = errors.New("unsupported framerate")
+	// ErrInvalidOption is returned for unknown strategies, policies and operators.
+	ErrInvalidOption = errors.New("invalid option")
+	// ErrEmptySpan is returned when a span has no frames in it.
+	ErrEmptySpan = errors.New("empty span")
+)
+
+// timecodeError keeps the human readable message on its own, while still
+// matching its sentinel with errors.Is.
+type timecodeError struct {
+	kind error
+	msg  string
+}
+
+func (e *timecodeError) Error() string {
+	return e.msg
+}
+
+func (e *timecodeError) Unwrap() error {
+	return e.kind
+}
+
+// NewError formats a message for the given sentinel error.
+func NewError(kind error, format string, a ...any) error {
+	return &timecodeError{kind: kind, msg: fmt.Sprintf(format, a...)}
+}
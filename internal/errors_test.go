@@ -0,0 +1,131 @@
+package internal
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSentinelErrors(t *testing.T) {
+	ntsc := FrameRate{30000, 1001}
+	film := FrameRate{24000, 1001}
+
+	validate := func(tc string, rate FrameRate) error {
+		t, err := NewTimecodeFromString(tc, rate)
+		if err != nil {
+			return err
+		}
+		return t.Validate()
+	}
+
+	tests := []struct {
+		name     string
+		err      error
+		sentinel error
+		message  string
+	}{
+		{
+			name:     "Malformed timecode",
+			err:      validate("1:00:00:00", ntsc),
+			sentinel: ErrMalformed,
+			message:  "Timecode is malformed. Please format as hh:mm:ss:ff or hh:mm:ss;ff",
+		},
+		{
+			name:     "Illegal drop frame label",
+			err:      validate("00:07:00;00", ntsc),
+			sentinel: ErrInvalidDropFrame,
+			message:  "00:07:00;00 is not valid drop frame timecode",
+		},
+		{
+			name:     "Drop frame at a non drop frame rate",
+			err:      validate("00:07:00;00", film),
+			sentinel: ErrInvalidDropFrame,
+			message:  "23.976 is not a valid framerate for drop frame timecode",
+		},
+		{
+			name:     "Frames out of range",
+			err:      validate("00:00:00:30", ntsc),
+			sentinel: ErrFrameOutOfRange,
+			message:  "Frames cannot be higher than 29",
+		},
+		{
+			name:     "Hours out of range",
+			err:      validate("24:00:00:00", ntsc),
+			sentinel: ErrFrameOutOfRange,
+			message:  "Hours cannot be higher than 23",
+		},
+		{
+			name: "Unsupported rate",
+			err: func() error {
+				_, _, err := ParseFrameRate("0")
+				return err
+			}(),
+			sentinel: ErrUnsupportedRate,
+			message:  "0 is not a valid framerate",
+		},
+		{
+			name: "Drop frame from frames",
+			err: func() error {
+				_, err := NewTimecodeFromFrames(10, film, true)
+				return err
+			}(),
+			sentinel: ErrInvalidDropFrame,
+			message:  "23.976 is not a valid framerate for drop frame timecode",
+		},
+		{
+			name: "Nil span",
+			err: func() error {
+				_, err := NewTimecodeSpan(nil, nil)
+				return err
+			}(),
+			sentinel: ErrMalformed,
+			message:  "A span needs both a first and last timecode",
+		},
+		{
+			name: "Span with mixed rates",
+			err: func() error {
+				a, _ := NewTimecodeFromString("00:00:00:00", ntsc)
+				b, _ := NewTimecodeFromString("00:00:00:00", film)
+				_, err := NewTimecodeSpan(a, b)
+				return err
+			}(),
+			sentinel: ErrUnsupportedRate,
+			message:  "The first and last timecode of a span must have the same framerate",
+		},
+		{
+			name: "Unknown strategy",
+			err: func() error {
+				_, err := ParseConvertStrategy("sideways")
+				return err
+			}(),
+			sentinel: ErrInvalidOption,
+			message:  "sideways is not a valid conversion strategy",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Error(t, tt.err)
+			require.True(t, errors.Is(tt.err, tt.sentinel), "%v is not %v", tt.err, tt.sentinel)
+			require.EqualError(t, tt.err, tt.message)
+		})
+	}
+}
+
+func TestAddFramesMoreThanADay(t *testing.T) {
+	rate := FrameRate{25, 1}
+	tc, err := NewTimecodeFromString("01:00:00:00", rate)
+	require.NoError(t, err)
+
+	tc.AddFrames(-int(rate.FramesPerDay(false)) * 2)
+	require.Equal(t, "01:00:00:00", tc.GetTimecode())
+
+	tc.AddFrames(int(rate.FramesPerDay(false))*3 + 25)
+	require.Equal(t, "01:00:01:00", tc.GetTimecode())
+
+	df, err := NewTimecodeFromString("00:00:00;00", FrameRate{30000, 1001})
+	require.NoError(t, err)
+	df.AddFrames(-int(df.FrameRate.FramesPerDay(true)) - 1)
+	require.Equal(t, "23:59:59;29", df.GetTimecode())
+}
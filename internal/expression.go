@@ -0,0 +1,561 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Expression is a parsed calculate expression, ie "+ (00:00:30:00 * 4) - 12f".
+//
+// Values are timecodes ("00:10:00:00"), frame counts ("12"), units ("1h",
+// "2m", "30s", "12f") which can be combined the way Go writes durations
+// ("1h2m3.5s", "500ms") and are counted as timecode, feet+frames ("123+08")
+// and numbers ("1.5"). Lengths can be added to and subtracted from each
+// other and multiplied or divided by a number. A bare frame count is a
+// length when it is added or subtracted, and a number when it is multiplied
+// or divided by. Parentheses and unary minus work as usual, and * and / bind
+// tighter than + and -.
+type Expression struct {
+	source string
+	root   exprNode
+}
+
+// ExpressionStep is one operation of an evaluated expression. Steps are
+// listed in the order they were evaluated, so the last step has the result.
+type ExpressionStep struct {
+	// Operation is "+", "-", "*" or "/". A unary operation has no left side.
+	Operation string
+	// Expression is the text of the operation, ie "00:00:30:00 * 4".
+	Expression string
+	// Timecode and Frames are the right side of the operation as a length.
+	// Timecode is empty when the right side is a number.
+	Timecode string
+	Frames   int64
+	// Scalar is the right side of a * or /.
+	Scalar float64
+	// Result is the length after the operation.
+	Result         int64
+	ResultTimecode string
+}
+
+type exprTokenKind int
+
+const (
+	exprEnd exprTokenKind = iota
+	exprValue
+	exprOperator
+	exprOpen
+	exprClose
+)
+
+type exprToken struct {
+	kind exprTokenKind
+	text string
+	pos  int // byte offset in the source
+}
+
+// exprNode is a node of the parsed expression. start and end are the byte
+// offsets of its text in the source.
+type exprNode interface {
+	span() (start, end int)
+}
+
+type exprValueNode struct {
+	token exprToken
+}
+
+type exprUnaryNode struct {
+	operator exprToken
+	operand  exprNode
+	end      int
+}
+
+type exprBinaryNode struct {
+	operator    exprToken
+	left, right exprNode
+}
+
+func (n *exprValueNode) span() (int, int) {
+	return n.token.pos, n.token.pos + len(n.token.text)
+}
+
+func (n *exprUnaryNode) span() (int, int) {
+	return n.operator.pos, n.end
+}
+
+func (n *exprBinaryNode) span() (int, int) {
+	start, _ := n.left.span()
+	_, end := n.right.span()
+	return start, end
+}
+
+// exprGroupNode is a parenthesised expression. It is kept so the text of a
+// step includes the parentheses.
+type exprGroupNode struct {
+	inner      exprNode
+	start, end int
+}
+
+func (n *exprGroupNode) span() (int, int) {
+	return n.start, n.end
+}
+
+func isExprValueChar(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == ':' || c == ';' || c == '.'
+}
+
+func tokenizeExpression(in string) ([]exprToken, error) {
+	var tokens []exprToken
+	for i := 0; i < len(in); {
+		c := in[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '+' || c == '-' || c == '*' || c == '/':
+			tokens = append(tokens, exprToken{exprOperator, string(c), i})
+			i++
+		case c == '(':
+			tokens = append(tokens, exprToken{exprOpen, "(", i})
+			i++
+		case c == ')':
+			tokens = append(tokens, exprToken{exprClose, ")", i})
+			i++
+		case isExprValueChar(c):
+			start := i
+			for i < len(in) && isExprValueChar(in[i]) {
+				i++
+			}
+			// Feet+frames (ie 123+08) is one value, when there are no spaces
+			// around the + and there are two digits of frames.
+			if n := footageLength(in, start, i); n > 0 {
+				i += n
+			}
+			tokens = append(tokens, exprToken{exprValue, in[start:i], start})
+		default:
+			return nil, NewError(ErrMalformed, "Unexpected character %q at position %d", in[i:i+1], i+1)
+		}
+	}
+	return append(tokens, exprToken{exprEnd, "", len(in)}), nil
+}
+
+// footageLength returns the length of the "+08" that follows the digits in
+// in[start:end], or 0 when they aren't feet+frames.
+func footageLength(in string, start, end int) int {
+	for j := start; j < end; j++ {
+		if in[j] < '0' || in[j] > '9' {
+			return 0
+		}
+	}
+	if end+3 > len(in) || in[end] != '+' || !isDigit(in[end+1]) || !isDigit(in[end+2]) {
+		return 0
+	}
+	if end+3 < len(in) && isExprValueChar(in[end+3]) {
+		return 0
+	}
+	return 3
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+type exprParser struct {
+	tokens []exprToken
+	next   int
+}
+
+func (p *exprParser) peek() exprToken {
+	return p.tokens[p.next]
+}
+
+func (p *exprParser) take() exprToken {
+	t := p.tokens[p.next]
+	if t.kind != exprEnd {
+		p.next++
+	}
+	return t
+}
+
+// ParseExpression parses a calculate expression. Syntax errors give the
+// position (counting from 1) where the expression went wrong. Values are
+// only checked when the expression is evaluated, as that needs a framerate.
+func ParseExpression(in string) (*Expression, error) {
+	tokens, err := tokenizeExpression(in)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{tokens: tokens}
+	if p.peek().kind == exprEnd {
+		return nil, NewError(ErrMalformed, "The expression is empty")
+	}
+
+	root, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != exprEnd {
+		if t.kind == exprClose {
+			return nil, NewError(ErrMalformed, "Unexpected ')' at position %d, there is no '(' to close", t.pos+1)
+		}
+		return nil, NewError(ErrMalformed, "Expected an operator at position %d, got %q", t.pos+1, t.text)
+	}
+	return &Expression{source: in, root: root}, nil
+}
+
+// parseSum parses a + b - c ...
+func (p *exprParser) parseSum() (exprNode, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for t := p.peek(); t.kind == exprOperator && (t.text == "+" || t.text == "-"); t = p.peek() {
+		p.take()
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		left = &exprBinaryNode{operator: t, left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseProduct parses a * b / c ...
+func (p *exprParser) parseProduct() (exprNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for t := p.peek(); t.kind == exprOperator && (t.text == "*" || t.text == "/"); t = p.peek() {
+		p.take()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &exprBinaryNode{operator: t, left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseUnary parses -a or +a.
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if t := p.peek(); t.kind == exprOperator && (t.text == "+" || t.text == "-") {
+		p.take()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		_, end := operand.span()
+		return &exprUnaryNode{operator: t, operand: operand, end: end}, nil
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary parses a value or a parenthesised expression.
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	t := p.take()
+	switch t.kind {
+	case exprValue:
+		return &exprValueNode{token: t}, nil
+	case exprOpen:
+		inner, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		closing := p.take()
+		if closing.kind != exprClose {
+			if closing.kind == exprEnd {
+				return nil, NewError(ErrMalformed, "Missing ')' to close the '(' at position %d", t.pos+1)
+			}
+			return nil, NewError(ErrMalformed, "Expected ')' at position %d, got %q", closing.pos+1, closing.text)
+		}
+		return &exprGroupNode{inner: inner, start: t.pos, end: closing.pos + 1}, nil
+	case exprEnd:
+		return nil, NewError(ErrMalformed, "Expected a timecode or frame count at position %d, got the end of the expression", t.pos+1)
+	default:
+		return nil, NewError(ErrMalformed, "Expected a timecode or frame count at position %d, got %q", t.pos+1, t.text)
+	}
+}
+
+// exprResult is the value of a node. A length is a whole number of frames.
+// A number that is whole can also be used as a length.
+type exprResult struct {
+	frames   int64
+	scalar   float64
+	isLength bool
+	// timecode is set when the value was entered as a timecode, so it is
+	// shown as a timecode rather than as a length.
+	timecode string
+}
+
+func lengthResult(frames int64) exprResult {
+	return exprResult{frames: frames, isLength: true}
+}
+
+func numberResult(n float64) exprResult {
+	return exprResult{scalar: n}
+}
+
+type exprEvaluator struct {
+	source              string
+	rate                FrameRate
+	dropFrame           bool
+	excludeLastTimecode bool
+	gauge               FilmGauge
+	steps               []ExpressionStep
+	// scaled is above zero while the operands of * or / are worked out.
+	scaled int
+	// lastFrameCounted is set once a timecode has counted its last frame.
+	lastFrameCounted bool
+}
+
+// Evaluate works out the expression as a number of frames, which can be
+// negative. dropFrame is used for frame counts and units, while timecodes
+// are drop frame when they have a ";". Timecodes are lengths, so
+// 00:00:30:00 * 4 is 00:02:00:00 and they can be longer than a day. The
+// first timecode that is added or subtracted, rather than scaled, also
+// counts its last frame unless excludeLastTimecode is set, the same as
+// ParseStringToTimecode. Feet+frames can only be used with a gauge.
+func (e *Expression) Evaluate(rate FrameRate, dropFrame bool, excludeLastTimecode bool, gauge FilmGauge) (int64, []ExpressionStep, error) {
+	if dropFrame && !rate.SupportsDropFrame() {
+		return 0, nil, NewError(ErrInvalidDropFrame, "%s is not a valid framerate for drop frame timecode", rate)
+	}
+	ev := &exprEvaluator{source: e.source, rate: rate, dropFrame: dropFrame, excludeLastTimecode: excludeLastTimecode, gauge: gauge}
+	result, err := ev.eval(e.root)
+	if err != nil {
+		return 0, ev.steps, err
+	}
+	frames, err := ev.asLength(result, e.root)
+	if err != nil {
+		return 0, ev.steps, err
+	}
+	return frames, ev.steps, nil
+}
+
+func (ev *exprEvaluator) text(n exprNode) string {
+	start, end := n.span()
+	return ev.source[start:end]
+}
+
+func (ev *exprEvaluator) position(n exprNode) int {
+	start, _ := n.span()
+	return start + 1
+}
+
+// asLength converts a value to frames, which fails for numbers with a fraction.
+func (ev *exprEvaluator) asLength(v exprResult, n exprNode) (int64, error) {
+	if v.isLength {
+		return v.frames, nil
+	}
+	if v.scalar != math.Trunc(v.scalar) {
+		return 0, NewError(ErrMalformed, "%s at position %d is not a whole number of frames", ev.text(n), ev.position(n))
+	}
+	return ev.toLength(v.scalar, ev.text(n), ev.position(n))
+}
+
+// maxLengthDays bounds a length, so that a huge multiplier or a tiny divisor
+// is an error rather than a count that overflows int64.
+const maxLengthDays = 10000
+
+// toLength rounds frames to a length, or errors when it is more than
+// maxLengthDays long.
+func (ev *exprEvaluator) toLength(frames float64, text string, pos int) (int64, error) {
+	limit := float64(ev.rate.FramesPerDay(ev.dropFrame)) * maxLengthDays
+	if math.IsNaN(frames) || math.Abs(frames) > limit {
+		return 0, NewError(ErrMalformed, "%s at position %d is too large, a result can be up to %d days", text, pos, maxLengthDays)
+	}
+	return int64(math.Round(frames)), nil
+}
+
+func (ev *exprEvaluator) eval(n exprNode) (exprResult, error) {
+	switch n := n.(type) {
+	case *exprValueNode:
+		return ev.evalValue(n)
+	case *exprGroupNode:
+		v, err := ev.eval(n.inner)
+		// The group is shown as a length, not as the timecode inside it.
+		v.timecode = ""
+		return v, err
+	case *exprUnaryNode:
+		return ev.evalUnary(n)
+	case *exprBinaryNode:
+		return ev.evalBinary(n)
+	}
+	return exprResult{}, NewError(ErrMalformed, "Unknown expression")
+}
+
+func (ev *exprEvaluator) evalValue(n *exprValueNode) (exprResult, error) {
+	text := n.token.text
+	pos := n.token.pos + 1
+
+	if IsFootage(text) {
+		footage, err := ParseFootage(text, ev.gauge)
+		if err != nil {
+			return exprResult{}, fmt.Errorf("Feet+frames at position %d: %w", pos, err)
+		}
+		return lengthResult(footage.GetFrameCount()), nil
+	}
+
+	if strings.ContainsAny(text, ":;") {
+		tc, err := NewTimecodeFromString(text, ev.rate)
+		if err == nil {
+			err = tc.ValidateLength()
+		}
+		if err != nil {
+			return exprResult{}, fmt.Errorf("Timecode at position %d: %w", pos, err)
+		}
+		frames := int64(tc.GetFrameIdx())
+		if !ev.excludeLastTimecode && ev.scaled == 0 && !ev.lastFrameCounted {
+			frames++
+			ev.lastFrameCounted = true
+		}
+		return exprResult{frames: frames, isLength: true, timecode: text}, nil
+	}
+
+	if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return numberResult(float64(n)), nil
+	}
+	if f, err := strconv.ParseFloat(text, 64); err == nil && !strings.ContainsAny(text, "eExXpPnN") {
+		return numberResult(f), nil
+	}
+
+	if d, ok := parseUnits(text); ok {
+		return lengthResult(unitsToFrames(d, ev.rate, ev.dropFrame)), nil
+	}
+
+	frames, err := strconv.ParseInt(text[:len(text)-1], 10, 64)
+	if err != nil || !strings.EqualFold(text[len(text)-1:], "f") {
+		return exprResult{}, NewError(ErrMalformed, "%q at position %d is not a timecode, frame count, feet+frames, unit (ie 1h, 2m, 30s or 12f) or duration (ie 1h2m3.5s)", text, pos)
+	}
+	return lengthResult(frames), nil
+}
+
+func (ev *exprEvaluator) evalUnary(n *exprUnaryNode) (exprResult, error) {
+	v, err := ev.eval(n.operand)
+	if err != nil {
+		return exprResult{}, err
+	}
+	if n.operator.text == "-" {
+		v.frames, v.scalar = -v.frames, -v.scalar
+	}
+	result := v
+	result.timecode = ""
+	ev.record(n.operator.text, false, ev.text(n), v, result)
+	if n.operator.text == "+" {
+		// Keep showing a timecode as it was entered, ie "+ 00:00:10:00".
+		result.timecode = v.timecode
+	}
+	return result, nil
+}
+
+func (ev *exprEvaluator) evalBinary(n *exprBinaryNode) (exprResult, error) {
+	op := n.operator.text
+	if op == "*" || op == "/" {
+		ev.scaled++
+		defer func() { ev.scaled-- }()
+	}
+	left, err := ev.eval(n.left)
+	if err != nil {
+		return exprResult{}, err
+	}
+	right, err := ev.eval(n.right)
+	if err != nil {
+		return exprResult{}, err
+	}
+	pos := n.operator.pos + 1
+
+	var result exprResult
+	switch op {
+	case "+", "-":
+		if !left.isLength && !right.isLength {
+			if op == "+" {
+				result = numberResult(left.scalar + right.scalar)
+			} else {
+				result = numberResult(left.scalar - right.scalar)
+			}
+			break
+		}
+		l, err := ev.asLength(left, n.left)
+		if err != nil {
+			return exprResult{}, err
+		}
+		r, err := ev.asLength(right, n.right)
+		if err != nil {
+			return exprResult{}, err
+		}
+		if op == "+" {
+			result = lengthResult(l + r)
+		} else {
+			result = lengthResult(l - r)
+		}
+	case "*":
+		switch {
+		case left.isLength && right.isLength:
+			return exprResult{}, NewError(ErrMalformed, "Can't multiply two lengths at position %d, one side must be a number", pos)
+		case left.isLength:
+			frames, err := ev.toLength(float64(left.frames)*right.scalar, ev.text(n), pos)
+			if err != nil {
+				return exprResult{}, err
+			}
+			result = lengthResult(frames)
+		case right.isLength:
+			frames, err := ev.toLength(left.scalar*float64(right.frames), ev.text(n), pos)
+			if err != nil {
+				return exprResult{}, err
+			}
+			result = lengthResult(frames)
+		default:
+			result = numberResult(left.scalar * right.scalar)
+		}
+	case "/":
+		if right.isLength {
+			return exprResult{}, NewError(ErrMalformed, "Can't divide by a length at position %d, only by a number", pos)
+		}
+		if right.scalar == 0 {
+			return exprResult{}, NewError(ErrMalformed, "Division by zero at position %d", pos)
+		}
+		if left.isLength {
+			frames, err := ev.toLength(float64(left.frames)/right.scalar, ev.text(n), pos)
+			if err != nil {
+				return exprResult{}, err
+			}
+			result = lengthResult(frames)
+		} else {
+			result = numberResult(left.scalar / right.scalar)
+		}
+	}
+
+	ev.record(op, true, ev.text(n), right, result)
+	return result, nil
+}
+
+// record adds a step. The right side is shown as a number for * and / (unless
+// it is a length), and as a length otherwise when it is a whole number.
+func (ev *exprEvaluator) record(op string, binary bool, text string, right exprResult, result exprResult) {
+	step := ExpressionStep{Operation: op, Expression: text}
+
+	isWhole := right.scalar == math.Trunc(right.scalar)
+	if right.isLength || (isWhole && !(binary && (op == "*" || op == "/"))) {
+		step.Frames = right.frames
+		if !right.isLength {
+			step.Frames = int64(right.scalar)
+		}
+		step.Timecode = right.timecode
+		if step.Timecode == "" {
+			step.Timecode = formatLength(step.Frames, ev.rate, ev.dropFrame)
+		}
+	} else {
+		step.Scalar = right.scalar
+	}
+
+	if result.isLength || result.scalar == math.Trunc(result.scalar) {
+		step.Result = result.frames
+		if !result.isLength {
+			step.Result = int64(result.scalar)
+		}
+		step.ResultTimecode = formatLength(step.Result, ev.rate, ev.dropFrame)
+	}
+
+	ev.steps = append(ev.steps, step)
+}
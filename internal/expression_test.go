@@ -0,0 +1,166 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluateExpression(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		fps      string
+		exclude  bool
+		expected int64
+		gauge    FilmGauge
+	}{
+		{"Frame count", "+ 5", "25", false, 5, FilmGauge{}},
+		{"Timecode is inclusive", "+ 00:00:10:00", "25", false, 251, FilmGauge{}},
+		{"Timecode exclusive", "+ 00:00:10:00", "25", true, 250, FilmGauge{}},
+		{"Left to right", "+ 00:00:10:00 - 5 + 1", "25", false, 247, FilmGauge{}},
+		{"Leading minus", "- 10 + 4", "25", false, -6, FilmGauge{}},
+		{"Multiply", "+ 00:00:30:00 * 4", "25", true, 3000, FilmGauge{}},
+		{"Multiply is a length", "+ 00:00:30:00 * 4", "24", false, 2880, FilmGauge{}},
+		{"Hour timecode is an hour", "+ 01:00:00:00 * 1 - 1h", "24", false, 0, FilmGauge{}},
+		{"Last frame is counted once", "+ 00:00:10:00 + 00:00:05:00", "25", false, 376, FilmGauge{}},
+		{"Subtracted timecode", "- 00:00:10:00", "25", false, -251, FilmGauge{}},
+		{"Timecode longer than a day", "+ 26:14:03:12", "25", false, 2361088, FilmGauge{}},
+		{"Number first", "+ 4 * 00:00:30:00", "25", true, 3000, FilmGauge{}},
+		{"Precedence", "+ 10 + 2 * 3", "25", false, 16, FilmGauge{}},
+		{"Parentheses", "+ (10 + 2) * 3", "25", false, 36, FilmGauge{}},
+		{"Divide rounds", "+ 10f / 4", "25", false, 3, FilmGauge{}},
+		{"Divide by decimal", "+ 1s / 0.5", "25", false, 50, FilmGauge{}},
+		{"Decimal scalar", "+ 1.5 * 2s", "25", false, 75, FilmGauge{}},
+		{"Unary minus", "+ -(1s - 5)", "25", false, -20, FilmGauge{}},
+		{"Double minus", "- -12f", "25", false, 12, FilmGauge{}},
+		{"Units", "+ 1h + 2m + 3s + 4f", "25", false, 90000 + 3000 + 75 + 4, FilmGauge{}},
+		{"Upper case unit", "+ 30S", "25", false, 750, FilmGauge{}},
+		{"Seconds past a minute", "+ 90s", "24", false, 2160, FilmGauge{}},
+		{"DF minute", "+ 1m", "29.97DF", false, 1798, FilmGauge{}},
+		{"DF tenth minute", "+ 10m", "29.97DF", false, 17982, FilmGauge{}},
+		{"DF hour", "+ 1h", "29.97DF", false, 107892, FilmGauge{}},
+		{"NDF hour at 29.97", "+ 1h", "29.97", false, 108000, FilmGauge{}},
+		{"No spaces", "+(00:00:01:00*2)-1", "25", true, 49, FilmGauge{}},
+		{"Longer than a day", "+ 25h", "25", false, 2250000, FilmGauge{}},
+		{"Feet and frames", "+ 123+08", "24", false, 1976, Gauge35mm4Perf},
+		{"Feet and frames plus frames", "+ 1+08+4", "24", false, 28, Gauge35mm4Perf},
+		{"Spaces are a plus", "+ 1 + 08", "24", false, 9, Gauge35mm4Perf},
+		{"Feet and frames 3-perf", "+ 2+00 - 1+00", "24", false, 21, Gauge35mm3Perf},
+		{"Feet and frames in parentheses", "+(1+00)*2", "24", false, 80, Gauge16mm},
+		{"Go duration", "+ 1h2m3.5s", "25", false, 93088, FilmGauge{}},
+		{"Whole seconds", "+ 30s", "29.97", false, 900, FilmGauge{}},
+		{"Seconds with a decimal are the same", "+ 30.0s", "29.97", false, 900, FilmGauge{}},
+		{"Units added", "+ 1h + 30m", "29.97", false, 162000, FilmGauge{}},
+		{"Units together are the same", "+ 1h30m", "29.97", false, 162000, FilmGauge{}},
+		{"Units together in drop frame", "+ 1h30m - (1h + 30m)", "29.97DF", false, 0, FilmGauge{}},
+		{"Units are timecode", "+ 1h30m - 01:30:00:00", "23.976", true, 0, FilmGauge{}},
+		{"Each duration rounds to the nearest frame", "+ 500ms + 20ms", "25", false, 14, FilmGauge{}},
+		{"Fractional seconds", "+ 1.5s", "24", false, 36, FilmGauge{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, df, err := ParseFrameRate(tt.fps)
+			require.NoError(t, err)
+
+			expr, err := ParseExpression(tt.input)
+			require.NoError(t, err)
+			frames, steps, err := expr.Evaluate(rate, df, tt.exclude, tt.gauge)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, frames)
+			require.Equal(t, tt.expected, steps[len(steps)-1].Result)
+		})
+	}
+}
+
+func TestParseExpressionErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Empty", "  ", "The expression is empty"},
+		{"Unknown character", "+ 5 % 2", "Unexpected character \"%\" at position 5"},
+		{"Missing value", "+ 00:00:10:00 -", "Expected a timecode or frame count at position 16, got the end of the expression"},
+		{"Two operators", "+ 5 * * 2", "Expected a timecode or frame count at position 7, got \"*\""},
+		{"Missing operator", "+ 00:00:01:00 5", "Expected an operator at position 15, got \"5\""},
+		{"Unclosed parenthesis", "+ (5 + 2", "Missing ')' to close the '(' at position 3"},
+		{"Extra parenthesis", "+ 5)", "Unexpected ')' at position 4, there is no '(' to close"},
+		{"Empty parentheses", "+ ()", "Expected a timecode or frame count at position 4, got \")\""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseExpression(tt.input)
+			require.ErrorIs(t, err, ErrMalformed)
+			require.EqualError(t, err, tt.expected)
+		})
+	}
+}
+
+func TestEvaluateExpressionErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		fps      string
+		expected string
+		kind     error
+	}{
+		{"Unknown unit", "+ 1x", "25", "\"1x\" at position 3 is not a timecode, frame count, feet+frames, unit (ie 1h, 2m, 30s or 12f) or duration (ie 1h2m3.5s)", ErrMalformed},
+		{"Fraction of a frame", "+ 1.5", "25", "+ 1.5 at position 1 is not a whole number of frames", ErrMalformed},
+		{"Numbers divide to a fraction", "+ 10 / 4", "25", "+ 10 / 4 at position 1 is not a whole number of frames", ErrMalformed},
+		{"Two lengths", "+ 2s * 2s", "25", "Can't multiply two lengths at position 6, one side must be a number", ErrMalformed},
+		{"Divide by a length", "+ 10 / 2s", "25", "Can't divide by a length at position 6, only by a number", ErrMalformed},
+		{"Divide by zero", "+ 10 / (2 - 2)", "25", "Division by zero at position 6", ErrMalformed},
+		{"Divide by a tiny number", "+ 00:00:01:00 / 0.0000000000001", "25", "+ 00:00:01:00 / 0.0000000000001 at position 15 is too large, a result can be up to 10000 days", ErrMalformed},
+		{"Huge multiplier", "+ 00:00:01:00 * 1000000000000000000000000", "25", "+ 00:00:01:00 * 1000000000000000000000000 at position 15 is too large, a result can be up to 10000 days", ErrMalformed},
+		{"Huge multiplier on the left", "+ 1000000000000000000000000 * 1s", "25", "+ 1000000000000000000000000 * 1s at position 29 is too large, a result can be up to 10000 days", ErrMalformed},
+		{"Huge frame count", "+ 99999999999999999999", "25", "+ 99999999999999999999 at position 1 is too large, a result can be up to 10000 days", ErrMalformed},
+		{"Invalid timecode", "+ 00:00:00:25", "25", "Timecode at position 3: Frames cannot be higher than 24", ErrFrameOutOfRange},
+		{"Feet and frames without a gauge", "+ 123+08", "24", "Feet+frames at position 3: 123+08 is feet+frames, which needs a film gauge (ie 35mm-4perf)", ErrInvalidOption},
+		{"Invalid DF timecode", "+ 5 + 00:01:00;00", "29.97DF", "Timecode at position 7: 00:01:00;00 is not valid drop frame timecode", ErrInvalidDropFrame},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, df, err := ParseFrameRate(tt.fps)
+			require.NoError(t, err)
+
+			expr, err := ParseExpression(tt.input)
+			require.NoError(t, err)
+			_, _, err = expr.Evaluate(rate, df, false, FilmGauge{})
+			require.ErrorIs(t, err, tt.kind)
+			require.EqualError(t, err, tt.expected)
+		})
+	}
+}
+
+func TestExpressionSteps(t *testing.T) {
+	rate, _, err := ParseFrameRate("25")
+	require.NoError(t, err)
+
+	expr, err := ParseExpression("+ (00:00:30:00 - 1) * 4")
+	require.NoError(t, err)
+	frames, steps, err := expr.Evaluate(rate, false, false, FilmGauge{})
+	require.NoError(t, err)
+	require.Equal(t, int64(2996), frames)
+
+	require.Equal(t, []ExpressionStep{
+		{Operation: "-", Expression: "00:00:30:00 - 1", Timecode: "00:00:00:01", Frames: 1, Result: 749, ResultTimecode: "00:00:29:24"},
+		{Operation: "+", Expression: "+ (00:00:30:00 - 1)", Timecode: "00:00:29:24", Frames: 749, Result: 749, ResultTimecode: "00:00:29:24"},
+		{Operation: "*", Expression: "+ (00:00:30:00 - 1) * 4", Scalar: 4, Result: 2996, ResultTimecode: "00:01:59:21"},
+	}, steps)
+
+	// A timecode that is scaled doesn't count its last frame.
+	rate, _, err = ParseFrameRate("24")
+	require.NoError(t, err)
+	expr, err = ParseExpression("+ 00:00:30:00 * 4")
+	require.NoError(t, err)
+	_, steps, err = expr.Evaluate(rate, false, false, FilmGauge{})
+	require.NoError(t, err)
+	require.Equal(t, []ExpressionStep{
+		{Operation: "+", Expression: "+ 00:00:30:00", Timecode: "00:00:30:00", Frames: 720, Result: 720, ResultTimecode: "00:00:30:00"},
+		{Operation: "*", Expression: "+ 00:00:30:00 * 4", Scalar: 4, Result: 2880, ResultTimecode: "00:02:00:00"},
+	}, steps)
+}
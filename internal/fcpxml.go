@@ -0,0 +1,474 @@
+package internal
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FCPTime is an FCPXML time, which is rational seconds such as "1001/30000s"
+// or "3600s".
+type FCPTime struct {
+	Num int64
+	Den int64
+}
+
+var fcpTimeRe = regexp.MustCompile(`^(-?[0-9]+)(?:/([0-9]+))?s$`)
+
+// ParseFCPTime parses an FCPXML time, ie "1001/30000s" or "3600s".
+func ParseFCPTime(s string) (FCPTime, error) {
+	m := fcpTimeRe.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return FCPTime{}, NewError(ErrMalformed, "%s is not a valid FCPXML time. Expected rational seconds, ie 1001/30000s or 3600s", s)
+	}
+	num, err := strconv.ParseInt(m[1], 10, 64)
+	if err != nil {
+		return FCPTime{}, NewError(ErrMalformed, "%s is not a valid FCPXML time. Expected rational seconds, ie 1001/30000s or 3600s", s)
+	}
+	den := int64(1)
+	if m[2] != "" {
+		if den, err = strconv.ParseInt(m[2], 10, 64); err != nil || den == 0 {
+			return FCPTime{}, NewError(ErrMalformed, "%s is not a valid FCPXML time. Expected rational seconds, ie 1001/30000s or 3600s", s)
+		}
+	}
+	return newFCPTime(num, den), nil
+}
+
+func newFCPTime(num, den int64) FCPTime {
+	g := gcd(num, den)
+	return FCPTime{Num: num / g, Den: den / g}
+}
+
+// Add returns t + u.
+func (t FCPTime) Add(u FCPTime) FCPTime {
+	return newFCPTime(t.Num*u.Den+u.Num*t.Den, t.Den*u.Den)
+}
+
+// Sub returns t - u.
+func (t FCPTime) Sub(u FCPTime) FCPTime {
+	return newFCPTime(t.Num*u.Den-u.Num*t.Den, t.Den*u.Den)
+}
+
+// Seconds returns t as seconds.
+func (t FCPTime) Seconds() float64 {
+	return float64(t.Num) / float64(t.Den)
+}
+
+// String returns t the way FCPXML writes it, ie "1001/30000s".
+func (t FCPTime) String() string {
+	if t.Den == 1 {
+		return fmt.Sprintf("%ds", t.Num)
+	}
+	return fmt.Sprintf("%d/%ds", t.Num, t.Den)
+}
+
+// Frames converts t to the nearest frame at the rate. aligned is false when
+// t isn't on a frame boundary.
+func (t FCPTime) Frames(rate FrameRate) (frames int64, aligned bool) {
+	// t.Num/t.Den seconds * rate.Num/rate.Den frames a second.
+	den := t.Den * rate.Den
+	frames, rem := floorDivmod(t.Num*rate.Num, den)
+	if 2*rem >= den {
+		frames++
+	}
+	return frames, rem == 0
+}
+
+// FCPXML is a parsed Final Cut Pro XML document.
+type FCPXML struct {
+	Version string
+	// Assets are the asset resources, by ID.
+	Assets    map[string]*FCPXMLAsset
+	Sequences []*FCPXMLSequence
+}
+
+// FCPXMLAsset is an asset resource, a piece of media.
+type FCPXMLAsset struct {
+	ID   string
+	Name string
+	// Start is the timecode of the first frame of the media.
+	Start    FCPTime
+	Duration FCPTime
+	// FrameRate is the rate of the asset's format. It is zero for media
+	// without video.
+	FrameRate FrameRate
+}
+
+// FCPXMLSequence is the sequence of a project, with the clips edited into it.
+type FCPXMLSequence struct {
+	// Name is the name of the project.
+	Name      string
+	FrameRate FrameRate
+	DropFrame bool
+	// TCStart is the timecode of the start of the sequence. Clip offsets in
+	// the sequence are in the same time, so the first clip is usually at
+	// TCStart.
+	TCStart  FCPTime
+	Duration FCPTime
+	Clips    []*FCPXMLClip
+}
+
+// FCPXMLClip is a clip in a sequence, either in the primary storyline or
+// connected to it.
+type FCPXMLClip struct {
+	// Kind is the element, ie "asset-clip", "clip", "ref-clip" or "title".
+	Kind string
+	Name string
+	// Lane is 0 in the primary storyline, above it when positive and below it
+	// when negative.
+	Lane int
+	// Asset is the asset the clip refers to. It is nil for clips that don't
+	// refer to an asset, such as compound clips.
+	Asset *FCPXMLAsset
+	// RecordIn and RecordOut are in the sequence's time, SourceIn and
+	// SourceOut in the media's time. The outs are exclusive.
+	RecordIn  FCPTime
+	RecordOut FCPTime
+	SourceIn  FCPTime
+	SourceOut FCPTime
+	// SourceRate and SourceDropFrame are the timecode of the media. Drop frame
+	// comes from the clip's tcFormat.
+	SourceRate      FrameRate
+	SourceDropFrame bool
+}
+
+// fcpxmlClipKinds are the elements listed as clips. Gaps and transitions
+// aren't clips, but clips can be connected to gaps.
+var fcpxmlClipKinds = map[string]bool{
+	"asset-clip": true,
+	"clip":       true,
+	"ref-clip":   true,
+	"sync-clip":  true,
+	"mc-clip":    true,
+	"title":      true,
+	"video":      true,
+	"audio":      true,
+}
+
+// fcpxmlNode is any element of the document.
+type fcpxmlNode struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr   `xml:",any,attr"`
+	Nodes   []fcpxmlNode `xml:",any"`
+}
+
+func (n *fcpxmlNode) attr(name string) string {
+	for _, a := range n.Attrs {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// time reads a time attribute, which is 0s when it isn't there.
+func (n *fcpxmlNode) time(name string) (FCPTime, error) {
+	s := n.attr(name)
+	if s == "" {
+		return FCPTime{Num: 0, Den: 1}, nil
+	}
+	t, err := ParseFCPTime(s)
+	if err != nil {
+		return FCPTime{}, fmt.Errorf("%s %s: %w", n.XMLName.Local, name, err)
+	}
+	return t, nil
+}
+
+// describe names an element in errors, ie `asset-clip "Interview"`.
+func (n *fcpxmlNode) describe() string {
+	if name := n.attr("name"); name != "" {
+		return fmt.Sprintf("%s %q", n.XMLName.Local, name)
+	}
+	return n.XMLName.Local
+}
+
+// ParseFCPXML will read an FCPXML document and list the clips of every
+// project's sequence. Each sequence has the rate of its format's
+// frameDuration and the drop frame of its tcFormat.
+func ParseFCPXML(r io.Reader) (*FCPXML, error) {
+	var root fcpxmlNode
+	if err := xml.NewDecoder(r).Decode(&root); err != nil {
+		return nil, NewError(ErrMalformed, "could not read FCPXML: %s", err)
+	}
+	if root.XMLName.Local != "fcpxml" {
+		return nil, NewError(ErrMalformed, "Not an FCPXML document. Expected <fcpxml>, not <%s>", root.XMLName.Local)
+	}
+
+	doc := &FCPXML{Version: root.attr("version"), Assets: map[string]*FCPXMLAsset{}}
+	formats := map[string]FrameRate{}
+	assetFormats := map[*FCPXMLAsset]string{}
+	for _, resources := range root.Nodes {
+		if resources.XMLName.Local != "resources" {
+			continue
+		}
+		for _, resource := range resources.Nodes {
+			switch resource.XMLName.Local {
+			case "format":
+				if resource.attr("frameDuration") == "" {
+					continue
+				}
+				frameDuration, err := resource.time("frameDuration")
+				if err != nil {
+					return nil, err
+				}
+				rate, err := NewFrameRate(frameDuration.Den, frameDuration.Num)
+				if err != nil {
+					return nil, fmt.Errorf("format %s: %w", resource.attr("id"), err)
+				}
+				formats[resource.attr("id")] = rate
+			case "asset":
+				asset := &FCPXMLAsset{ID: resource.attr("id"), Name: resource.attr("name")}
+				var err error
+				if asset.Start, err = resource.time("start"); err != nil {
+					return nil, err
+				}
+				if asset.Duration, err = resource.time("duration"); err != nil {
+					return nil, err
+				}
+				doc.Assets[asset.ID] = asset
+				assetFormats[asset] = resource.attr("format")
+			}
+		}
+	}
+	// Formats can come after the assets that use them.
+	for asset, format := range assetFormats {
+		asset.FrameRate = formats[format]
+	}
+
+	p := &fcpxmlParser{doc: doc, formats: formats}
+	if err := p.findSequences(root.Nodes, ""); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+type fcpxmlParser struct {
+	doc     *FCPXML
+	formats map[string]FrameRate
+}
+
+// findSequences looks for the sequences of projects. The sequences of
+// compound clips are in the resources and are skipped.
+func (p *fcpxmlParser) findSequences(nodes []fcpxmlNode, project string) error {
+	for _, node := range nodes {
+		switch node.XMLName.Local {
+		case "resources":
+		case "project":
+			if err := p.findSequences(node.Nodes, node.attr("name")); err != nil {
+				return err
+			}
+		case "sequence":
+			if err := p.parseSequence(&node, project); err != nil {
+				return err
+			}
+		default:
+			if err := p.findSequences(node.Nodes, project); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (p *fcpxmlParser) parseSequence(node *fcpxmlNode, project string) error {
+	rate, ok := p.formats[node.attr("format")]
+	if !ok {
+		return NewError(ErrUnsupportedRate, "Sequence %q has no frame rate. Its format needs a frameDuration", project)
+	}
+	seq := &FCPXMLSequence{Name: project, FrameRate: rate, DropFrame: node.attr("tcFormat") == "DF"}
+	if seq.DropFrame && !rate.SupportsDropFrame() {
+		return NewError(ErrInvalidDropFrame, "%s is not a valid framerate for drop frame timecode", rate)
+	}
+	var err error
+	if seq.TCStart, err = node.time("tcStart"); err != nil {
+		return err
+	}
+	if seq.Duration, err = node.time("duration"); err != nil {
+		return err
+	}
+
+	for _, spine := range node.Nodes {
+		if spine.XMLName.Local != "spine" {
+			continue
+		}
+		identity := func(t FCPTime) FCPTime { return t }
+		if err := p.walk(seq, spine.Nodes, 0, identity); err != nil {
+			return fmt.Errorf("sequence %q: %w", project, err)
+		}
+	}
+
+	p.doc.Sequences = append(p.doc.Sequences, seq)
+	return nil
+}
+
+// walk lists the clips of a storyline and the clips connected to them.
+// toSequence maps a time in the storyline to the sequence's time.
+func (p *fcpxmlParser) walk(seq *FCPXMLSequence, items []fcpxmlNode, lane int, toSequence func(FCPTime) FCPTime) error {
+	for i := range items {
+		item := &items[i]
+		kind := item.XMLName.Local
+		if kind != "spine" && kind != "gap" && !fcpxmlClipKinds[kind] {
+			continue
+		}
+
+		itemLane := lane
+		if s := item.attr("lane"); s != "" {
+			l, err := strconv.Atoi(s)
+			if err != nil {
+				return fmt.Errorf("%s: %w", item.describe(), NewError(ErrMalformed, "%s is not a valid lane", s))
+			}
+			itemLane = l
+		}
+		offset, err := item.time("offset")
+		if err != nil {
+			return fmt.Errorf("%s: %w", item.describe(), err)
+		}
+		start, err := item.time("start")
+		if err != nil {
+			return fmt.Errorf("%s: %w", item.describe(), err)
+		}
+		// The item's own time starts at start, which is at offset in the
+		// storyline.
+		toItem := func(t FCPTime) FCPTime { return toSequence(offset.Add(t.Sub(start))) }
+
+		if kind == "spine" {
+			// A secondary storyline.
+			if err := p.walk(seq, item.Nodes, itemLane, toItem); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if fcpxmlClipKinds[kind] {
+			duration, err := item.time("duration")
+			if err != nil {
+				return fmt.Errorf("%s: %w", item.describe(), err)
+			}
+			clip := &FCPXMLClip{
+				Kind:       kind,
+				Name:       item.attr("name"),
+				Lane:       itemLane,
+				RecordIn:   toSequence(offset),
+				RecordOut:  toSequence(offset.Add(duration)),
+				SourceIn:   start,
+				SourceOut:  start.Add(duration),
+				SourceRate: seq.FrameRate,
+			}
+			if asset, ok := p.doc.Assets[item.attr("ref")]; ok {
+				clip.Asset = asset
+				if clip.Name == "" {
+					clip.Name = asset.Name
+				}
+				if asset.FrameRate.Num != 0 {
+					clip.SourceRate = asset.FrameRate
+				}
+			} else if rate, ok := p.formats[item.attr("format")]; ok {
+				clip.SourceRate = rate
+			}
+			clip.SourceDropFrame = item.attr("tcFormat") == "DF" && clip.SourceRate.SupportsDropFrame()
+			seq.Clips = append(seq.Clips, clip)
+		}
+
+		// Connected clips and secondary storylines have a lane, the rest
+		// of the children are the item's own contents.
+		var connected []fcpxmlNode
+		for _, child := range item.Nodes {
+			if child.attr("lane") != "" {
+				connected = append(connected, child)
+			}
+		}
+		if err := p.walk(seq, connected, itemLane, toItem); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fcpTimecode is the timecode of the nearest frame to t. It rolls over at
+// midnight.
+func fcpTimecode(t FCPTime, rate FrameRate, dropFrame bool) (*Timecode, error) {
+	frames, _ := t.Frames(rate)
+	_, frames = floorDivmod(frames, rate.FramesPerDay(dropFrame))
+	return NewTimecodeFromFrames(frames, rate, dropFrame)
+}
+
+// fcpSpan is the span from in up to, but not including, out, on the nearest
+// frames. It is nil when that has no frames.
+func fcpSpan(in, out FCPTime, rate FrameRate, dropFrame bool) (*TimecodeSpan, error) {
+	inFrames, _ := in.Frames(rate)
+	outFrames, _ := out.Frames(rate)
+	if outFrames <= inFrames {
+		return nil, nil
+	}
+	first, err := fcpTimecode(in, rate, dropFrame)
+	if err != nil {
+		return nil, err
+	}
+	return NewTimecodeSpanFromOffset(first, outFrames-inFrames-1)
+}
+
+// Timecode is the sequence timecode of the nearest frame to t.
+func (s *FCPXMLSequence) Timecode(t FCPTime) (*Timecode, error) {
+	return fcpTimecode(t, s.FrameRate, s.DropFrame)
+}
+
+// Span is the span of the whole sequence. It is nil for an empty sequence.
+func (s *FCPXMLSequence) Span() (*TimecodeSpan, error) {
+	return fcpSpan(s.TCStart, s.TCStart.Add(s.Duration), s.FrameRate, s.DropFrame)
+}
+
+// RecordTimecodes are the sequence timecodes of the clip's record in and
+// record out.
+func (s *FCPXMLSequence) RecordTimecodes(c *FCPXMLClip) (in, out *Timecode, err error) {
+	if in, err = s.Timecode(c.RecordIn); err != nil {
+		return nil, nil, err
+	}
+	if out, err = s.Timecode(c.RecordOut); err != nil {
+		return nil, nil, err
+	}
+	return in, out, nil
+}
+
+// RecordSpan is the span of the clip in the sequence. It is nil when the
+// clip has no frames.
+func (s *FCPXMLSequence) RecordSpan(c *FCPXMLClip) (*TimecodeSpan, error) {
+	return fcpSpan(c.RecordIn, c.RecordOut, s.FrameRate, s.DropFrame)
+}
+
+// SourceTimecodes are the media timecodes of the clip's source in and
+// source out.
+func (c *FCPXMLClip) SourceTimecodes() (in, out *Timecode, err error) {
+	if in, err = fcpTimecode(c.SourceIn, c.SourceRate, c.SourceDropFrame); err != nil {
+		return nil, nil, err
+	}
+	if out, err = fcpTimecode(c.SourceOut, c.SourceRate, c.SourceDropFrame); err != nil {
+		return nil, nil, err
+	}
+	return in, out, nil
+}
+
+// UnalignedEdits lists the edit points of the clip that aren't on a frame
+// boundary, ie "record in" or "source out". Record edits are checked at the
+// sequence's rate and source edits at the media's rate. Timecodes of
+// unaligned edits are the nearest frame.
+func (s *FCPXMLSequence) UnalignedEdits(c *FCPXMLClip) []string {
+	var edits []string
+	for _, edit := range []struct {
+		name string
+		t    FCPTime
+		rate FrameRate
+	}{
+		{"record in", c.RecordIn, s.FrameRate},
+		{"record out", c.RecordOut, s.FrameRate},
+		{"source in", c.SourceIn, c.SourceRate},
+		{"source out", c.SourceOut, c.SourceRate},
+	} {
+		if _, aligned := edit.t.Frames(edit.rate); !aligned {
+			edits = append(edits, edit.name)
+		}
+	}
+	return edits
+}
@@ -0,0 +1,191 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testFCPXML = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE fcpxml>
+<fcpxml version="1.10">
+    <resources>
+        <asset id="r3" name="Interview" start="36036s" duration="600s" format="r1" hasVideo="1"/>
+        <asset id="r4" name="Broll" start="0s" duration="100s" format="r2" hasVideo="1"/>
+        <format id="r1" name="FFVideoFormat1080p2997" frameDuration="1001/30000s" width="1920" height="1080"/>
+        <format id="r2" name="FFVideoFormat1080p25" frameDuration="100/2500s" width="1920" height="1080"/>
+        <media id="r5" name="Compound">
+            <sequence format="r1" duration="10s" tcStart="0s">
+                <spine><asset-clip ref="r3" offset="0s" duration="10s"/></spine>
+            </sequence>
+        </media>
+    </resources>
+    <library>
+        <event name="Day 1">
+            <project name="Promo">
+                <sequence format="r1" duration="1001/100s" tcStart="107999892/30000s" tcFormat="DF">
+                    <spine>
+                        <asset-clip ref="r3" offset="107999892/30000s" name="Interview A" start="36036s" duration="5005/1000s" tcFormat="NDF">
+                            <asset-clip ref="r4" lane="1" offset="36037001/1000s" name="Broll" start="10s" duration="2s"/>
+                        </asset-clip>
+                        <gap name="Gap" offset="108150042/30000s" start="3600s" duration="1001/1000s">
+                            <title lane="1" offset="3600s" name="Lower third" start="0s" duration="1001/1000s"/>
+                        </gap>
+                        <transition name="Cross Dissolve" offset="108165057/30000s" duration="1001/1000s"/>
+                        <clip name="Wide" offset="108180072/30000s" start="0s" duration="3003/1000s">
+                            <video ref="r4" offset="0s" start="20s" duration="3003/1000s"/>
+                        </clip>
+                        <asset-clip ref="r3" offset="108270162/30000s" name="Interview B" start="36100s" duration="1s"/>
+                    </spine>
+                </sequence>
+            </project>
+        </event>
+    </library>
+</fcpxml>
+`
+
+func TestParseFCPTime(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    FCPTime
+		expectError bool
+	}{
+		{"1001/30000s", FCPTime{Num: 1001, Den: 30000}, false},
+		{"3600s", FCPTime{Num: 3600, Den: 1}, false},
+		{"2002/60000s", FCPTime{Num: 1001, Den: 30000}, false},
+		{"-1s", FCPTime{Num: -1, Den: 1}, false},
+		{"0/2400s", FCPTime{Num: 0, Den: 1}, false},
+		{"1001/30000", FCPTime{}, true},
+		{"1.5s", FCPTime{}, true},
+		{"1/0s", FCPTime{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			parsed, err := ParseFCPTime(tt.input)
+			if tt.expectError {
+				require.ErrorIs(t, err, ErrMalformed)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, parsed)
+		})
+	}
+
+	require.Equal(t, "3003/1000s", FCPTime{Num: 1001, Den: 1000}.Add(FCPTime{Num: 2002, Den: 1000}).String())
+	require.Equal(t, "-1s", FCPTime{Num: 1, Den: 1}.Sub(FCPTime{Num: 2, Den: 1}).String())
+}
+
+func TestFCPTimeFrames(t *testing.T) {
+	tests := []struct {
+		name     string
+		time     FCPTime
+		fps      float64
+		expected int64
+		aligned  bool
+	}{
+		{"A frame at 29.97", FCPTime{Num: 1001, Den: 30000}, 29.97, 1, true},
+		{"A second at 29.97", FCPTime{Num: 1, Den: 1}, 29.97, 30, false},
+		{"Half a frame goes up", FCPTime{Num: 1, Den: 50}, 25, 1, false},
+		{"An hour at 25", FCPTime{Num: 3600, Den: 1}, 25, 90000, true},
+		{"Negative", FCPTime{Num: -1, Den: 25}, 25, -1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frames, aligned := tt.time.Frames(FrameRateFromFloat(tt.fps))
+			require.Equal(t, tt.expected, frames)
+			require.Equal(t, tt.aligned, aligned)
+		})
+	}
+}
+
+func TestParseFCPXML(t *testing.T) {
+	doc, err := ParseFCPXML(strings.NewReader(testFCPXML))
+	require.NoError(t, err)
+	require.Equal(t, "1.10", doc.Version)
+	require.Equal(t, FrameRateFromFloat(25), doc.Assets["r4"].FrameRate)
+
+	// The compound clip's sequence is a resource, not a project.
+	require.Len(t, doc.Sequences, 1)
+	seq := doc.Sequences[0]
+	require.Equal(t, "Promo", seq.Name)
+	require.Equal(t, FrameRateFromFloat(29.97), seq.FrameRate)
+	require.True(t, seq.DropFrame)
+
+	span, err := seq.Span()
+	require.NoError(t, err)
+	require.Equal(t, "01:00:00;00", span.StartTimecode.GetTimecode())
+	require.Equal(t, 300, span.GetTotalFrames())
+
+	tests := []struct {
+		kind      string
+		name      string
+		lane      int
+		recordIn  string
+		recordOut string
+		sourceIn  string
+		sourceOut string
+		unaligned []string
+	}{
+		{"asset-clip", "Interview A", 0, "01:00:00;00", "01:00:05;00", "10:00:00:00", "10:00:05:00", nil},
+		{"asset-clip", "Broll", 1, "01:00:01;00", "01:00:03;00", "00:00:10:00", "00:00:12:00", []string{"record out"}},
+		{"title", "Lower third", 1, "01:00:05;00", "01:00:06;00", "00:00:00:00", "00:00:01:00", nil},
+		{"clip", "Wide", 0, "01:00:06;00", "01:00:09;00", "00:00:00:00", "00:00:03:00", nil},
+		{"asset-clip", "Interview B", 0, "01:00:09;00", "01:00:10;00", "10:01:03:28", "10:01:04:28", []string{"record out", "source in", "source out"}},
+	}
+	require.Len(t, seq.Clips, len(tests))
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clip := seq.Clips[i]
+			require.Equal(t, tt.kind, clip.Kind)
+			require.Equal(t, tt.name, clip.Name)
+			require.Equal(t, tt.lane, clip.Lane)
+
+			in, out, err := seq.RecordTimecodes(clip)
+			require.NoError(t, err)
+			require.Equal(t, tt.recordIn, in.GetTimecode())
+			require.Equal(t, tt.recordOut, out.GetTimecode())
+
+			in, out, err = clip.SourceTimecodes()
+			require.NoError(t, err)
+			require.Equal(t, tt.sourceIn, in.GetTimecode())
+			require.Equal(t, tt.sourceOut, out.GetTimecode())
+
+			require.Equal(t, tt.unaligned, seq.UnalignedEdits(clip))
+		})
+	}
+
+	span, err = seq.RecordSpan(seq.Clips[0])
+	require.NoError(t, err)
+	require.Equal(t, 150, span.GetTotalFrames())
+	require.Equal(t, "01:00:04;29", span.LastTimecode.GetTimecode())
+}
+
+func TestParseFCPXMLErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected error
+		message  string
+	}{
+		{"Not FCPXML", `<xmeml version="5"/>`, ErrMalformed, "Not an FCPXML document. Expected <fcpxml>, not <xmeml>"},
+		{"Not XML", `TITLE: CUT`, ErrMalformed, ""},
+		{"No frame rate", `<fcpxml><library><project name="Cut"><sequence format="r9"/></project></library></fcpxml>`, ErrUnsupportedRate, `Sequence "Cut" has no frame rate. Its format needs a frameDuration`},
+		{"Below a frame a second", `<fcpxml><resources><format id="r1" frameDuration="1000/1s"/></resources><project name="Cut"><sequence format="r1"/></project></fcpxml>`, ErrUnsupportedRate, "format r1: 1/1000 is not a valid framerate"},
+		{"Drop frame at 25", `<fcpxml><resources><format id="r1" frameDuration="1/25s"/></resources><project name="Cut"><sequence format="r1" tcFormat="DF"/></project></fcpxml>`, ErrInvalidDropFrame, ""},
+		{"Bad offset", `<fcpxml><resources><format id="r1" frameDuration="1/25s"/></resources><project name="Cut"><sequence format="r1"><spine><gap name="Gap" offset="1.5s"/></spine></sequence></project></fcpxml>`, ErrMalformed, `sequence "Cut": gap "Gap": gap offset: 1.5s is not a valid FCPXML time. Expected rational seconds, ie 1001/30000s or 3600s`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFCPXML(strings.NewReader(tt.input))
+			require.ErrorIs(t, err, tt.expected)
+			if tt.message != "" {
+				require.Equal(t, tt.message, err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FilmGauge is the number of frames in a foot of film, which is a fraction
+// for 35mm 3-perf (64 frames every 3 feet). The zero value is no gauge.
+type FilmGauge struct {
+	Name string
+	// FramesPerFoot is Num/Den frames.
+	Num int64
+	Den int64
+}
+
+var (
+	// Gauge35mm4Perf is 16 frames per foot.
+	Gauge35mm4Perf = FilmGauge{Name: "35mm-4perf", Num: 16, Den: 1}
+	// Gauge35mm3Perf is 21.33 frames per foot. The feet of each 3 foot
+	// cycle are 21, 21 and 22 frames long.
+	Gauge35mm3Perf = FilmGauge{Name: "35mm-3perf", Num: 64, Den: 3}
+	// Gauge16mm is 40 frames per foot.
+	Gauge16mm = FilmGauge{Name: "16mm", Num: 40, Den: 1}
+)
+
+// FilmGauges are the gauges ParseFilmGauge knows.
+var FilmGauges = []FilmGauge{Gauge35mm4Perf, Gauge35mm3Perf, Gauge16mm}
+
+// ParseFilmGauge parses a gauge name, ie "35mm-4perf", "35mm-3perf" or
+// "16mm". "35mm" on its own is 4-perf.
+func ParseFilmGauge(in string) (FilmGauge, error) {
+	name := strings.ToLower(strings.TrimSpace(in))
+	if name == "35mm" {
+		return Gauge35mm4Perf, nil
+	}
+	for _, gauge := range FilmGauges {
+		if name == gauge.Name {
+			return gauge, nil
+		}
+	}
+	return FilmGauge{}, NewError(ErrInvalidOption, "%s is not a valid film gauge. Use 35mm-4perf, 35mm-3perf or 16mm", in)
+}
+
+// IsZero reports whether no gauge has been set.
+func (g FilmGauge) IsZero() bool {
+	return g.Num == 0
+}
+
+// FramesPerFoot returns the approximate decimal frames in a foot.
+func (g FilmGauge) FramesPerFoot() float64 {
+	return float64(g.Num) / float64(g.Den)
+}
+
+func (g FilmGauge) String() string {
+	return g.Name
+}
+
+// feetToFrames is the frame a foot starts on.
+func (g FilmGauge) feetToFrames(feet int64) int64 {
+	q, _ := floorDivmod(feet*g.Num, g.Den)
+	return q
+}
+
+// framesToFeet is the foot a frame is in.
+func (g FilmGauge) framesToFeet(frames int64) int64 {
+	q, _ := floorDivmod((frames+1)*g.Den-1, g.Num)
+	return q
+}
+
+// Footage is a number of frames counted in feet+frames, ie 123+08. It can be
+// a length, or a position counted from 0+00 (which is 00:00:00:00).
+type Footage struct {
+	Gauge  FilmGauge
+	frames int64
+}
+
+// NewFootageFromFrames creates a footage from a frame count, which can be negative.
+func NewFootageFromFrames(frames int64, gauge FilmGauge) (*Footage, error) {
+	if gauge.IsZero() {
+		return nil, NewError(ErrInvalidOption, "A film gauge is needed for feet+frames")
+	}
+	return &Footage{Gauge: gauge, frames: frames}, nil
+}
+
+// NewFootageFromTimecode is the footage of a timecode's frame index, where
+// 00:00:00:00 is 0+00.
+func NewFootageFromTimecode(tc *Timecode, gauge FilmGauge) (*Footage, error) {
+	return NewFootageFromFrames(int64(tc.GetFrameIdx()), gauge)
+}
+
+var footageRe = regexp.MustCompile(`^(-?)([0-9]+)\+([0-9]{2})$`)
+
+// IsFootage reports whether a string looks like feet+frames, ie 123+08.
+func IsFootage(in string) bool {
+	return footageRe.MatchString(in)
+}
+
+// ParseFootage parses feet+frames, ie "123+08". The frames must fit in
+// the foot, so 123+16 is out of range for 35mm 4-perf.
+func ParseFootage(in string, gauge FilmGauge) (*Footage, error) {
+	if gauge.IsZero() {
+		return nil, NewError(ErrInvalidOption, "%s is feet+frames, which needs a film gauge (ie 35mm-4perf)", in)
+	}
+	m := footageRe.FindStringSubmatch(in)
+	if m == nil {
+		return nil, NewError(ErrMalformed, "Footage is malformed. Please format as feet+frames, ie 123+08")
+	}
+	feet, err := strconv.ParseInt(m[2], 10, 64)
+	if err != nil {
+		return nil, NewError(ErrMalformed, "Feet are malformed.")
+	}
+	frames, _ := strconv.ParseInt(m[3], 10, 64)
+
+	framesInFoot := gauge.feetToFrames(feet+1) - gauge.feetToFrames(feet)
+	if frames >= framesInFoot {
+		return nil, NewError(ErrFrameOutOfRange, "Foot %d of %s only has %d frames, so frames cannot be higher than %d", feet, gauge, framesInFoot, framesInFoot-1)
+	}
+
+	total := gauge.feetToFrames(feet) + frames
+	if m[1] == "-" {
+		total = -total
+	}
+	return &Footage{Gauge: gauge, frames: total}, nil
+}
+
+// GetFrameCount returns the footage as frames.
+func (f *Footage) GetFrameCount() int64 {
+	return f.frames
+}
+
+// GetComponents returns the feet and the frames into the last foot. Both are
+// negative for a negative footage.
+func (f *Footage) GetComponents() (feet, frames int64) {
+	abs := f.frames
+	if abs < 0 {
+		abs = -abs
+	}
+	feet = f.Gauge.framesToFeet(abs)
+	frames = abs - f.Gauge.feetToFrames(feet)
+	if f.frames < 0 {
+		return -feet, -frames
+	}
+	return feet, frames
+}
+
+// String formats the footage as feet+frames, ie "123+08" or "-2+15".
+func (f *Footage) String() string {
+	feet, frames := f.GetComponents()
+	sign := ""
+	if f.frames < 0 {
+		sign = "-"
+		feet, frames = -feet, -frames
+	}
+	return fmt.Sprintf("%s%d+%02d", sign, feet, frames)
+}
+
+// ToTimecode returns the timecode of the footage as a frame index, where
+// 0+00 is 00:00:00:00.
+func (f *Footage) ToTimecode(rate FrameRate, dropFrame bool) (*Timecode, error) {
+	return NewTimecodeFromFrames(f.frames, rate, dropFrame)
+}
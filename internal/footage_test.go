@@ -0,0 +1,98 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFilmGauge(t *testing.T) {
+	tests := []struct {
+		input     string
+		expected  FilmGauge
+		expectErr bool
+	}{
+		{"35mm-4perf", Gauge35mm4Perf, false},
+		{"35mm", Gauge35mm4Perf, false},
+		{"35MM-3PERF", Gauge35mm3Perf, false},
+		{" 16mm ", Gauge16mm, false},
+		{"8mm", FilmGauge{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			gauge, err := ParseFilmGauge(tt.input)
+			if tt.expectErr {
+				require.ErrorIs(t, err, ErrInvalidOption)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, gauge)
+		})
+	}
+}
+
+func TestParseFootage(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		gauge     FilmGauge
+		expected  int64
+		expectErr error
+	}{
+		{"4-perf", "123+08", Gauge35mm4Perf, 1976, nil},
+		{"4-perf zero", "0+00", Gauge35mm4Perf, 0, nil},
+		{"4-perf last frame of a foot", "1+15", Gauge35mm4Perf, 31, nil},
+		{"4-perf frames out of range", "1+16", Gauge35mm4Perf, 0, ErrFrameOutOfRange},
+		{"3-perf first foot", "1+00", Gauge35mm3Perf, 21, nil},
+		{"3-perf third foot has 22 frames", "2+21", Gauge35mm3Perf, 63, nil},
+		{"3-perf second foot has 21 frames", "1+21", Gauge35mm3Perf, 0, ErrFrameOutOfRange},
+		{"3-perf cycle", "3+00", Gauge35mm3Perf, 64, nil},
+		{"16mm", "10+39", Gauge16mm, 439, nil},
+		{"Negative", "-1+04", Gauge35mm4Perf, -20, nil},
+		{"One digit of frames", "12+8", Gauge35mm4Perf, 0, ErrMalformed},
+		{"No gauge", "12+08", FilmGauge{}, 0, ErrInvalidOption},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			footage, err := ParseFootage(tt.input, tt.gauge)
+			if tt.expectErr != nil {
+				require.ErrorIs(t, err, tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, footage.GetFrameCount())
+			require.Equal(t, tt.input, footage.String())
+		})
+	}
+}
+
+func TestFootageRoundTrip(t *testing.T) {
+	for _, gauge := range FilmGauges {
+		for frames := int64(-200); frames <= 200; frames++ {
+			footage, err := NewFootageFromFrames(frames, gauge)
+			require.NoError(t, err)
+			parsed, err := ParseFootage(footage.String(), gauge)
+			require.NoError(t, err, "%s %d frames is %s", gauge, frames, footage)
+			require.Equal(t, frames, parsed.GetFrameCount(), "%s %s", gauge, footage)
+		}
+	}
+}
+
+func TestFootageTimecode(t *testing.T) {
+	rate := FrameRateFromFloat(24)
+	tc, err := NewTimecodeFromString("00:01:22:08", rate)
+	require.NoError(t, err)
+
+	footage, err := NewFootageFromTimecode(tc, Gauge35mm4Perf)
+	require.NoError(t, err)
+	require.Equal(t, "123+08", footage.String())
+
+	back, err := footage.ToTimecode(rate, false)
+	require.NoError(t, err)
+	require.Equal(t, "00:01:22:08", back.GetTimecode())
+
+	_, err = NewFootageFromTimecode(tc, FilmGauge{})
+	require.ErrorIs(t, err, ErrInvalidOption)
+}
@@ -0,0 +1,202 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FrameRate is an exact rational frame rate. NTSC style rates are stored
+// as their true values (ie 29.97 is 30000/1001) so that conversions to
+// real time do not drift over long programs.
+type FrameRate struct {
+	Num int64
+	Den int64
+}
+
+// NewFrameRate will create a FrameRate from a numerator and denominator,
+// reducing the fraction. Rates below 1 fps don't have a timebase, so they
+// aren't valid.
+func NewFrameRate(num, den int64) (FrameRate, error) {
+	if num <= 0 || den <= 0 || num < den {
+		return FrameRate{}, NewError(ErrUnsupportedRate, "%d/%d is not a valid framerate", num, den)
+	}
+	g := gcd(num, den)
+	return FrameRate{Num: num / g, Den: den / g}, nil
+}
+
+// FrameRateFromFloat maps a decimal framerate to its exact rational value.
+// Values within a hundredth of an NTSC rate (23.976, 29.97, 59.94 etc)
+// become x000/1001, anything else is taken as is. Rates below 1 fps give the
+// zero FrameRate, which ParseFrameRate rejects.
+func FrameRateFromFloat(fps float64) FrameRate {
+	nominal := math.Round(fps)
+	if nominal >= 1 && nominal != fps && math.Abs(fps-nominal*1000/1001) < 0.01 {
+		return FrameRate{Num: int64(nominal) * 1000, Den: 1001}
+	}
+	// Nothing we support needs more than 3 decimal places.
+	r, _ := NewFrameRate(int64(math.Round(fps*1000)), 1000)
+	return r
+}
+
+// ParseFrameRate will parse a framerate string. Accepted formats are a
+// decimal ("29.97", "25"), a fraction ("30000/1001") and either of those
+// followed by a "DF" or "NDF" suffix ("29.97DF"). The returned bool is true
+// when the string asks for drop frame counting.
+func ParseFrameRate(in string) (FrameRate, bool, error) {
+	s := strings.ToUpper(strings.TrimSpace(in))
+
+	dropFrame := false
+	if strings.HasSuffix(s, "NDF") {
+		s = strings.TrimSpace(strings.TrimSuffix(s, "NDF"))
+	} else if strings.HasSuffix(s, "DF") {
+		s = strings.TrimSpace(strings.TrimSuffix(s, "DF"))
+		dropFrame = true
+	}
+
+	var r FrameRate
+	if num, den, ok := strings.Cut(s, "/"); ok {
+		n, errN := strconv.ParseInt(strings.TrimSpace(num), 10, 64)
+		d, errD := strconv.ParseInt(strings.TrimSpace(den), 10, 64)
+		if errN != nil || errD != nil {
+			return FrameRate{}, false, NewError(ErrUnsupportedRate, "%s is not a valid framerate", in)
+		}
+		var err error
+		if r, err = NewFrameRate(n, d); err != nil {
+			return FrameRate{}, false, err
+		}
+	} else {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil || f <= 0 || math.IsInf(f, 0) {
+			return FrameRate{}, false, NewError(ErrUnsupportedRate, "%s is not a valid framerate", in)
+		}
+		r = FrameRateFromFloat(f)
+		if !r.valid() {
+			return FrameRate{}, false, NewError(ErrUnsupportedRate, "%s is not a valid framerate", in)
+		}
+	}
+
+	if dropFrame && !r.SupportsDropFrame() {
+		return FrameRate{}, false, NewError(ErrInvalidDropFrame, "%s is not a valid framerate for drop frame timecode", r)
+	}
+
+	return r, dropFrame, nil
+}
+
+// Timebase is the nominal (integer) frame count of a second of timecode.
+// For instance, both 29.97 and 30 have a timebase of 30.
+func (r FrameRate) Timebase() int {
+	return int((r.Num + r.Den - 1) / r.Den)
+}
+
+// valid reports whether timecode can be counted at the rate, which needs a
+// timebase of at least 1. The zero FrameRate isn't valid.
+func (r FrameRate) valid() bool {
+	return r.Num > 0 && r.Den > 0 && r.Timebase() >= 1
+}
+
+// SupportsDropFrame reports whether drop frame timecode exists at this rate.
+// That is 29.97, 59.94 and 119.88.
+func (r FrameRate) SupportsDropFrame() bool {
+	if r.Den != 1001 {
+		return false
+	}
+	tb := r.Timebase()
+	return tb == 30 || tb == 60 || tb == 120
+}
+
+// DropFramesPerMinute is the amount of frame labels skipped at the start
+// of every minute (except each tenth minute) in drop frame timecode.
+// 2 at 29.97, 4 at 59.94 and 8 at 119.88.
+func (r FrameRate) DropFramesPerMinute() int {
+	if !r.SupportsDropFrame() {
+		return 0
+	}
+	return r.Timebase() / 15
+}
+
+// FramesPerDay is the number of frames in 24 hours of timecode.
+func (r FrameRate) FramesPerDay(dropFrame bool) int64 {
+	timeBase := int64(r.Timebase())
+	if dropFrame {
+		dropFrames := int64(r.DropFramesPerMinute())
+		return (timeBase*60*10 - dropFrames*9) * 6 * 24
+	}
+	return timeBase * 60 * 60 * 24
+}
+
+// Float64 returns the approximate decimal value of the framerate.
+func (r FrameRate) Float64() float64 {
+	return float64(r.Num) / float64(r.Den)
+}
+
+// Rational returns the framerate formatted as "num/den".
+func (r FrameRate) Rational() string {
+	return fmt.Sprintf("%d/%d", r.Num, r.Den)
+}
+
+// String returns the framerate as it is typically written, ie "29.97" or "25".
+func (r FrameRate) String() string {
+	if r.Den == 1 {
+		return strconv.FormatInt(r.Num, 10)
+	}
+	s := strconv.FormatFloat(r.Float64(), 'f', 3, 64)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// FramesToMilliseconds converts a frame count to real time milliseconds,
+// rounded to the nearest millisecond. This is done with integer math so
+// there is no drift regardless of the length.
+func (r FrameRate) FramesToMilliseconds(frames int64) int64 {
+	return roundDiv(frames*r.Den*1000, r.Num)
+}
+
+// FramesToDuration converts a frame count to real time, rounded to the
+// nearest nanosecond.
+func (r FrameRate) FramesToDuration(frames int64) time.Duration {
+	// Split into whole seconds and the remainder, as frames * den * 1e9
+	// would overflow an int64 for long durations.
+	secs, rem := divmod(frames*r.Den, r.Num)
+	return time.Duration(secs)*time.Second + time.Duration(roundDiv(rem*int64(time.Second), r.Num))
+}
+
+// FramesToSeconds converts a frame count to real time seconds.
+func (r FrameRate) FramesToSeconds(frames int64) float64 {
+	return float64(frames*r.Den) / float64(r.Num)
+}
+
+// FramesToSamples returns the first audio sample of a frame, counting from
+// frame 0 and sample 0. At pull down rates frames aren't a whole number of
+// samples (1601.6 at 29.97 and 48 kHz), so this is the first sample at or
+// after the start of the frame.
+func (r FrameRate) FramesToSamples(frames int64, sampleRate int) int64 {
+	return ceilDiv(frames*int64(sampleRate)*r.Den, r.Num)
+}
+
+// SamplesToFrames returns the frame an audio sample is in, and how many
+// samples it is after the first sample of that frame.
+func (r FrameRate) SamplesToFrames(samples int64, sampleRate int) (frames int64, offset int64) {
+	frames, _ = floorDivmod(samples*r.Num, int64(sampleRate)*r.Den)
+	return frames, samples - r.FramesToSamples(frames, sampleRate)
+}
+
+func gcd(a, b int64) int64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+// roundDiv divides and rounds half away from zero.
+func roundDiv(numerator, denominator int64) int64 {
+	if (numerator < 0) != (denominator < 0) {
+		return (numerator - denominator/2) / denominator
+	}
+	return (numerator + denominator/2) / denominator
+}
@@ -0,0 +1,95 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFrameRate(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		expected  FrameRate
+		dropFrame bool
+		expectErr bool
+	}{
+		{"Integer", "24", FrameRate{24, 1}, false, false},
+		{"NTSC decimal", "29.97", FrameRate{30000, 1001}, false, false},
+		{"NTSC short decimal", "23.98", FrameRate{24000, 1001}, false, false},
+		{"NTSC long decimal", "23.976", FrameRate{24000, 1001}, false, false},
+		{"Rational", "60000/1001", FrameRate{60000, 1001}, false, false},
+		{"Rational reduces", "50/2", FrameRate{25, 1}, false, false},
+		{"Non NTSC decimal", "12.5", FrameRate{25, 2}, false, false},
+		{"Drop frame suffix", "29.97DF", FrameRate{30000, 1001}, true, false},
+		{"Lower case suffix", "59.94 df", FrameRate{60000, 1001}, true, false},
+		{"Non drop frame suffix", "29.97NDF", FrameRate{30000, 1001}, false, false},
+		{"Drop frame not allowed", "25DF", FrameRate{}, false, true},
+		{"Zero", "0", FrameRate{}, false, true},
+		{"Zero denominator", "30000/0", FrameRate{}, false, true},
+		{"Below a frame a second", "0.001", FrameRate{}, false, true},
+		{"Rounds to zero", "0.0001", FrameRate{}, false, true},
+		{"Rational below a frame a second", "1/1000", FrameRate{}, false, true},
+		{"Garbage", "fast", FrameRate{}, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, df, err := ParseFrameRate(tt.input)
+			if tt.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, r)
+			require.Equal(t, tt.dropFrame, df)
+		})
+	}
+}
+
+func TestFrameRateString(t *testing.T) {
+	cases := map[string]FrameRate{
+		"23.976": {24000, 1001},
+		"29.97":  {30000, 1001},
+		"59.94":  {60000, 1001},
+		"25":     {25, 1},
+		"12.5":   {25, 2},
+	}
+
+	for expected, r := range cases {
+		require.Equal(t, expected, r.String())
+	}
+}
+
+func TestTimebase(t *testing.T) {
+	cases := []struct {
+		framerate FrameRate
+		expected  int
+	}{
+		{FrameRate{30000, 1001}, 30},
+		{FrameRate{60000, 1001}, 60},
+		{FrameRate{24, 1}, 24},
+		{FrameRate{25, 2}, 13},
+	}
+
+	for _, c := range cases {
+		result := c.framerate.Timebase()
+		if result != c.expected {
+			t.Errorf("%s Timebase() = %d; expected %d", c.framerate.Rational(), result, c.expected)
+		}
+	}
+}
+
+func TestDropFramesPerMinute(t *testing.T) {
+	require.Equal(t, 2, FrameRate{30000, 1001}.DropFramesPerMinute())
+	require.Equal(t, 4, FrameRate{60000, 1001}.DropFramesPerMinute())
+	require.Equal(t, 0, FrameRate{24000, 1001}.DropFramesPerMinute())
+	require.Equal(t, 0, FrameRate{30, 1}.DropFramesPerMinute())
+}
+
+func TestFramesToMilliseconds(t *testing.T) {
+	// One hour of 29.97 NDF timecode is 3.6 seconds longer than an hour.
+	require.Equal(t, int64(3603600), FrameRate{30000, 1001}.FramesToMilliseconds(108000))
+	require.Equal(t, int64(1000), FrameRate{25, 1}.FramesToMilliseconds(25))
+	require.Equal(t, int64(42), FrameRate{24, 1}.FramesToMilliseconds(1))
+}
@@ -0,0 +1,342 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+)
+
+// LTCFrame is one 80 bit frame of SMPTE ST 12-1 linear timecode.
+type LTCFrame struct {
+	Timecode   *Timecode
+	ColorFrame bool
+	UserBits   UserBits
+	// BinaryGroupFlags are BGF0 to BGF2, with BGF0 in the lowest bit.
+	BinaryGroupFlags uint8
+	// Reverse is set when the frame was decoded from audio playing backwards.
+	Reverse bool
+	// Sample is where the frame starts in the audio.
+	Sample int64
+}
+
+// LTCBits is an 80 bit LTC frame as it is sent, with bit 0 of the frame in
+// the lowest bit of the first byte.
+type LTCBits [10]byte
+
+const (
+	ltcFrameBits = 80
+	// ltcSyncWord is bits 64 to 79, read from bit 64 as the lowest bit.
+	ltcSyncWord = 0xBFFC
+	// ltcReverseSyncWord is the sync word as it arrives when the audio is
+	// played backwards.
+	ltcReverseSyncWord = 0x3FFD
+)
+
+func (b *LTCBits) bit(i int) bool {
+	return b[i/8]&(1<<(i%8)) != 0
+}
+
+func (b *LTCBits) setBit(i int, v bool) {
+	if v {
+		b[i/8] |= 1 << (i % 8)
+	} else {
+		b[i/8] &^= 1 << (i % 8)
+	}
+}
+
+// field reads n bits starting at bit i, with bit i as the lowest bit.
+func (b *LTCBits) field(i, n int) int {
+	v := 0
+	for j := n - 1; j >= 0; j-- {
+		v <<= 1
+		if b.bit(i + j) {
+			v |= 1
+		}
+	}
+	return v
+}
+
+func (b *LTCBits) setField(i, n, v int) {
+	for j := 0; j < n; j++ {
+		b.setBit(i+j, v&(1<<j) != 0)
+	}
+}
+
+// EncodeLTCFrame packs a frame into its 80 bits, including the sync word and
+// the polarity correction bit. The 64 bits before the sync word are the SMPTE
+// 12M time address with a user bit group after each of its 4 bit digits.
+func EncodeLTCFrame(frame LTCFrame) (LTCBits, error) {
+	var bits LTCBits
+	word, err := frame.Timecode.PackSMPTE(SMPTEFlags{ColorFrame: frame.ColorFrame, BinaryGroupFlags: frame.BinaryGroupFlags})
+	if err != nil {
+		return bits, err
+	}
+	for n := 0; n < 8; n++ {
+		bits.setField(8*n, 4, int(word>>(4*n))&0xF)
+		bits.setField(8*n+4, 4, frame.UserBits.Group(n+1))
+	}
+	bits.setField(64, 16, ltcSyncWord)
+
+	// An even number of ones starts every frame on the same polarity. The
+	// polarity correction bit is the field bit of the time address.
+	ones := 0
+	for i := 0; i < ltcFrameBits; i++ {
+		if bits.bit(i) {
+			ones++
+		}
+	}
+	bits.setBit(ltcPolarityBit(frame.Timecode.FrameRate), ones%2 == 1)
+	return bits, nil
+}
+
+// ltcPolarityBit is where the field bit of the time address ends up in LTC.
+func ltcPolarityBit(rate FrameRate) int {
+	field, _ := smpteFlagBits(rate)
+	return 8*(field/4) + field%4
+}
+
+// DecodeLTCFrame unpacks the 80 bits of a frame. LTC doesn't say what the
+// frame rate is, so it has to be given. Drop frame comes from the frame.
+func DecodeLTCFrame(bits LTCBits, rate FrameRate) (*LTCFrame, error) {
+	if err := checkSMPTERate(rate); err != nil {
+		return nil, err
+	}
+	if bits.field(64, 16) != ltcSyncWord {
+		return nil, NewError(ErrMalformed, "The LTC frame has no sync word")
+	}
+
+	var word, userBits uint32
+	for n := 0; n < 8; n++ {
+		word |= uint32(bits.field(8*n, 4)) << (4 * n)
+		userBits |= uint32(bits.field(8*n+4, 4)) << (4 * n)
+	}
+	tc, flags, err := UnpackSMPTE(word, rate)
+	if err != nil {
+		return nil, fmt.Errorf("LTC frame: %w", err)
+	}
+	return &LTCFrame{
+		Timecode:         tc,
+		ColorFrame:       flags.ColorFrame,
+		UserBits:         UserBits(userBits),
+		BinaryGroupFlags: flags.BinaryGroupFlags,
+	}, nil
+}
+
+// LTCEncodeOptions are the settings for rendering LTC audio.
+type LTCEncodeOptions struct {
+	SampleRate int
+	// Amplitude is the peak level, from 0 to 1. 0 is 0.5 (-6 dBFS).
+	Amplitude  float64
+	UserBits   UserBits
+	ColorFrame bool
+}
+
+// EncodeLTC renders frames of LTC audio counting up from start. The samples
+// are a biphase mark square wave, which flips at the start of every bit and
+// again half way through a one.
+func EncodeLTC(start *Timecode, frames int, options LTCEncodeOptions) ([]float64, error) {
+	if options.SampleRate < 1 {
+		return nil, NewError(ErrInvalidOption, "The sample rate must be above 0")
+	}
+	// A half bit needs at least two samples to be decoded.
+	rate := start.FrameRate
+	if int64(options.SampleRate)*rate.Den < 2*ltcFrameBits*2*rate.Num {
+		minimum := (2*ltcFrameBits*2*rate.Num + rate.Den - 1) / rate.Den
+		return nil, NewError(ErrInvalidOption, "The sample rate must be at least %d for LTC at %s fps", minimum, rate)
+	}
+	if frames < 1 {
+		return nil, NewError(ErrInvalidOption, "At least one frame of LTC is needed")
+	}
+	amplitude := options.Amplitude
+	if amplitude == 0 {
+		amplitude = 0.5
+	}
+	if amplitude < 0 || amplitude > 1 {
+		return nil, NewError(ErrInvalidOption, "The amplitude must be between 0 and 1")
+	}
+
+	// The level of each half bit.
+	halves := make([]bool, 0, frames*ltcFrameBits*2)
+	level := false
+	tc := *start
+	for i := 0; i < frames; i++ {
+		bits, err := EncodeLTCFrame(LTCFrame{Timecode: &tc, UserBits: options.UserBits, ColorFrame: options.ColorFrame})
+		if err != nil {
+			return nil, err
+		}
+		for b := 0; b < ltcFrameBits; b++ {
+			level = !level
+			halves = append(halves, level)
+			if bits.bit(b) {
+				level = !level
+			}
+			halves = append(halves, level)
+		}
+		tc.AddFrames(1)
+	}
+
+	// There are 160 half bits a frame, so half bit n is at n * sampleRate / (160 * fps) samples.
+	perSample := 160 * rate.Num
+	perHalf := rate.Den * int64(options.SampleRate)
+	total := int64(len(halves)) * perHalf / perSample
+	samples := make([]float64, total)
+	for i := range samples {
+		if halves[int64(i)*perSample/perHalf] {
+			samples[i] = amplitude
+		} else {
+			samples[i] = -amplitude
+		}
+	}
+	return samples, nil
+}
+
+// DecodeLTC finds the LTC frames in audio. It follows the bit rate as it
+// drifts, so varispeed audio decodes as long as it is close to the given
+// frame rate. Audio that is playing backwards is decoded too, with Reverse
+// set on each frame.
+func DecodeLTC(samples []float64, sampleRate int, rate FrameRate) ([]*LTCFrame, error) {
+	if err := checkSMPTERate(rate); err != nil {
+		return nil, err
+	}
+	if sampleRate < 1 {
+		return nil, NewError(ErrInvalidOption, "The sample rate must be above 0")
+	}
+
+	peak := 0.0
+	for _, s := range samples {
+		peak = math.Max(peak, math.Abs(s))
+	}
+	if peak == 0 {
+		return nil, nil
+	}
+	// Hysteresis stops noise around zero from looking like transitions.
+	threshold := peak * 0.1
+
+	d := &ltcDecoder{
+		rate:      rate,
+		bitPeriod: float64(sampleRate) * float64(rate.Den) / float64(ltcFrameBits*rate.Num),
+		last:      -1,
+	}
+
+	high := samples[0] > 0
+	crossing := 0.0
+	// Audio that starts or ends on a level has an edge there, which times
+	// the first and last bits.
+	if math.Abs(samples[0]) > threshold {
+		d.transition(0)
+	}
+	for i := 1; i < len(samples); i++ {
+		prev, s := samples[i-1], samples[i]
+		if (prev > 0) != (s > 0) {
+			// Where the signal crosses zero, between the two samples.
+			crossing = float64(i-1) + prev/(prev-s)
+		}
+		if high && s < -threshold || !high && s > threshold {
+			high = !high
+			d.transition(crossing)
+		}
+	}
+	if math.Abs(samples[len(samples)-1]) > threshold {
+		d.transition(float64(len(samples)))
+	}
+	return d.frames, nil
+}
+
+type ltcDecoder struct {
+	rate FrameRate
+	// bitPeriod is how many samples a bit lasts. It is updated as bits are
+	// decoded.
+	bitPeriod float64
+	// last is the last transition, or -1 before the first.
+	last float64
+	// half is the start of the first half of a one, when one has been seen.
+	half   float64
+	inHalf bool
+	bits   []bool
+	starts []float64
+	frames []*LTCFrame
+}
+
+// transition measures the time since the last transition. About a bit long is
+// a zero, and two about half a bit long are a one.
+func (d *ltcDecoder) transition(at float64) {
+	last := d.last
+	d.last = at
+	if last < 0 {
+		return
+	}
+	interval := at - last
+
+	switch {
+	case interval > d.bitPeriod*1.5:
+		// A drop out. Start looking for a sync word again.
+		d.reset()
+	case interval > d.bitPeriod*0.75:
+		d.bitPeriod = d.bitPeriod*0.9 + interval*0.1
+		d.inHalf = false
+		d.push(false, last)
+	case d.inHalf:
+		d.bitPeriod = d.bitPeriod*0.9 + (at-d.half)*0.1
+		d.inHalf = false
+		d.push(true, d.half)
+	default:
+		d.half = last
+		d.inHalf = true
+	}
+}
+
+func (d *ltcDecoder) reset() {
+	d.inHalf = false
+	d.bits = d.bits[:0]
+	d.starts = d.starts[:0]
+}
+
+func (d *ltcDecoder) push(bit bool, start float64) {
+	d.bits = append(d.bits, bit)
+	d.starts = append(d.starts, start)
+	if len(d.bits) > ltcFrameBits {
+		d.bits = d.bits[1:]
+		d.starts = d.starts[1:]
+	}
+	if len(d.bits) < ltcFrameBits {
+		return
+	}
+
+	var bits LTCBits
+	reverse := false
+	switch {
+	case d.word(ltcFrameBits-16) == ltcSyncWord:
+		for i, b := range d.bits {
+			bits.setBit(i, b)
+		}
+	case d.word(0) == ltcReverseSyncWord:
+		// Backwards, bit 79 arrives first.
+		reverse = true
+		for i, b := range d.bits {
+			bits.setBit(ltcFrameBits-1-i, b)
+		}
+	default:
+		return
+	}
+
+	// Frames with bad digits are skipped, the next sync word will pick up
+	// the following frame.
+	if frame, err := DecodeLTCFrame(bits, d.rate); err == nil {
+		frame.Reverse = reverse
+		frame.Sample = int64(math.Round(d.starts[0]))
+		d.frames = append(d.frames, frame)
+	}
+	d.bits = d.bits[:0]
+	d.starts = d.starts[:0]
+}
+
+// word reads 16 of the received bits, starting at i, with the first as the lowest bit.
+func (d *ltcDecoder) word(i int) int {
+	v := 0
+	for j := 15; j >= 0; j-- {
+		v <<= 1
+		if d.bits[i+j] {
+			v |= 1
+		}
+	}
+	return v
+}
@@ -0,0 +1,185 @@
+package internal
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLTCFrameRoundTrip(t *testing.T) {
+	tests := []struct {
+		name       string
+		timecode   string
+		fps        string
+		userBits   UserBits
+		colorFrame bool
+		flags      uint8
+	}{
+		{"24", "01:02:03:04", "24", 0, false, 0},
+		{"25 with flags", "23:59:59:24", "25", 0x12345678, false, 0b101},
+		{"29.97DF", "00:10:00;00", "29.97", 0xFFFFFFFF, true, 0b010},
+		{"30", "12:34:56:29", "30", 0x0000000A, false, 0b001},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, _, err := ParseFrameRate(tt.fps)
+			require.NoError(t, err)
+			tc, err := NewTimecodeFromString(tt.timecode, rate)
+			require.NoError(t, err)
+
+			bits, err := EncodeLTCFrame(LTCFrame{Timecode: tc, UserBits: tt.userBits, ColorFrame: tt.colorFrame, BinaryGroupFlags: tt.flags})
+			require.NoError(t, err)
+
+			ones := 0
+			for i := 0; i < ltcFrameBits; i++ {
+				if bits.bit(i) {
+					ones++
+				}
+			}
+			require.Zero(t, ones%2, "the polarity correction bit should make the ones even")
+
+			frame, err := DecodeLTCFrame(bits, rate)
+			require.NoError(t, err)
+			require.Equal(t, tt.timecode, frame.Timecode.GetTimecode())
+			require.Equal(t, tc.DropFrame, frame.Timecode.DropFrame)
+			require.Equal(t, tt.userBits, frame.UserBits)
+			require.Equal(t, tt.colorFrame, frame.ColorFrame)
+			require.Equal(t, tt.flags, frame.BinaryGroupFlags)
+		})
+	}
+}
+
+func TestLTCFrameErrors(t *testing.T) {
+	rate := FrameRateFromFloat(25)
+	tc, err := NewTimecodeFromString("01:00:00:00", rate)
+	require.NoError(t, err)
+	bits, err := EncodeLTCFrame(LTCFrame{Timecode: tc})
+	require.NoError(t, err)
+
+	noSync := bits
+	noSync[9] = 0
+	_, err = DecodeLTCFrame(noSync, rate)
+	require.ErrorIs(t, err, ErrMalformed)
+
+	badDigit := bits
+	badDigit.setField(0, 4, 12)
+	_, err = DecodeLTCFrame(badDigit, rate)
+	require.ErrorIs(t, err, ErrMalformed)
+
+	_, err = DecodeLTCFrame(bits, FrameRateFromFloat(50))
+	require.ErrorIs(t, err, ErrUnsupportedRate)
+}
+
+func TestLTCAudioRoundTrip(t *testing.T) {
+	tests := []struct {
+		name       string
+		start      string
+		fps        string
+		sampleRate int
+		reverse    bool
+	}{
+		{"24 at 48k", "00:59:59:20", "24", 48000, false},
+		{"25 at 44.1k", "10:00:00:00", "25", 44100, false},
+		{"29.97DF across a minute", "00:00:59;25", "29.97", 48000, false},
+		{"30 at 96k", "23:59:59:25", "30", 96000, false},
+		{"25 at the lowest sample rate", "01:00:00:00", "25", 8000, false},
+		{"23.976 backwards", "01:00:00:00", "23.976", 48000, true},
+		{"29.97DF backwards", "00:09:59;28", "29.97", 44100, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, _, err := ParseFrameRate(tt.fps)
+			require.NoError(t, err)
+			start, err := NewTimecodeFromString(tt.start, rate)
+			require.NoError(t, err)
+
+			const frames = 12
+			samples, err := EncodeLTC(start, frames, LTCEncodeOptions{SampleRate: tt.sampleRate, UserBits: 0xCAFE0123})
+			require.NoError(t, err)
+
+			// Through a 16 bit WAV file, as the CLI would.
+			var buf bytes.Buffer
+			require.NoError(t, WriteWAV(&buf, &WAV{SampleRate: tt.sampleRate, BitsPerSample: 16, Samples: [][]float64{samples}}))
+			wav, err := ReadWAV(&buf)
+			require.NoError(t, err)
+			require.Equal(t, tt.sampleRate, wav.SampleRate)
+			samples = wav.Samples[0]
+
+			if tt.reverse {
+				for i, j := 0, len(samples)-1; i < j; i, j = i+1, j-1 {
+					samples[i], samples[j] = samples[j], samples[i]
+				}
+			}
+
+			decoded, err := DecodeLTC(samples, wav.SampleRate, rate)
+			require.NoError(t, err)
+
+			require.Len(t, decoded, frames)
+
+			expected := *start
+			if tt.reverse {
+				expected.AddFrames(frames - 1)
+			}
+			for _, frame := range decoded {
+				require.Equal(t, expected.GetTimecode(), frame.Timecode.GetTimecode())
+				require.Equal(t, tt.reverse, frame.Reverse)
+				require.Equal(t, UserBits(0xCAFE0123), frame.UserBits)
+				if tt.reverse {
+					expected.AddFrames(-1)
+				} else {
+					expected.AddFrames(1)
+				}
+			}
+		})
+	}
+}
+
+func TestLTCDecodeSilence(t *testing.T) {
+	frames, err := DecodeLTC(make([]float64, 4800), 48000, FrameRateFromFloat(25))
+	require.NoError(t, err)
+	require.Empty(t, frames)
+}
+
+func TestEncodeLTCSampleRateTooLow(t *testing.T) {
+	tests := []struct {
+		fps        string
+		sampleRate int
+		expected   string
+	}{
+		{"25", 1000, "The sample rate must be at least 8000 for LTC at 25 fps"},
+		{"25", 7999, "The sample rate must be at least 8000 for LTC at 25 fps"},
+		{"29.97", 9000, "The sample rate must be at least 9591 for LTC at 29.97 fps"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fps, func(t *testing.T) {
+			rate, _, err := ParseFrameRate(tt.fps)
+			require.NoError(t, err)
+			start, err := NewTimecodeFromString("01:00:00:00", rate)
+			require.NoError(t, err)
+			_, err = EncodeLTC(start, 1, LTCEncodeOptions{SampleRate: tt.sampleRate})
+			require.ErrorIs(t, err, ErrInvalidOption)
+			require.EqualError(t, err, tt.expected)
+		})
+	}
+}
+
+func TestLTCVarispeed(t *testing.T) {
+	rate := FrameRateFromFloat(25)
+	start, err := NewTimecodeFromString("01:00:00:00", rate)
+	require.NoError(t, err)
+	samples, err := EncodeLTC(start, 10, LTCEncodeOptions{SampleRate: 48000})
+	require.NoError(t, err)
+
+	// Decoding at the wrong sample rate is the same as the tape running 5%
+	// fast or slow.
+	for _, sampleRate := range []int{45600, 50400} {
+		frames, err := DecodeLTC(samples, sampleRate, rate)
+		require.NoError(t, err)
+		require.Len(t, frames, 10, "%d Hz", sampleRate)
+		require.Equal(t, "01:00:00:09", frames[9].Timecode.GetTimecode())
+	}
+}
@@ -0,0 +1,328 @@
+package internal
+
+// MTC rate codes, as carried in the hours of MIDI timecode.
+const (
+	MTCRate24     = 0
+	MTCRate25     = 1
+	MTCRate2997DF = 2
+	MTCRate30     = 3
+)
+
+const (
+	mtcQuarterFrame = 0xF1
+	mtcSysExStart   = 0xF0
+	mtcSysExEnd     = 0xF7
+)
+
+// MTCRateCode returns the MTC rate code of a rate. MTC only has codes for 24,
+// 25, 30 drop frame and 30, so 23.976 is sent as 24 and 29.97 non drop frame
+// as 30.
+func MTCRateCode(rate FrameRate, dropFrame bool) (int, error) {
+	switch {
+	case rate.Timebase() == 24 && !dropFrame:
+		return MTCRate24, nil
+	case rate.Timebase() == 25 && !dropFrame:
+		return MTCRate25, nil
+	case rate.Timebase() == 30 && dropFrame:
+		return MTCRate2997DF, nil
+	case rate.Timebase() == 30:
+		return MTCRate30, nil
+	}
+	df := ""
+	if dropFrame {
+		df = "DF"
+	}
+	return 0, NewError(ErrUnsupportedRate, "MTC can't carry %s%s. It has 24, 25, 29.97DF and 30", rate, df)
+}
+
+// mtcRate is the rate a rate code is read as. A given rate is used instead
+// when it has the same timebase, so 24 can be read as 23.976.
+func mtcRate(code int, rate FrameRate) (FrameRate, bool, error) {
+	codeRates := [4]FrameRate{{Num: 24, Den: 1}, {Num: 25, Den: 1}, {Num: 30000, Den: 1001}, {Num: 30, Den: 1}}
+	dropFrame := code == MTCRate2997DF
+	if rate.Num == 0 {
+		return codeRates[code], dropFrame, nil
+	}
+	if rate.Timebase() != codeRates[code].Timebase() {
+		return rate, false, NewError(ErrUnsupportedRate, "The MTC is at %s fps (rate code %d), not %s", codeRates[code], code, rate)
+	}
+	return rate, dropFrame, nil
+}
+
+func mtcTimecode(hours, minutes, seconds, frames, code int, rate FrameRate) (*Timecode, error) {
+	rate, dropFrame, err := mtcRate(code, rate)
+	if err != nil {
+		return nil, err
+	}
+	tc, err := NewTimecodeFromString(formatTimecode(int64(hours), int64(minutes), int64(seconds), int64(frames), dropFrame, rate), rate)
+	if err != nil {
+		return nil, err
+	}
+	if err := tc.Validate(); err != nil {
+		return nil, err
+	}
+	return tc, nil
+}
+
+// MTCFullFrame returns the full frame SysEx message for a timecode, which
+// tells a receiver to locate to it.
+func MTCFullFrame(tc *Timecode) ([]byte, error) {
+	code, err := MTCRateCode(tc.FrameRate, tc.DropFrame)
+	if err != nil {
+		return nil, err
+	}
+	if err := tc.Validate(); err != nil {
+		return nil, err
+	}
+	hours, minutes, seconds, frames := tc.GetComponents()
+	return []byte{
+		mtcSysExStart, 0x7F, 0x7F, 0x01, 0x01,
+		byte(code<<5 | hours), byte(minutes), byte(seconds), byte(frames),
+		mtcSysExEnd,
+	}, nil
+}
+
+// ParseMTCFullFrame reads a full frame SysEx message, from F0 to F7. rate
+// is used when it has the same timebase as the rate code, otherwise leave it
+// as the zero FrameRate to use the rate code's.
+func ParseMTCFullFrame(msg []byte, rate FrameRate) (*Timecode, error) {
+	if len(msg) != 10 || msg[0] != mtcSysExStart || msg[1] != 0x7F || msg[3] != 0x01 || msg[4] != 0x01 || msg[9] != mtcSysExEnd {
+		return nil, NewError(ErrMalformed, "This is not an MTC full frame message")
+	}
+	return mtcTimecode(int(msg[5]&0x1F), int(msg[6]&0x3F), int(msg[7]&0x3F), int(msg[8]&0x1F), int(msg[5]>>5&0x3), rate)
+}
+
+// MTCQuarterFrames returns the 8 quarter frame messages (F1 and a data byte
+// each) that carry a timecode, piece 0 first. They are sent 4 a frame, so
+// going forwards the timecode is the frame piece 0 is sent on.
+func MTCQuarterFrames(tc *Timecode) ([]byte, error) {
+	code, err := MTCRateCode(tc.FrameRate, tc.DropFrame)
+	if err != nil {
+		return nil, err
+	}
+	if err := tc.Validate(); err != nil {
+		return nil, err
+	}
+	hours, minutes, seconds, frames := tc.GetComponents()
+	nibbles := [8]int{
+		frames & 0xF, frames >> 4,
+		seconds & 0xF, seconds >> 4,
+		minutes & 0xF, minutes >> 4,
+		hours & 0xF, hours>>4 | code<<1,
+	}
+	msgs := make([]byte, 0, 16)
+	for piece, nibble := range nibbles {
+		msgs = append(msgs, mtcQuarterFrame, byte(piece<<4|nibble))
+	}
+	return msgs, nil
+}
+
+// EncodeMTC returns a full frame message for start, then the quarter frames
+// for frames frames from it. Going in reverse, the timecode counts down from
+// start and each set of quarter frames is sent from piece 7 to piece 0.
+func EncodeMTC(start *Timecode, frames int, reverse bool) ([]byte, error) {
+	if frames < 1 {
+		return nil, NewError(ErrInvalidOption, "At least one frame of MTC is needed")
+	}
+	out, err := MTCFullFrame(start)
+	if err != nil {
+		return nil, err
+	}
+	step := 2
+	if reverse {
+		step = -2
+	}
+	tc := *start
+	// Each set of 8 quarter frames takes 2 frames.
+	for i := 0; i < frames; i += 2 {
+		msgs, err := MTCQuarterFrames(&tc)
+		if err != nil {
+			return nil, err
+		}
+		if reverse {
+			for piece := 7; piece >= 0; piece-- {
+				out = append(out, msgs[2*piece:2*piece+2]...)
+			}
+		} else {
+			out = append(out, msgs...)
+		}
+		tc.AddFrames(step)
+	}
+	return out, nil
+}
+
+// MTCDirection is the way MTC quarter frames are running.
+type MTCDirection int
+
+const (
+	MTCStopped MTCDirection = iota
+	MTCForward
+	MTCReverse
+)
+
+func (d MTCDirection) String() string {
+	switch d {
+	case MTCForward:
+		return "forward"
+	case MTCReverse:
+		return "reverse"
+	}
+	return "stopped"
+}
+
+// MTCEvent is a timecode found in an MTC stream.
+type MTCEvent struct {
+	Timecode *Timecode
+	// FullFrame is set for a full frame message, which is a locate rather
+	// than running timecode.
+	FullFrame bool
+	// Direction is the way the quarter frames were running. It is
+	// MTCStopped for a full frame message.
+	Direction MTCDirection
+	// Offset is where the message that completed the timecode starts in
+	// the stream.
+	Offset int
+}
+
+// MTCDecoder reads MTC a byte at a time from a MIDI stream. Anything that
+// isn't MTC, such as notes or clock, is skipped.
+type MTCDecoder struct {
+	rate FrameRate
+
+	offset int
+	// sysex is the SysEx message being read, from F0.
+	sysex      []byte
+	inSysEx    bool
+	sysexStart int
+
+	// quarterFrame is set after an F1, when its data byte is next.
+	quarterFrame      bool
+	quarterFrameStart int
+
+	nibbles [8]int
+	// have is how many pieces in a row have been seen, in the direction.
+	have      int
+	lastPiece int
+	direction MTCDirection
+}
+
+// NewMTCDecoder creates a decoder. rate is used when it has the same timebase
+// as the rate code, ie to read 24 as 23.976. Leave it as the zero FrameRate
+// to use the rate code's rate.
+func NewMTCDecoder(rate FrameRate) *MTCDecoder {
+	return &MTCDecoder{rate: rate, lastPiece: -1}
+}
+
+// Direction is the way the quarter frames are running.
+func (d *MTCDecoder) Direction() MTCDirection {
+	return d.direction
+}
+
+// Decode reads the next byte. It returns an event when the byte completes a
+// full frame message or a set of 8 quarter frames, and an error when that
+// timecode isn't valid.
+func (d *MTCDecoder) Decode(b byte) (*MTCEvent, error) {
+	offset := d.offset
+	d.offset++
+
+	// Real time messages can turn up anywhere, even inside a SysEx.
+	if b >= 0xF8 {
+		return nil, nil
+	}
+
+	if d.quarterFrame {
+		d.quarterFrame = false
+		if b < 0x80 {
+			return d.quarterFrameData(b)
+		}
+	}
+
+	switch {
+	case b == mtcSysExStart:
+		d.inSysEx = true
+		d.sysexStart = offset
+		d.sysex = append(d.sysex[:0], b)
+	case b == mtcSysExEnd && d.inSysEx:
+		d.inSysEx = false
+		msg := append(d.sysex, b)
+		if len(msg) != 10 || msg[1] != 0x7F || msg[3] != 0x01 || msg[4] != 0x01 {
+			// Some other SysEx.
+			return nil, nil
+		}
+		tc, err := ParseMTCFullFrame(msg, d.rate)
+		if err != nil {
+			return nil, err
+		}
+		// A locate restarts the quarter frames.
+		d.have, d.lastPiece, d.direction = 0, -1, MTCStopped
+		return &MTCEvent{Timecode: tc, FullFrame: true, Offset: d.sysexStart}, nil
+	case b < 0x80 && d.inSysEx:
+		// Anything longer than a full frame message is some other SysEx,
+		// so there is no need to keep it all.
+		if len(d.sysex) < 10 {
+			d.sysex = append(d.sysex, b)
+		}
+	case b >= 0x80:
+		// Any other status byte ends a SysEx.
+		d.inSysEx = false
+		if b == mtcQuarterFrame {
+			d.quarterFrame = true
+			d.quarterFrameStart = offset
+		}
+	}
+	return nil, nil
+}
+
+func (d *MTCDecoder) quarterFrameData(b byte) (*MTCEvent, error) {
+	piece := int(b >> 4 & 0x7)
+	d.nibbles[piece] = int(b & 0xF)
+
+	switch {
+	case d.lastPiece >= 0 && piece == (d.lastPiece+1)%8:
+		if d.direction != MTCForward {
+			d.have = 1
+		}
+		d.direction = MTCForward
+		d.have++
+	case d.lastPiece >= 0 && piece == (d.lastPiece+7)%8:
+		if d.direction != MTCReverse {
+			d.have = 1
+		}
+		d.direction = MTCReverse
+		d.have++
+	default:
+		// The first quarter frame, or one was missed.
+		d.have = 1
+	}
+	d.lastPiece = piece
+
+	complete := d.have >= 8 &&
+		(d.direction == MTCForward && piece == 7 || d.direction == MTCReverse && piece == 0)
+	if !complete {
+		return nil, nil
+	}
+
+	n := d.nibbles
+	tc, err := mtcTimecode(n[7]&0x1<<4|n[6], n[5]&0x3<<4|n[4], n[3]&0x3<<4|n[2], n[1]&0x1<<4|n[0], n[7]>>1&0x3, d.rate)
+	if err != nil {
+		return nil, err
+	}
+	return &MTCEvent{Timecode: tc, Direction: d.direction, Offset: d.quarterFrameStart}, nil
+}
+
+// DecodeMTC reads every MTC timecode in a MIDI byte stream. Invalid
+// timecodes are skipped, and counted.
+func DecodeMTC(data []byte, rate FrameRate) (events []*MTCEvent, invalid int) {
+	d := NewMTCDecoder(rate)
+	for _, b := range data {
+		event, err := d.Decode(b)
+		if err != nil {
+			invalid++
+			continue
+		}
+		if event != nil {
+			events = append(events, event)
+		}
+	}
+	return events, invalid
+}
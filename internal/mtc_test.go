@@ -0,0 +1,202 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMTCFullFrame(t *testing.T) {
+	tests := []struct {
+		name     string
+		timecode string
+		fps      string
+		expected []byte
+	}{
+		{"24", "01:02:03:04", "24", []byte{0xF0, 0x7F, 0x7F, 0x01, 0x01, 0x01, 0x02, 0x03, 0x04, 0xF7}},
+		{"25", "23:59:59:24", "25", []byte{0xF0, 0x7F, 0x7F, 0x01, 0x01, 0x37, 0x3B, 0x3B, 0x18, 0xF7}},
+		{"29.97DF", "00:10:00;00", "29.97", []byte{0xF0, 0x7F, 0x7F, 0x01, 0x01, 0x40, 0x0A, 0x00, 0x00, 0xF7}},
+		{"30", "10:00:00:29", "30", []byte{0xF0, 0x7F, 0x7F, 0x01, 0x01, 0x6A, 0x00, 0x00, 0x1D, 0xF7}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, _, err := ParseFrameRate(tt.fps)
+			require.NoError(t, err)
+			tc, err := NewTimecodeFromString(tt.timecode, rate)
+			require.NoError(t, err)
+
+			msg, err := MTCFullFrame(tc)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, msg)
+
+			back, err := ParseMTCFullFrame(msg, FrameRate{})
+			require.NoError(t, err)
+			require.Equal(t, tt.timecode, back.GetTimecode())
+			require.Equal(t, rate, back.FrameRate)
+		})
+	}
+}
+
+func TestMTCRateCode(t *testing.T) {
+	tests := []struct {
+		fps       string
+		expected  int
+		expectErr error
+	}{
+		{"23.976", MTCRate24, nil},
+		{"24", MTCRate24, nil},
+		{"25", MTCRate25, nil},
+		{"29.97DF", MTCRate2997DF, nil},
+		{"29.97", MTCRate30, nil},
+		{"30", MTCRate30, nil},
+		{"50", 0, ErrUnsupportedRate},
+		{"59.94DF", 0, ErrUnsupportedRate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fps, func(t *testing.T) {
+			rate, df, err := ParseFrameRate(tt.fps)
+			require.NoError(t, err)
+			code, err := MTCRateCode(rate, df)
+			if tt.expectErr != nil {
+				require.ErrorIs(t, err, tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, code)
+		})
+	}
+}
+
+func TestMTCQuarterFrames(t *testing.T) {
+	rate := FrameRateFromFloat(29.97)
+	tc, err := NewTimecodeFromString("01:37:52;16", rate)
+	require.NoError(t, err)
+
+	msgs, err := MTCQuarterFrames(tc)
+	require.NoError(t, err)
+	// 16 frames is 0x10, 52 seconds 0x34, 37 minutes 0x25 and 1 hour with
+	// rate code 2 is 0x41.
+	require.Equal(t, []byte{
+		0xF1, 0x00, 0xF1, 0x11,
+		0xF1, 0x24, 0xF1, 0x33,
+		0xF1, 0x45, 0xF1, 0x52,
+		0xF1, 0x61, 0xF1, 0x74,
+	}, msgs)
+}
+
+func TestMTCDecoder(t *testing.T) {
+	tests := []struct {
+		name    string
+		start   string
+		fps     string
+		reverse bool
+	}{
+		{"24 forward", "00:59:59:20", "24", false},
+		{"25 forward", "10:00:00:00", "25", false},
+		{"29.97DF forward across a minute", "00:00:59;24", "29.97DF", false},
+		{"30 forward", "23:59:59:26", "30", false},
+		{"25 reverse", "01:00:00:04", "25", true},
+		{"29.97DF reverse across a minute", "00:01:00;06", "29.97DF", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, df, err := ParseFrameRate(tt.fps)
+			require.NoError(t, err)
+			start, err := NewTimecodeFromString(tt.start, rate)
+			require.NoError(t, err)
+			start.DropFrame = df
+
+			const frames = 10
+			stream, err := EncodeMTC(start, frames, tt.reverse)
+			require.NoError(t, err)
+
+			events, invalid := DecodeMTC(stream, FrameRate{})
+			require.Zero(t, invalid)
+			require.Len(t, events, 1+frames/2)
+
+			require.True(t, events[0].FullFrame)
+			require.Equal(t, start.GetTimecode(), events[0].Timecode.GetTimecode())
+
+			expected := *start
+			direction := MTCForward
+			step := 2
+			if tt.reverse {
+				direction = MTCReverse
+				step = -2
+			}
+			for _, event := range events[1:] {
+				require.False(t, event.FullFrame)
+				require.Equal(t, direction, event.Direction)
+				require.Equal(t, expected.GetTimecode(), event.Timecode.GetTimecode())
+				expected.AddFrames(step)
+			}
+		})
+	}
+}
+
+func TestMTCDecoderStream(t *testing.T) {
+	rate := FrameRateFromFloat(25)
+	tc, err := NewTimecodeFromString("01:00:00:00", rate)
+	require.NoError(t, err)
+	msgs, err := MTCQuarterFrames(tc)
+	require.NoError(t, err)
+
+	// Clock, a note with running status and another SysEx around quarter
+	// frames that start on piece 4 and have clock in the middle of one.
+	var stream []byte
+	stream = append(stream, 0xF8, 0x90, 0x3C, 0x64, 0x3E, 0x64)
+	stream = append(stream, 0xF0, 0x7E, 0x7F, 0x06, 0x01, 0xF7)
+	stream = append(stream, msgs[8:]...)
+	stream = append(stream, msgs[:4]...)
+	stream = append(stream, 0xF1, 0xF8)
+	stream = append(stream, msgs[5:]...)
+
+	d := NewMTCDecoder(FrameRate{})
+	var events []*MTCEvent
+	for _, b := range stream {
+		event, err := d.Decode(b)
+		require.NoError(t, err)
+		if event != nil {
+			events = append(events, event)
+		}
+	}
+	require.Len(t, events, 1)
+	require.Equal(t, "01:00:00:00", events[0].Timecode.GetTimecode())
+	require.Equal(t, MTCForward, d.Direction())
+
+	// A missing quarter frame starts the count again.
+	d = NewMTCDecoder(FrameRate{})
+	for i, b := range append(append([]byte{}, msgs[:6]...), msgs[8:]...) {
+		event, err := d.Decode(b)
+		require.NoError(t, err)
+		require.Nil(t, event, "byte %d", i)
+	}
+}
+
+func TestMTCDecoderRate(t *testing.T) {
+	rate := FrameRateFromFloat(24)
+	tc, err := NewTimecodeFromString("01:00:00:00", rate)
+	require.NoError(t, err)
+	msg, err := MTCFullFrame(tc)
+	require.NoError(t, err)
+
+	// 24 can be read as 23.976, but not as 25.
+	back, err := ParseMTCFullFrame(msg, FrameRateFromFloat(23.976))
+	require.NoError(t, err)
+	require.Equal(t, FrameRateFromFloat(23.976), back.FrameRate)
+
+	_, err = ParseMTCFullFrame(msg, FrameRateFromFloat(25))
+	require.ErrorIs(t, err, ErrUnsupportedRate)
+
+	// Frames that are too high for the rate.
+	bad := append([]byte{}, msg...)
+	bad[8] = 24
+	_, err = ParseMTCFullFrame(bad, FrameRate{})
+	require.ErrorIs(t, err, ErrFrameOutOfRange)
+
+	_, err = ParseMTCFullFrame(msg[:9], FrameRate{})
+	require.ErrorIs(t, err, ErrMalformed)
+}
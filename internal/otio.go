@@ -0,0 +1,435 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// RationalTime is an OpenTimelineIO time, a number of frames at a rate. OTIO
+// stores both as floats, so the value doesn't have to be a whole frame and
+// 23.976 is stored as 23.976023976023978.
+type RationalTime struct {
+	Value float64
+	Rate  float64
+}
+
+// NewRationalTimeFromTimecode is the RationalTime of the frame of tc, counting
+// from 00:00:00:00 at the timecode's rate.
+func NewRationalTimeFromTimecode(tc *Timecode) RationalTime {
+	return RationalTime{Value: float64(tc.GetFrameIdx()), Rate: tc.FrameRate.Float64()}
+}
+
+// Add adds u to t, at t's rate. A zero t takes u's rate.
+func (t RationalTime) Add(u RationalTime) RationalTime {
+	if t.Rate == 0 {
+		return u
+	}
+	return RationalTime{Value: t.Value + u.RescaledTo(t.Rate).Value, Rate: t.Rate}
+}
+
+// Sub subtracts u from t, at t's rate.
+func (t RationalTime) Sub(u RationalTime) RationalTime {
+	return t.Add(RationalTime{Value: -u.Value, Rate: u.Rate})
+}
+
+// RescaledTo is the same time at another rate. The value is not rounded, so
+// it can land between frames.
+func (t RationalTime) RescaledTo(rate float64) RationalTime {
+	if t.Rate == rate || t.Rate == 0 {
+		return RationalTime{Value: t.Value, Rate: rate}
+	}
+	return RationalTime{Value: t.Value * rate / t.Rate, Rate: rate}
+}
+
+// Seconds is the real time of t.
+func (t RationalTime) Seconds() float64 {
+	if t.Rate == 0 {
+		return 0
+	}
+	return t.Value / t.Rate
+}
+
+// FrameRate is the exact rate of t, ie 24000/1001 for 23.976023976023978.
+func (t RationalTime) FrameRate() FrameRate {
+	return FrameRateFromFloat(t.Rate)
+}
+
+// RateMatches reports whether t is at rate. NTSC rates match however many
+// decimal places they were written with.
+func (t RationalTime) RateMatches(rate FrameRate) bool {
+	return t.Rate > 0 && t.FrameRate() == rate
+}
+
+// Frames is the nearest frame to t, with halves going up. aligned is false
+// when t is between frames.
+func (t RationalTime) Frames() (frames int64, aligned bool) {
+	rounded := math.Floor(t.Value + 0.5)
+	return int64(rounded), math.Abs(t.Value-rounded) < 1e-6
+}
+
+// String is the value and rate of t, ie "86400@24".
+func (t RationalTime) String() string {
+	return strconv.FormatFloat(t.Value, 'f', -1, 64) + "@" + strconv.FormatFloat(t.Rate, 'f', -1, 64)
+}
+
+// Timecode is the timecode of the nearest frame to t. It rolls over at
+// midnight. t has to be at the timecode's rate; rescale it first when it
+// isn't.
+func (t RationalTime) Timecode(rate FrameRate, dropFrame bool) (*Timecode, error) {
+	if !t.RateMatches(rate) {
+		return nil, NewError(ErrUnsupportedRate, "%s is at %s fps, not %s fps. Rescale it to the timecode's rate first", t, t.FrameRate(), rate)
+	}
+	frames, _ := t.Frames()
+	_, frames = floorDivmod(frames, rate.FramesPerDay(dropFrame))
+	return NewTimecodeFromFrames(frames, rate, dropFrame)
+}
+
+// TimeRange is an OpenTimelineIO range, from StartTime for Duration.
+type TimeRange struct {
+	StartTime RationalTime
+	Duration  RationalTime
+}
+
+// EndTimeExclusive is the time just after the range, at the start time's
+// rate.
+func (r TimeRange) EndTimeExclusive() RationalTime {
+	return r.StartTime.Add(r.Duration)
+}
+
+// RescaledTo is the same range with both times at another rate.
+func (r TimeRange) RescaledTo(rate float64) TimeRange {
+	return TimeRange{StartTime: r.StartTime.RescaledTo(rate), Duration: r.Duration.RescaledTo(rate)}
+}
+
+// Span is the span of the range on the nearest frames at rate. It is nil
+// when that has no frames.
+func (r TimeRange) Span(rate FrameRate, dropFrame bool) (*TimecodeSpan, error) {
+	first, err := r.StartTime.Timecode(rate, dropFrame)
+	if err != nil {
+		return nil, err
+	}
+	inFrames, _ := r.StartTime.Frames()
+	outFrames, _ := r.EndTimeExclusive().Frames()
+	if outFrames <= inFrames {
+		return nil, nil
+	}
+	return NewTimecodeSpanFromOffset(first, outFrames-inFrames-1)
+}
+
+// OTIOTimeline is a parsed OpenTimelineIO timeline.
+type OTIOTimeline struct {
+	Name string
+	// GlobalStartTime is the time of the start of the timeline, ie
+	// 86400@24 for 01:00:00:00. It is nil when the timeline doesn't have one.
+	GlobalStartTime *RationalTime
+	Tracks          []*OTIOTrack
+}
+
+// OTIOTrack is a track of a timeline, with its items in order.
+type OTIOTrack struct {
+	Name string
+	// Kind is "Video" or "Audio".
+	Kind  string
+	Items []*OTIOItem
+}
+
+// OTIOItem is a clip, gap, transition or nested stack in a track.
+type OTIOItem struct {
+	// Kind is the OTIO schema, ie "Clip", "Gap", "Transition" or "Stack".
+	Kind string
+	Name string
+	// SourceRange is the part of the media used: the clip's source_range, or
+	// its media's available_range when it isn't trimmed. It is zero for
+	// transitions.
+	SourceRange TimeRange
+	// RecordRange is where the item is in the track, from the start of the
+	// track. A transition's is the overlap around its cut, from its in offset
+	// before the cut to its out offset after.
+	RecordRange TimeRange
+	// MediaURL is the target_url of a clip's external media reference.
+	MediaURL string
+	// TransitionType is the kind of transition, ie "SMPTE_Dissolve".
+	TransitionType string
+}
+
+type otioRationalTime struct {
+	Value float64 `json:"value"`
+	Rate  float64 `json:"rate"`
+}
+
+type otioTimeRange struct {
+	StartTime otioRationalTime `json:"start_time"`
+	Duration  otioRationalTime `json:"duration"`
+}
+
+// otioNode is any OTIO object. Only the fields this package reads are
+// decoded.
+type otioNode struct {
+	Schema                  string               `json:"OTIO_SCHEMA"`
+	Name                    string               `json:"name"`
+	Kind                    string               `json:"kind"`
+	Children                []*otioNode          `json:"children"`
+	Tracks                  *otioNode            `json:"tracks"`
+	GlobalStartTime         *otioRationalTime    `json:"global_start_time"`
+	SourceRange             *otioTimeRange       `json:"source_range"`
+	AvailableRange          *otioTimeRange       `json:"available_range"`
+	MediaReference          *otioNode            `json:"media_reference"`
+	MediaReferences         map[string]*otioNode `json:"media_references"`
+	ActiveMediaReferenceKey string               `json:"active_media_reference_key"`
+	TargetURL               string               `json:"target_url"`
+	TransitionType          string               `json:"transition_type"`
+	InOffset                *otioRationalTime    `json:"in_offset"`
+	OutOffset               *otioRationalTime    `json:"out_offset"`
+}
+
+// schema is the node's schema without its version, ie "Clip" for "Clip.2".
+func (n *otioNode) schema() string {
+	name, _, _ := strings.Cut(n.Schema, ".")
+	return name
+}
+
+func (n *otioNode) describe() string {
+	if n.Name == "" {
+		return n.schema()
+	}
+	return fmt.Sprintf("%s %q", n.schema(), n.Name)
+}
+
+// mediaReference is the clip's active media reference. OTIO 0.15 and later
+// keep several references by key.
+func (n *otioNode) mediaReference() *otioNode {
+	if len(n.MediaReferences) > 0 {
+		key := n.ActiveMediaReferenceKey
+		if key == "" {
+			key = "DEFAULT_MEDIA"
+		}
+		return n.MediaReferences[key]
+	}
+	return n.MediaReference
+}
+
+func newRationalTime(t otioRationalTime) (RationalTime, error) {
+	if t.Rate <= 0 || math.IsInf(t.Rate, 0) || math.IsNaN(t.Rate) {
+		return RationalTime{}, NewError(ErrUnsupportedRate, "%g is not a valid RationalTime rate", t.Rate)
+	}
+	return RationalTime{Value: t.Value, Rate: t.Rate}, nil
+}
+
+func newTimeRange(r otioTimeRange) (TimeRange, error) {
+	start, err := newRationalTime(r.StartTime)
+	if err != nil {
+		return TimeRange{}, err
+	}
+	duration, err := newRationalTime(r.Duration)
+	if err != nil {
+		return TimeRange{}, err
+	}
+	return TimeRange{StartTime: start, Duration: duration}, nil
+}
+
+// ParseOTIO reads an OpenTimelineIO timeline from .otio JSON. Items are
+// placed one after another in their track, with transitions overlapping the
+// items either side of their cut rather than taking up time.
+func ParseOTIO(r io.Reader) (*OTIOTimeline, error) {
+	var root otioNode
+	if err := json.NewDecoder(r).Decode(&root); err != nil {
+		return nil, NewError(ErrMalformed, "Not OTIO JSON: %s", err)
+	}
+	if root.schema() != "Timeline" {
+		return nil, NewError(ErrMalformed, "Not an OTIO timeline. Expected a Timeline, not %q", root.Schema)
+	}
+
+	timeline := &OTIOTimeline{Name: root.Name}
+	if root.GlobalStartTime != nil {
+		start, err := newRationalTime(*root.GlobalStartTime)
+		if err != nil {
+			return nil, fmt.Errorf("global_start_time: %w", err)
+		}
+		timeline.GlobalStartTime = &start
+	}
+
+	if root.Tracks == nil || root.Tracks.schema() != "Stack" {
+		return nil, NewError(ErrMalformed, "Timeline %q has no tracks. Expected a Stack", root.Name)
+	}
+	for _, child := range root.Tracks.Children {
+		if child.schema() != "Track" {
+			return nil, NewError(ErrMalformed, "Expected a Track in the timeline's stack, not %q", child.Schema)
+		}
+		track, err := parseOTIOTrack(child)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", child.describe(), err)
+		}
+		timeline.Tracks = append(timeline.Tracks, track)
+	}
+
+	return timeline, nil
+}
+
+func parseOTIOTrack(node *otioNode) (*OTIOTrack, error) {
+	track := &OTIOTrack{Name: node.Name, Kind: node.Kind}
+
+	var position RationalTime
+	for _, child := range node.Children {
+		item := &OTIOItem{Kind: child.schema(), Name: child.Name}
+
+		switch item.Kind {
+		case "Transition":
+			if child.InOffset == nil || child.OutOffset == nil {
+				return nil, NewError(ErrMalformed, "%s needs an in_offset and an out_offset", child.describe())
+			}
+			in, err := newRationalTime(*child.InOffset)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", child.describe(), err)
+			}
+			out, err := newRationalTime(*child.OutOffset)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", child.describe(), err)
+			}
+			item.TransitionType = child.TransitionType
+			item.RecordRange = TimeRange{StartTime: position.Sub(in), Duration: in.Add(out)}
+
+		case "Clip", "Gap", "Stack", "Track":
+			duration, err := otioTrimmedRange(child)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", child.describe(), err)
+			}
+			if item.Kind == "Clip" {
+				item.SourceRange = duration
+				if ref := child.mediaReference(); ref != nil {
+					item.MediaURL = ref.TargetURL
+				}
+			}
+			start := position
+			if start.Rate == 0 {
+				start = RationalTime{Rate: duration.Duration.Rate}
+			}
+			item.RecordRange = TimeRange{StartTime: start, Duration: duration.Duration}
+			position = start.Add(duration.Duration)
+
+		default:
+			return nil, NewError(ErrMalformed, "%s is not a Clip, Gap, Transition or Stack", child.describe())
+		}
+
+		track.Items = append(track.Items, item)
+	}
+
+	return track, nil
+}
+
+// otioTrimmedRange is the part of an item that is used: its source_range,
+// or what it has available when it isn't trimmed. That is a clip's media's
+// available_range, the longest track of a stack and all of a track's items.
+func otioTrimmedRange(node *otioNode) (TimeRange, error) {
+	if node.SourceRange != nil {
+		return newTimeRange(*node.SourceRange)
+	}
+
+	switch node.schema() {
+	case "Clip":
+		if ref := node.mediaReference(); ref != nil && ref.AvailableRange != nil {
+			return newTimeRange(*ref.AvailableRange)
+		}
+		return TimeRange{}, NewError(ErrMalformed, "Clip needs a source_range or media with an available_range")
+
+	case "Stack":
+		var longest RationalTime
+		for _, child := range node.Children {
+			r, err := otioTrimmedRange(child)
+			if err != nil {
+				return TimeRange{}, err
+			}
+			if longest.Rate == 0 || r.Duration.Seconds() > longest.Seconds() {
+				longest = r.Duration
+			}
+		}
+		return TimeRange{StartTime: RationalTime{Rate: longest.Rate}, Duration: longest}, nil
+
+	case "Track":
+		var total RationalTime
+		for _, child := range node.Children {
+			if child.schema() == "Transition" {
+				continue
+			}
+			r, err := otioTrimmedRange(child)
+			if err != nil {
+				return TimeRange{}, err
+			}
+			total = total.Add(r.Duration)
+		}
+		return TimeRange{StartTime: RationalTime{Rate: total.Rate}, Duration: total}, nil
+	}
+
+	return TimeRange{}, NewError(ErrMalformed, "%s needs a source_range", node.schema())
+}
+
+// FrameRate is the rate of the timeline: the rate of its global start time,
+// or of its first clip or gap when it doesn't have one.
+func (t *OTIOTimeline) FrameRate() (FrameRate, error) {
+	if t.GlobalStartTime != nil {
+		return timelineFrameRate(*t.GlobalStartTime)
+	}
+	for _, track := range t.Tracks {
+		for _, item := range track.Items {
+			if item.Kind != "Transition" && item.RecordRange.Duration.Rate > 0 {
+				return timelineFrameRate(item.RecordRange.Duration)
+			}
+		}
+	}
+	return FrameRate{}, NewError(ErrUnsupportedRate, "Timeline %q has no frame rate. It needs a global_start_time or a clip", t.Name)
+}
+
+// timelineFrameRate is the frame rate of a time the timeline is counted in.
+// A RationalTime rate can be below 1, but a frame rate can't.
+func timelineFrameRate(t RationalTime) (FrameRate, error) {
+	rate := t.FrameRate()
+	if rate.Num <= 0 {
+		return FrameRate{}, NewError(ErrUnsupportedRate, "%g is not a valid timeline frame rate", t.Rate)
+	}
+	return rate, nil
+}
+
+// RecordRange is where the item is in the timeline, from its global start
+// time.
+func (t *OTIOTimeline) RecordRange(item *OTIOItem) TimeRange {
+	if t.GlobalStartTime == nil {
+		return item.RecordRange
+	}
+	return TimeRange{StartTime: t.GlobalStartTime.Add(item.RecordRange.StartTime), Duration: item.RecordRange.Duration}
+}
+
+// RecordSpan is the span of the item in the timeline at rate. Times at
+// another rate are rescaled to rate first and land on the nearest frame. It
+// is nil when the item has no frames.
+func (t *OTIOTimeline) RecordSpan(item *OTIOItem, rate FrameRate, dropFrame bool) (*TimecodeSpan, error) {
+	return t.RecordRange(item).RescaledTo(rate.Float64()).Span(rate, dropFrame)
+}
+
+// SourceSpan is the span of the media the clip uses, at the media's rate.
+// It is nil when the clip has no frames.
+func (i *OTIOItem) SourceSpan(dropFrame bool) (*TimecodeSpan, error) {
+	r := i.SourceRange.RescaledTo(i.SourceRange.StartTime.Rate)
+	return r.Span(r.StartTime.FrameRate(), dropFrame)
+}
+
+// RateMismatch describes how the item's times disagree with rate, ie a 25
+// fps clip in a 24 fps timeline. It is empty when they all match.
+func (i *OTIOItem) RateMismatch(rate FrameRate) string {
+	var times []RationalTime
+	switch i.Kind {
+	case "Clip":
+		times = []RationalTime{i.SourceRange.StartTime, i.SourceRange.Duration}
+	default:
+		times = []RationalTime{i.RecordRange.Duration}
+	}
+
+	for _, t := range times {
+		if !t.RateMatches(rate) {
+			return fmt.Sprintf("%s %q is at %s fps in a %s fps timeline", i.Kind, i.Name, t.FrameRate(), rate)
+		}
+	}
+	return ""
+}
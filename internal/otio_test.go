@@ -0,0 +1,221 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testOTIO = `{
+    "OTIO_SCHEMA": "Timeline.1",
+    "name": "Reel 1",
+    "global_start_time": {"OTIO_SCHEMA": "RationalTime.1", "rate": 24.0, "value": 86400.0},
+    "tracks": {
+        "OTIO_SCHEMA": "Stack.1",
+        "name": "tracks",
+        "children": [
+            {
+                "OTIO_SCHEMA": "Track.1",
+                "name": "V1",
+                "kind": "Video",
+                "children": [
+                    {
+                        "OTIO_SCHEMA": "Clip.2",
+                        "name": "A001",
+                        "source_range": {
+                            "OTIO_SCHEMA": "TimeRange.1",
+                            "start_time": {"OTIO_SCHEMA": "RationalTime.1", "rate": 24.0, "value": 100.0},
+                            "duration": {"OTIO_SCHEMA": "RationalTime.1", "rate": 24.0, "value": 48.0}
+                        },
+                        "media_references": {
+                            "DEFAULT_MEDIA": {"OTIO_SCHEMA": "ExternalReference.1", "target_url": "file:///media/A001.mov"}
+                        },
+                        "active_media_reference_key": "DEFAULT_MEDIA"
+                    },
+                    {
+                        "OTIO_SCHEMA": "Transition.1",
+                        "name": "Dissolve",
+                        "transition_type": "SMPTE_Dissolve",
+                        "in_offset": {"OTIO_SCHEMA": "RationalTime.1", "rate": 24.0, "value": 12.0},
+                        "out_offset": {"OTIO_SCHEMA": "RationalTime.1", "rate": 24.0, "value": 12.0}
+                    },
+                    {
+                        "OTIO_SCHEMA": "Clip.1",
+                        "name": "A002",
+                        "source_range": null,
+                        "media_reference": {
+                            "OTIO_SCHEMA": "ExternalReference.1",
+                            "target_url": "file:///media/A002.mov",
+                            "available_range": {
+                                "OTIO_SCHEMA": "TimeRange.1",
+                                "start_time": {"OTIO_SCHEMA": "RationalTime.1", "rate": 24.0, "value": 0.0},
+                                "duration": {"OTIO_SCHEMA": "RationalTime.1", "rate": 24.0, "value": 24.0}
+                            }
+                        }
+                    },
+                    {
+                        "OTIO_SCHEMA": "Gap.1",
+                        "name": "",
+                        "source_range": {
+                            "OTIO_SCHEMA": "TimeRange.1",
+                            "start_time": {"OTIO_SCHEMA": "RationalTime.1", "rate": 24.0, "value": 0.0},
+                            "duration": {"OTIO_SCHEMA": "RationalTime.1", "rate": 24.0, "value": 24.0}
+                        }
+                    },
+                    {
+                        "OTIO_SCHEMA": "Clip.2",
+                        "name": "B001",
+                        "source_range": {
+                            "OTIO_SCHEMA": "TimeRange.1",
+                            "start_time": {"OTIO_SCHEMA": "RationalTime.1", "rate": 25.0, "value": 250.0},
+                            "duration": {"OTIO_SCHEMA": "RationalTime.1", "rate": 25.0, "value": 50.0}
+                        }
+                    }
+                ]
+            },
+            {
+                "OTIO_SCHEMA": "Track.1",
+                "name": "A1",
+                "kind": "Audio",
+                "children": [
+                    {
+                        "OTIO_SCHEMA": "Clip.2",
+                        "name": "Music",
+                        "source_range": {
+                            "OTIO_SCHEMA": "TimeRange.1",
+                            "start_time": {"OTIO_SCHEMA": "RationalTime.1", "rate": 24.0, "value": 0.0},
+                            "duration": {"OTIO_SCHEMA": "RationalTime.1", "rate": 24.0, "value": 144.0}
+                        }
+                    }
+                ]
+            }
+        ]
+    }
+}`
+
+func TestRationalTime(t *testing.T) {
+	ntsc := RationalTime{Value: 86313.6, Rate: 23.976023976023978}
+	require.True(t, ntsc.RateMatches(FrameRateFromFloat(23.976)))
+	require.False(t, ntsc.RateMatches(FrameRateFromFloat(24)))
+
+	frames, aligned := ntsc.Frames()
+	require.Equal(t, int64(86314), frames)
+	require.False(t, aligned)
+
+	tc, err := RationalTime{Value: 86400, Rate: 24}.Timecode(FrameRateFromFloat(24), false)
+	require.NoError(t, err)
+	require.Equal(t, "01:00:00:00", tc.GetTimecode())
+
+	_, err = RationalTime{Value: 86400, Rate: 24}.Timecode(FrameRateFromFloat(23.976), false)
+	require.ErrorIs(t, err, ErrUnsupportedRate)
+	require.Equal(t, "86400@24 is at 24 fps, not 23.976 fps. Rescale it to the timecode's rate first", err.Error())
+
+	// A RationalTime counts frames, so drop frame timecode comes back as its
+	// frame count.
+	df, err := NewTimecodeFromString("01:00:00;00", FrameRateFromFloat(29.97))
+	require.NoError(t, err)
+	rt := NewRationalTimeFromTimecode(df)
+	require.Equal(t, RationalTime{Value: 107892, Rate: 30000.0 / 1001}, rt)
+	back, err := rt.Timecode(FrameRateFromFloat(29.97), true)
+	require.NoError(t, err)
+	require.Equal(t, "01:00:00;00", back.GetTimecode())
+
+	require.Equal(t, RationalTime{Value: 48, Rate: 24}, RationalTime{Value: 50, Rate: 25}.RescaledTo(24))
+	require.Equal(t, RationalTime{Value: 60, Rate: 24}, RationalTime{Value: 48, Rate: 24}.Add(RationalTime{Value: 12.5, Rate: 25}))
+}
+
+func TestParseOTIO(t *testing.T) {
+	timeline, err := ParseOTIO(strings.NewReader(testOTIO))
+	require.NoError(t, err)
+	require.Equal(t, "Reel 1", timeline.Name)
+	require.Len(t, timeline.Tracks, 2)
+
+	rate, err := timeline.FrameRate()
+	require.NoError(t, err)
+	require.Equal(t, FrameRateFromFloat(24), rate)
+
+	video := timeline.Tracks[0]
+	require.Equal(t, "Video", video.Kind)
+
+	tests := []struct {
+		kind        string
+		name        string
+		recordFirst string
+		recordLast  string
+		sourceFirst string
+		sourceLast  string
+		mismatch    string
+	}{
+		{"Clip", "A001", "01:00:00:00", "01:00:01:23", "00:00:04:04", "00:00:06:03", ""},
+		{"Transition", "Dissolve", "01:00:01:12", "01:00:02:11", "", "", ""},
+		{"Clip", "A002", "01:00:02:00", "01:00:02:23", "00:00:00:00", "00:00:00:23", ""},
+		{"Gap", "", "01:00:03:00", "01:00:03:23", "", "", ""},
+		{"Clip", "B001", "01:00:04:00", "01:00:05:23", "00:00:10:00", "00:00:11:24", `Clip "B001" is at 25 fps in a 24 fps timeline`},
+	}
+	require.Len(t, video.Items, len(tests))
+
+	for i, tt := range tests {
+		t.Run(tt.kind+" "+tt.name, func(t *testing.T) {
+			item := video.Items[i]
+			require.Equal(t, tt.kind, item.Kind)
+			require.Equal(t, tt.name, item.Name)
+
+			span, err := timeline.RecordSpan(item, rate, false)
+			require.NoError(t, err)
+			require.Equal(t, tt.recordFirst, span.StartTimecode.GetTimecode())
+			require.Equal(t, tt.recordLast, span.LastTimecode.GetTimecode())
+
+			if tt.kind == "Clip" {
+				span, err = item.SourceSpan(false)
+				require.NoError(t, err)
+				require.Equal(t, tt.sourceFirst, span.StartTimecode.GetTimecode())
+				require.Equal(t, tt.sourceLast, span.LastTimecode.GetTimecode())
+			}
+
+			require.Equal(t, tt.mismatch, item.RateMismatch(rate))
+		})
+	}
+
+	require.Equal(t, "file:///media/A001.mov", video.Items[0].MediaURL)
+	require.Equal(t, "file:///media/A002.mov", video.Items[2].MediaURL)
+	require.Equal(t, "SMPTE_Dissolve", video.Items[1].TransitionType)
+
+	music, err := timeline.RecordSpan(timeline.Tracks[1].Items[0], rate, false)
+	require.NoError(t, err)
+	require.Equal(t, 144, music.GetTotalFrames())
+}
+
+func TestParseOTIOErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected error
+		message  string
+	}{
+		{"Not JSON", `<fcpxml/>`, ErrMalformed, ""},
+		{"Not a timeline", `{"OTIO_SCHEMA": "Clip.2", "name": "A001"}`, ErrMalformed, `Not an OTIO timeline. Expected a Timeline, not "Clip.2"`},
+		{"No tracks", `{"OTIO_SCHEMA": "Timeline.1", "name": "Cut"}`, ErrMalformed, `Timeline "Cut" has no tracks. Expected a Stack`},
+		{"Untrimmed clip", `{"OTIO_SCHEMA": "Timeline.1", "tracks": {"OTIO_SCHEMA": "Stack.1", "children": [{"OTIO_SCHEMA": "Track.1", "name": "V1", "children": [{"OTIO_SCHEMA": "Clip.2", "name": "A001"}]}]}}`, ErrMalformed, `Track "V1": Clip "A001": Clip needs a source_range or media with an available_range`},
+		{"Zero rate", `{"OTIO_SCHEMA": "Timeline.1", "global_start_time": {"value": 0, "rate": 0}, "tracks": {"OTIO_SCHEMA": "Stack.1"}}`, ErrUnsupportedRate, "global_start_time: 0 is not a valid RationalTime rate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseOTIO(strings.NewReader(tt.input))
+			require.ErrorIs(t, err, tt.expected)
+			if tt.message != "" {
+				require.Equal(t, tt.message, err.Error())
+			}
+		})
+	}
+}
+
+// A RationalTime can be at a rate below 1, but the timeline needs a frame rate.
+func TestOTIOTimelineFrameRate(t *testing.T) {
+	timeline, err := ParseOTIO(strings.NewReader(`{"OTIO_SCHEMA": "Timeline.1", "global_start_time": {"value": 0, "rate": 0.001}, "tracks": {"OTIO_SCHEMA": "Stack.1"}}`))
+	require.NoError(t, err)
+	_, err = timeline.FrameRate()
+	require.ErrorIs(t, err, ErrUnsupportedRate)
+	require.EqualError(t, err, "0.001 is not a valid timeline frame rate")
+}
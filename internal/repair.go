@@ -0,0 +1,208 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// RepairPolicy decides how an out of range timecode is corrected.
+type RepairPolicy string
+
+const (
+	// RepairCarry carries overflowing fields into the next field up, the same
+	// way GetTimecode does (ie 00:00:10:35 @ 30 becomes 00:00:11:05). Hours
+	// wrap at 24 and illegal drop frame labels move forward to the next label.
+	RepairCarry RepairPolicy = "carry"
+	// RepairSnapForward moves to the next valid timecode (ie 00:00:10:35 @ 30
+	// becomes 00:00:11:00).
+	RepairSnapForward RepairPolicy = "snap-forward"
+	// RepairSnapBack moves to the previous valid timecode (ie 00:00:10:35 @ 30
+	// becomes 00:00:10:29).
+	RepairSnapBack RepairPolicy = "snap-back"
+)
+
+// RepairPolicies lists every policy in the order they are documented.
+var RepairPolicies = []RepairPolicy{
+	RepairCarry,
+	RepairSnapForward,
+	RepairSnapBack,
+}
+
+// RepairIssueKind identifies a problem found with an input timecode.
+type RepairIssueKind string
+
+const (
+	IssueWrongDelimiter   RepairIssueKind = "wrong-delimiter"
+	IssueFrameOverflow    RepairIssueKind = "frame-overflow"
+	IssueSecondsOverflow  RepairIssueKind = "seconds-overflow"
+	IssueMinutesOverflow  RepairIssueKind = "minutes-overflow"
+	IssueHourRollover     RepairIssueKind = "hour-rollover"
+	IssueIllegalDropFrame RepairIssueKind = "illegal-drop-frame"
+)
+
+type RepairIssue struct {
+	Kind    RepairIssueKind
+	Message string
+}
+
+// Repair is the result of RepairTimecode.
+type Repair struct {
+	Input    string
+	Timecode *Timecode
+	Policy   RepairPolicy
+	Issues   []RepairIssue
+}
+
+// looseTimecodeRe accepts anything that looks like a timecode, with out of
+// range fields and any of the commonly used delimiters.
+var looseTimecodeRe = regexp.MustCompile(`^([0-9]{1,3})([:;.,])([0-9]{1,3})([:;.,])([0-9]{1,3})([:;.,])([0-9]{1,4})$`)
+
+// ParseRepairPolicy will match a policy name, ie "snap-back".
+func ParseRepairPolicy(in string) (RepairPolicy, error) {
+	p := RepairPolicy(strings.ToLower(strings.TrimSpace(in)))
+	for _, policy := range RepairPolicies {
+		if p == policy {
+			return policy, nil
+		}
+	}
+	return "", NewError(ErrInvalidOption, "%s is not a valid repair policy", in)
+}
+
+// RepairTimecode will attempt to fix a broken timecode string. It reports each
+// problem it found and returns the corrected timecode. dropFrame forces drop
+// frame counting, otherwise it is taken from the delimiter of the input.
+// An error is only returned when the input doesn't resemble a timecode.
+func RepairTimecode(in string, rate FrameRate, dropFrame bool, policy RepairPolicy) (*Repair, error) {
+	if !rate.valid() {
+		return nil, NewError(ErrUnsupportedRate, "%d/%d is not a valid framerate", rate.Num, rate.Den)
+	}
+	m := looseTimecodeRe.FindStringSubmatch(strings.TrimSpace(in))
+	if m == nil {
+		return nil, NewError(ErrMalformed, "Timecode is malformed. Please format as hh:mm:ss:ff or hh:mm:ss;ff")
+	}
+
+	repair := &Repair{Input: in, Policy: policy}
+	addIssue := func(kind RepairIssueKind, format string, a ...any) {
+		repair.Issues = append(repair.Issues, RepairIssue{Kind: kind, Message: fmt.Sprintf(format, a...)})
+	}
+
+	hours, _ := strconv.Atoi(m[1])
+	mins, _ := strconv.Atoi(m[3])
+	secs, _ := strconv.Atoi(m[5])
+	frames, _ := strconv.Atoi(m[7])
+
+	// Delimiters. Anything other than ":" before the frames is taken as a
+	// request for drop frame, as long as the rate allows it.
+	frameDelim := m[6]
+	if frameDelim != ":" && rate.SupportsDropFrame() {
+		dropFrame = true
+	}
+	if dropFrame && !rate.SupportsDropFrame() {
+		return nil, NewError(ErrInvalidDropFrame, "%s is not a valid framerate for drop frame timecode", rate)
+	}
+	expectedDelim := ":"
+	if dropFrame {
+		expectedDelim = ";"
+	}
+	if m[2] != ":" || m[4] != ":" || frameDelim != expectedDelim {
+		addIssue(IssueWrongDelimiter, "Timecode should be formatted as %s at %s", formatTimecode(0, 0, 0, 0, dropFrame, rate), rate)
+	}
+
+	timeBase := rate.Timebase()
+	lastFrame := timeBase - 1
+
+	// Only the fields that are out of range in the input are reported. A
+	// carry from the field below (ie 00:00:59:35 becoming 00:01:00:05) is
+	// normalized without an issue of its own.
+	if frames > lastFrame {
+		addIssue(IssueFrameOverflow, "Frames cannot be higher than %d", lastFrame)
+	}
+	if secs > 59 {
+		addIssue(IssueSecondsOverflow, "Seconds cannot be higher than 59")
+	}
+	if mins > 59 {
+		addIssue(IssueMinutesOverflow, "Minutes cannot be higher than 59")
+	}
+	if hours > 23 {
+		addIssue(IssueHourRollover, "Hours cannot be higher than 23")
+	}
+
+	if frames > lastFrame {
+		switch policy {
+		case RepairSnapBack:
+			frames = lastFrame
+		case RepairSnapForward:
+			frames = 0
+			secs++
+		default:
+			secs += frames / timeBase
+			frames = frames % timeBase
+		}
+	}
+
+	if secs > 59 {
+		switch policy {
+		case RepairSnapBack:
+			secs = 59
+			frames = lastFrame
+		case RepairSnapForward:
+			secs = 0
+			frames = 0
+			mins++
+		default:
+			mins += secs / 60
+			secs = secs % 60
+		}
+	}
+
+	if mins > 59 {
+		switch policy {
+		case RepairSnapBack:
+			mins = 59
+			secs = 59
+			frames = lastFrame
+		case RepairSnapForward:
+			mins = 0
+			secs = 0
+			frames = 0
+			hours++
+		default:
+			hours += mins / 60
+			mins = mins % 60
+		}
+	}
+
+	if hours > 23 {
+		switch policy {
+		case RepairSnapBack:
+			hours, mins, secs, frames = 23, 59, 59, lastFrame
+		case RepairSnapForward:
+			hours, mins, secs, frames = 0, 0, 0, 0
+		default:
+			hours = hours % 24
+		}
+	}
+
+	dropFrames := rate.DropFramesPerMinute()
+	if dropFrame && secs == 0 && mins%10 != 0 && frames < dropFrames {
+		addIssue(IssueIllegalDropFrame, "%s is not valid drop frame timecode", formatTimecode(int64(hours), int64(mins), int64(secs), int64(frames), true, rate))
+		if policy == RepairSnapBack {
+			// The last frame of the previous minute always exists.
+			mins--
+			secs = 59
+			frames = lastFrame
+		} else {
+			frames = dropFrames
+		}
+	}
+
+	tc, err := NewTimecodeFromString(formatTimecode(int64(hours), int64(mins), int64(secs), int64(frames), dropFrame, rate), rate)
+	if err != nil {
+		return nil, err
+	}
+	repair.Timecode = tc
+
+	return repair, nil
+}
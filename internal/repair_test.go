@@ -0,0 +1,75 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepairTimecode(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		fps       string
+		policy    RepairPolicy
+		expected  string
+		issues    []RepairIssueKind
+		expectErr bool
+
+		forceDropFrame bool
+	}{
+		{"Already valid", "01:00:00:00", "25", RepairCarry, "01:00:00:00", nil, false, false},
+		{"Frame overflow carry", "00:00:10:35", "30", RepairCarry, "00:00:11:05", []RepairIssueKind{IssueFrameOverflow}, false, false},
+		{"Frame overflow snap forward", "00:00:10:35", "30", RepairSnapForward, "00:00:11:00", []RepairIssueKind{IssueFrameOverflow}, false, false},
+		{"Frame overflow snap back", "00:00:10:35", "30", RepairSnapBack, "00:00:10:29", []RepairIssueKind{IssueFrameOverflow}, false, false},
+		{"Carry ripples up", "00:59:59:30", "30", RepairCarry, "01:00:00:00", []RepairIssueKind{IssueFrameOverflow}, false, false},
+		{"Snap forward carries into seconds", "00:00:59:35", "30", RepairSnapForward, "00:01:00:00", []RepairIssueKind{IssueFrameOverflow}, false, false},
+		{"Snap forward carries into minutes", "00:59:59:35", "30", RepairSnapForward, "01:00:00:00", []RepairIssueKind{IssueFrameOverflow}, false, false},
+		{"Seconds and frames overflow", "00:00:75:35", "30", RepairCarry, "00:01:16:05", []RepairIssueKind{IssueFrameOverflow, IssueSecondsOverflow}, false, false},
+		{"Seconds overflow", "00:00:75:00", "24", RepairCarry, "00:01:15:00", []RepairIssueKind{IssueSecondsOverflow}, false, false},
+		{"Minutes overflow snap back", "00:75:00:00", "24", RepairSnapBack, "00:59:59:23", []RepairIssueKind{IssueMinutesOverflow}, false, false},
+		{"Hour rollover carry", "25:00:00:00", "24", RepairCarry, "01:00:00:00", []RepairIssueKind{IssueHourRollover}, false, false},
+		{"Hour rollover snap back", "25:00:00:00", "24", RepairSnapBack, "23:59:59:23", []RepairIssueKind{IssueHourRollover}, false, false},
+		{"Hour rollover snap forward", "25:00:00:00", "24", RepairSnapForward, "00:00:00:00", []RepairIssueKind{IssueHourRollover}, false, false},
+		{"Illegal DF label carry", "00:01:00;00", "29.97", RepairCarry, "00:01:00;02", []RepairIssueKind{IssueIllegalDropFrame}, false, false},
+		{"Illegal DF label snap back", "00:01:00;01", "29.97", RepairSnapBack, "00:00:59;29", []RepairIssueKind{IssueIllegalDropFrame}, false, false},
+		{"Illegal DF label 59.94", "00:02:00;03", "59.94", RepairSnapForward, "00:02:00;04", []RepairIssueKind{IssueIllegalDropFrame}, false, false},
+		{"Tenth minute is legal", "00:10:00;00", "29.97", RepairCarry, "00:10:00;00", nil, false, false},
+		{"Semicolon at NDF rate", "01:00:00;00", "25", RepairCarry, "01:00:00:00", []RepairIssueKind{IssueWrongDelimiter}, false, false},
+		{"Colon at DF rate", "01:00:00:00", "29.97DF", RepairCarry, "01:00:00;00", []RepairIssueKind{IssueWrongDelimiter}, false, false},
+		{"Period means DF", "00:01:00.00", "29.97", RepairCarry, "00:01:00;02", []RepairIssueKind{IssueWrongDelimiter, IssueIllegalDropFrame}, false, false},
+		{"Not a timecode", "one hour", "25", RepairCarry, "", nil, true, false},
+		{"DF at NDF rate", "01:00:00:00", "25", RepairCarry, "", nil, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, df, err := ParseFrameRate(tt.fps)
+			require.NoError(t, err)
+
+			r, err := RepairTimecode(tt.input, rate, df || tt.forceDropFrame, tt.policy)
+			if tt.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, r.Timecode.GetTimecode())
+			require.NoError(t, r.Timecode.Validate())
+
+			var kinds []RepairIssueKind
+			for _, issue := range r.Issues {
+				kinds = append(kinds, issue.Kind)
+			}
+			require.Equal(t, tt.issues, kinds)
+		})
+	}
+}
+
+func TestParseRepairPolicy(t *testing.T) {
+	p, err := ParseRepairPolicy("Snap-Back")
+	require.NoError(t, err)
+	require.Equal(t, RepairSnapBack, p)
+
+	_, err = ParseRepairPolicy("sideways")
+	require.EqualError(t, err, "sideways is not a valid repair policy")
+}
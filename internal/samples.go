@@ -0,0 +1,126 @@
+package internal
+
+import (
+	"math"
+)
+
+// Subframe divisors. Pro Tools counts 100 subframes to a frame, and 80 is the
+// bits of an LTC frame.
+const (
+	Subframes80  = 80
+	Subframes100 = 100
+)
+
+// SetSubframes sets a position inside the frame, in 1/divisor of a frame. It
+// replaces a field or sample offset.
+func (t *Timecode) SetSubframes(subframes, divisor int) error {
+	if divisor != Subframes80 && divisor != Subframes100 {
+		return NewError(ErrInvalidOption, "Subframes are 1/80 or 1/100 of a frame, not 1/%d", divisor)
+	}
+	if subframes < 0 || subframes >= divisor {
+		return NewError(ErrFrameOutOfRange, "Subframes must be from 0 to %d", divisor-1)
+	}
+	t.clearPosition()
+	t._subframes = subframes
+	t._subframeDivisor = divisor
+	return nil
+}
+
+// GetSubframes returns the subframes and what they are a fraction of. The
+// divisor is 0 when the timecode has no subframes.
+func (t *Timecode) GetSubframes() (subframes, divisor int) {
+	return t._subframes, t._subframeDivisor
+}
+
+// SetSampleOffset sets a position inside the frame, in samples after the first
+// sample of the frame at the sample rate. It replaces a field or subframes.
+func (t *Timecode) SetSampleOffset(offset int64, sampleRate int) error {
+	if sampleRate < 1 {
+		return NewError(ErrInvalidOption, "The sample rate must be above 0")
+	}
+	if offset < 0 {
+		return NewError(ErrFrameOutOfRange, "The sample offset cannot be negative")
+	}
+	t.clearPosition()
+	t._sampleOffset = offset
+	t._sampleRate = sampleRate
+	return nil
+}
+
+// GetSampleOffset returns the sample offset and its sample rate. The sample
+// rate is 0 when the timecode has no sample offset.
+func (t *Timecode) GetSampleOffset() (offset int64, sampleRate int) {
+	return t._sampleOffset, t._sampleRate
+}
+
+// clearPosition removes the field, subframes and sample offset, so the
+// timecode is the start of the frame.
+func (t *Timecode) clearPosition() {
+	t._field, t._hasField = 0, false
+	t._subframes, t._subframeDivisor = 0, 0
+	t._sampleOffset, t._sampleRate = 0, 0
+}
+
+// GetSampleIdx returns the audio sample the timecode is at, where sample 0 is
+// 00:00:00:00. A field, subframes or sample offset move it into the frame.
+func (t *Timecode) GetSampleIdx(sampleRate int) int64 {
+	return t.samplesAt(int64(t.GetFrameIdx()), sampleRate)
+}
+
+// samplesAt is GetSampleIdx with the timecode's position inside the frame
+// moved to another frame.
+func (t *Timecode) samplesAt(frameIdx int64, sampleRate int) int64 {
+	if t._sampleRate != 0 {
+		samples := t.FrameRate.FramesToSamples(frameIdx, t._sampleRate) + t._sampleOffset
+		return ceilDiv(samples*int64(sampleRate), int64(t._sampleRate))
+	}
+	// The position is frameIdx + n/d frames.
+	n, d := t.framePosition()
+	return ceilDiv((frameIdx*d+n)*int64(sampleRate)*t.FrameRate.Den, t.FrameRate.Num*d)
+}
+
+// framePosition is how far into the frame a field or subframes are, as a
+// fraction of a frame.
+func (t *Timecode) framePosition() (n, d int64) {
+	switch {
+	case t._hasField:
+		return int64(t._field), 2
+	case t._subframeDivisor != 0:
+		return int64(t._subframes), int64(t._subframeDivisor)
+	}
+	return 0, 1
+}
+
+// GetSeconds returns the real time of the timecode from 00:00:00:00, including
+// its position inside the frame.
+func (t *Timecode) GetSeconds() float64 {
+	idx := int64(t.GetFrameIdx())
+	if t._sampleRate != 0 {
+		return float64(t.FrameRate.FramesToSamples(idx, t._sampleRate)+t._sampleOffset) / float64(t._sampleRate)
+	}
+	n, d := t.framePosition()
+	return float64((idx*d+n)*t.FrameRate.Den) / float64(t.FrameRate.Num*d)
+}
+
+// NewTimecodeFromSamples will create a Timecode for an audio sample, where
+// sample 0 is 00:00:00:00. The samples after the start of the frame are kept
+// as its sample offset.
+func NewTimecodeFromSamples(sampleIdx int64, sampleRate int, frameRate FrameRate, isDropframe bool) (*Timecode, error) {
+	if sampleRate < 1 {
+		return nil, NewError(ErrInvalidOption, "The sample rate must be above 0")
+	}
+	frames, offset := frameRate.SamplesToFrames(sampleIdx, sampleRate)
+	t, err := NewTimecodeFromFrames(frames, frameRate, isDropframe)
+	if err != nil {
+		return nil, err
+	}
+	t._sampleOffset = offset
+	t._sampleRate = sampleRate
+	return t, nil
+}
+
+// NewTimecodeFromSeconds will create a Timecode for a real time from
+// 00:00:00:00, accurate to the nearest sample at the sample rate.
+func NewTimecodeFromSeconds(seconds float64, sampleRate int, frameRate FrameRate, isDropframe bool) (*Timecode, error) {
+	return NewTimecodeFromSamples(int64(math.Round(seconds*float64(sampleRate))), sampleRate, frameRate, isDropframe)
+}
@@ -0,0 +1,91 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFramesToSamples(t *testing.T) {
+	tests := []struct {
+		fps        float64
+		sampleRate int
+		frames     int64
+		expected   int64
+	}{
+		{25, 48000, 1, 1920},
+		{24, 96000, 24, 96000},
+		// 1601.6 samples a frame, so the first sample of frame 1 is 1602.
+		{29.97, 48000, 1, 1602},
+		{29.97, 48000, 5, 8008},
+		{29.97, 48000, -1, -1601},
+		{23.976, 48000, 24, 48048},
+	}
+
+	for _, tt := range tests {
+		rate := FrameRateFromFloat(tt.fps)
+		samples := rate.FramesToSamples(tt.frames, tt.sampleRate)
+		require.Equal(t, tt.expected, samples, "%v fps frame %d", tt.fps, tt.frames)
+
+		frames, offset := rate.SamplesToFrames(samples, tt.sampleRate)
+		require.Equal(t, tt.frames, frames)
+		require.Zero(t, offset)
+		frames, offset = rate.SamplesToFrames(samples-1, tt.sampleRate)
+		require.Equal(t, tt.frames-1, frames)
+		require.Positive(t, offset)
+	}
+}
+
+func TestSubframes(t *testing.T) {
+	rate := FrameRateFromFloat(25)
+	tc, err := NewTimecodeFromString("01:00:00:00.50", rate)
+	require.NoError(t, err)
+	require.NoError(t, tc.Validate())
+	require.Equal(t, "01:00:00:00.50", tc.GetTimecode())
+	subframes, divisor := tc.GetSubframes()
+	require.Equal(t, 50, subframes)
+	require.Equal(t, Subframes100, divisor)
+	require.Equal(t, int64(3600*48000+960), tc.GetSampleIdx(48000))
+	require.InDelta(t, 3600.02, tc.GetSeconds(), 1e-9)
+
+	require.NoError(t, tc.SetSubframes(40, Subframes80))
+	require.Equal(t, "01:00:00:00.40", tc.GetTimecode())
+	require.Equal(t, int64(3600*48000+960), tc.GetSampleIdx(48000))
+
+	require.ErrorIs(t, tc.SetSubframes(80, Subframes80), ErrFrameOutOfRange)
+	require.ErrorIs(t, tc.SetSubframes(0, 60), ErrInvalidOption)
+
+	// A field replaces the subframes.
+	require.NoError(t, tc.SetField(1))
+	require.Equal(t, "01:00:00:00.1", tc.GetTimecode())
+	_, divisor = tc.GetSubframes()
+	require.Zero(t, divisor)
+}
+
+func TestSampleOffsets(t *testing.T) {
+	rate := FrameRateFromFloat(29.97)
+	tc, err := NewTimecodeFromSamples(48000*60+100, 48000, rate, true)
+	require.NoError(t, err)
+	require.NoError(t, tc.Validate())
+	// 2880100 samples is frame 1798 (00:01:00;00 is 1800 after the 2
+	// dropped labels), which starts at sample 2879677.
+	require.Equal(t, "00:00:59;28", tc.GetTimecode())
+	offset, sampleRate := tc.GetSampleOffset()
+	require.Equal(t, int64(423), offset)
+	require.Equal(t, 48000, sampleRate)
+	require.Equal(t, int64(48000*60+100), tc.GetSampleIdx(48000))
+	require.Equal(t, int64(96000*60+200), tc.GetSampleIdx(96000))
+	require.InDelta(t, 60+100.0/48000, tc.GetSeconds(), 1e-9)
+
+	back, err := NewTimecodeFromSeconds(tc.GetSeconds(), 96000, rate, true)
+	require.NoError(t, err)
+	require.Equal(t, tc.GetTimecode(), back.GetTimecode())
+	offset, _ = back.GetSampleOffset()
+	require.Equal(t, int64(846), offset)
+
+	require.NoError(t, tc.SetSampleOffset(1602, 48000))
+	require.ErrorIs(t, tc.Validate(), ErrFrameOutOfRange)
+
+	_, err = NewTimecodeFromSamples(0, 0, rate, false)
+	require.ErrorIs(t, err, ErrInvalidOption)
+}
@@ -0,0 +1,287 @@
+package internal
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMPTEFlags are the flags carried alongside a SMPTE 12M time address. Drop
+// frame is a flag too, but it is the Timecode's DropFrame.
+type SMPTEFlags struct {
+	ColorFrame bool
+	// Field is the field mark of VITC. LTC uses the same bit for its
+	// polarity correction.
+	Field bool
+	// BinaryGroupFlags are BGF0 to BGF2, with BGF0 in the lowest bit. BGF0
+	// and BGF2 say how the user bits are used (see UserBitsFormat), and
+	// BGF1 is the clock flag.
+	BinaryGroupFlags uint8
+}
+
+const (
+	// BGF0 to BGF2 are the binary group flag bits of SMPTEFlags.BinaryGroupFlags.
+	BGF0 uint8 = 1 << iota
+	BGF1
+	BGF2
+)
+
+// UserBitsFormat is how the user bits are used, which is set by BGF0 and BGF2.
+type UserBitsFormat uint8
+
+const (
+	// UserBitsUnspecified is user bits that are whatever the user wants, ie a reel ID.
+	UserBitsUnspecified UserBitsFormat = 0
+	// UserBitsCharacters is 8 bit characters, 4 to a frame.
+	UserBitsCharacters UserBitsFormat = UserBitsFormat(BGF0)
+	// UserBitsDate is a date and time zone.
+	UserBitsDate UserBitsFormat = UserBitsFormat(BGF2)
+	// UserBitsPageLine is the page/line multiplex system.
+	UserBitsPageLine UserBitsFormat = UserBitsFormat(BGF0 | BGF2)
+)
+
+// UserBitsFormat returns how the user bits are used.
+func (f SMPTEFlags) UserBitsFormat() UserBitsFormat {
+	return UserBitsFormat(f.BinaryGroupFlags & (BGF0 | BGF2))
+}
+
+// SetUserBitsFormat sets BGF0 and BGF2, leaving the clock flag (BGF1) alone.
+func (f *SMPTEFlags) SetUserBitsFormat(format UserBitsFormat) {
+	f.BinaryGroupFlags = f.BinaryGroupFlags&BGF1 | uint8(format)&(BGF0|BGF2)
+}
+
+// smpteFlagBits are the bits of the time address for the field bit and for
+// BGF0 to BGF2. 25 fps moves them around.
+func smpteFlagBits(rate FrameRate) (field int, bgf [3]int) {
+	if rate.Timebase() == 25 {
+		return 31, [3]int{15, 30, 23}
+	}
+	return 15, [3]int{23, 30, 31}
+}
+
+func checkSMPTERate(rate FrameRate) error {
+	if rate.Timebase() > 30 {
+		return NewError(ErrUnsupportedRate, "SMPTE 12M timecode carries up to 30 frames a second, so %s cannot be used", rate)
+	}
+	return nil
+}
+
+// PackSMPTE packs the timecode into the 32 bit SMPTE 12M time address, the
+// packed BCD word carried by LTC, VITC, AES3, SDI and written into MXF and DPX
+// headers. Written as hex, a timecode without flags reads as HHMMSSFF.
+func (t *Timecode) PackSMPTE(flags SMPTEFlags) (uint32, error) {
+	if err := checkSMPTERate(t.FrameRate); err != nil {
+		return 0, err
+	}
+	if err := t.Validate(); err != nil {
+		return 0, err
+	}
+	hours, minutes, seconds, frames := t.GetComponents()
+
+	word := uint32(frames%10) |
+		uint32(frames/10)<<4 |
+		uint32(seconds%10)<<8 |
+		uint32(seconds/10)<<12 |
+		uint32(minutes%10)<<16 |
+		uint32(minutes/10)<<20 |
+		uint32(hours%10)<<24 |
+		uint32(hours/10)<<28
+
+	setBit := func(bit int, v bool) {
+		if v {
+			word |= 1 << bit
+		}
+	}
+	setBit(6, t.DropFrame)
+	setBit(7, flags.ColorFrame)
+	field, bgf := smpteFlagBits(t.FrameRate)
+	setBit(field, flags.Field)
+	for i, bit := range bgf {
+		setBit(bit, flags.BinaryGroupFlags&(1<<i) != 0)
+	}
+	return word, nil
+}
+
+// UnpackSMPTE reads a 32 bit SMPTE 12M time address. The time address doesn't
+// say what the frame rate is, so it has to be given. Drop frame comes from the
+// time address.
+func UnpackSMPTE(word uint32, rate FrameRate) (*Timecode, SMPTEFlags, error) {
+	var flags SMPTEFlags
+	if err := checkSMPTERate(rate); err != nil {
+		return nil, flags, err
+	}
+
+	digit := func(shift int, bits int) int {
+		return int(word>>shift) & (1<<bits - 1)
+	}
+	units := []struct {
+		name  string
+		shift int
+	}{{"frames", 0}, {"seconds", 8}, {"minutes", 16}, {"hours", 24}}
+	for _, u := range units {
+		if v := digit(u.shift, 4); v > 9 {
+			return nil, flags, NewError(ErrMalformed, "The %s units are not a BCD digit (%d)", u.name, v)
+		}
+	}
+
+	frames := int64(digit(4, 2)*10 + digit(0, 4))
+	seconds := int64(digit(12, 3)*10 + digit(8, 4))
+	minutes := int64(digit(20, 3)*10 + digit(16, 4))
+	hours := int64(digit(28, 2)*10 + digit(24, 4))
+	bit := func(i int) bool { return word&(1<<i) != 0 }
+
+	tc, err := NewTimecodeFromString(formatTimecode(hours, minutes, seconds, frames, bit(6), rate), rate)
+	if err != nil {
+		return nil, flags, err
+	}
+	if err := tc.Validate(); err != nil {
+		return nil, flags, err
+	}
+
+	flags.ColorFrame = bit(7)
+	field, bgf := smpteFlagBits(rate)
+	flags.Field = bit(field)
+	for i, b := range bgf {
+		if bit(b) {
+			flags.BinaryGroupFlags |= 1 << i
+		}
+	}
+	return tc, flags, nil
+}
+
+// MarshalBinary packs the timecode into a big endian SMPTE 12M time address
+// without flags, other than drop frame.
+func (t *Timecode) MarshalBinary() ([]byte, error) {
+	word, err := t.PackSMPTE(SMPTEFlags{})
+	if err != nil {
+		return nil, err
+	}
+	return binary.BigEndian.AppendUint32(nil, word), nil
+}
+
+// UnmarshalBinary reads a big endian SMPTE 12M time address. The frame rate
+// isn't in the time address, so FrameRate has to be set first. The flags,
+// other than drop frame, are ignored.
+func (t *Timecode) UnmarshalBinary(data []byte) error {
+	if len(data) != 4 {
+		return NewError(ErrMalformed, "A SMPTE time address is 4 bytes, not %d", len(data))
+	}
+	if t.FrameRate.Num == 0 {
+		return NewError(ErrUnsupportedRate, "The frame rate has to be set before a SMPTE time address is read")
+	}
+	tc, _, err := UnpackSMPTE(binary.BigEndian.Uint32(data), t.FrameRate)
+	if err != nil {
+		return err
+	}
+	*t = *tc
+	return nil
+}
+
+// UserBits are the 32 user bits of SMPTE 12M timecode, as 8 groups of 4 bits
+// with group 1 in the lowest 4 bits.
+type UserBits uint32
+
+// Group returns user bit group n, from 1 to 8.
+func (u UserBits) Group(n int) int {
+	return int(u>>(4*(n-1))) & 0xF
+}
+
+// String formats the user bits as 8 hex digits, group 8 first.
+func (u UserBits) String() string {
+	return fmt.Sprintf("%08X", uint32(u))
+}
+
+// ParseUserBits parses up to 8 hex digits, group 8 first. Spaces are ignored,
+// so "12 34 56 78" works too.
+func ParseUserBits(in string) (UserBits, error) {
+	s := strings.ReplaceAll(strings.TrimSpace(in), " ", "")
+	if len(s) == 0 || len(s) > 8 {
+		return 0, NewError(ErrMalformed, "User bits must be 1 to 8 hex digits, ie 0A1B2C3D")
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return 0, NewError(ErrMalformed, "User bits must be 1 to 8 hex digits, ie 0A1B2C3D")
+	}
+	return UserBits(v), nil
+}
+
+// NewUserBitsFromReelID stores a reel ID of up to 8 hex digits (ie "A001")
+// in the user bits, which is how tape decks label the reel. Use the
+// UserBitsUnspecified format.
+func NewUserBitsFromReelID(reel string) (UserBits, error) {
+	u, err := ParseUserBits(reel)
+	if err != nil {
+		return 0, NewError(ErrMalformed, "%s is not a reel ID. Reel IDs in user bits are 1 to 8 hex digits", reel)
+	}
+	return u, nil
+}
+
+// ReelID returns the user bits as a reel ID, without the leading zeros.
+func (u UserBits) ReelID() string {
+	return strings.ToUpper(strconv.FormatUint(uint64(u), 16))
+}
+
+// NewUserBitsFromDate stores a date in the user bits as BCD: groups 1 and 2
+// are the day, 3 and 4 the month and 5 and 6 the two digit year, units
+// first. Groups 7 and 8 are the time zone, which is left at 0 (UTC). Use the
+// UserBitsDate format.
+func NewUserBitsFromDate(date time.Time) UserBits {
+	year, month, day := date.Date()
+	yy := year % 100
+	return UserBits(day%10 | day/10<<4 |
+		int(month)%10<<8 | int(month)/10<<12 |
+		yy%10<<16 | yy/10<<20)
+}
+
+// Date reads a date stored by NewUserBitsFromDate. Two digit years from 69
+// are the 1900s, the same as time.Parse.
+func (u UserBits) Date() (time.Time, error) {
+	for n := 1; n <= 6; n++ {
+		if u.Group(n) > 9 {
+			return time.Time{}, NewError(ErrMalformed, "User bits %s are not a BCD date", u)
+		}
+	}
+	day := u.Group(2)*10 + u.Group(1)
+	month := u.Group(4)*10 + u.Group(3)
+	year := u.Group(6)*10 + u.Group(5)
+	if year >= 69 {
+		year += 1900
+	} else {
+		year += 2000
+	}
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if date.Day() != day || int(date.Month()) != month {
+		return time.Time{}, NewError(ErrMalformed, "User bits %s are not a valid date", u)
+	}
+	return date, nil
+}
+
+// NewUserBitsFromASCII stores ASCII text in the user bits, 4 characters to a
+// frame with the first character in groups 1 and 2. 8 characters, ie a tape
+// name, take two frames. The last frame is padded with spaces. Use the
+// UserBitsCharacters format.
+func NewUserBitsFromASCII(text string) ([]UserBits, error) {
+	for i := 0; i < len(text); i++ {
+		if text[i] < 0x20 || text[i] > 0x7E {
+			return nil, NewError(ErrMalformed, "User bits can only carry printable ASCII, %q is not", text[i])
+		}
+	}
+	var words []UserBits
+	for i := 0; i < len(text); i += 4 {
+		chunk := []byte(fmt.Sprintf("%-4s", text[i:min(i+4, len(text))]))
+		words = append(words, UserBits(binary.LittleEndian.Uint32(chunk)))
+	}
+	return words, nil
+}
+
+// ASCIIFromUserBits reads the characters stored by NewUserBitsFromASCII, without the
+// trailing spaces.
+func ASCIIFromUserBits(words []UserBits) string {
+	var text []byte
+	for _, u := range words {
+		text = binary.LittleEndian.AppendUint32(text, uint32(u))
+	}
+	return strings.TrimRight(string(text), " \x00")
+}
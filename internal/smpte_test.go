@@ -0,0 +1,167 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackSMPTE(t *testing.T) {
+	tests := []struct {
+		name     string
+		timecode string
+		fps      string
+		flags    SMPTEFlags
+		expected uint32
+	}{
+		{"25", "01:00:00:00", "25", SMPTEFlags{}, 0x01000000},
+		{"30 last frame of the day", "23:59:59:29", "30", SMPTEFlags{}, 0x23595929},
+		{"Drop frame", "00:10:00;00", "29.97", SMPTEFlags{}, 0x00100040},
+		{"Color frame", "10:20:30:12", "24", SMPTEFlags{ColorFrame: true}, 0x10203092},
+		{"Field at 30", "00:00:00:00", "30", SMPTEFlags{Field: true}, 0x00008000},
+		{"Field at 25", "00:00:00:00", "25", SMPTEFlags{Field: true}, 0x80000000},
+		{"BGF at 30", "00:00:00:00", "29.97", SMPTEFlags{BinaryGroupFlags: BGF0 | BGF1 | BGF2}, 0xC0800000},
+		{"BGF0 at 25", "00:00:00:00", "25", SMPTEFlags{BinaryGroupFlags: BGF0}, 0x00008000},
+		{"BGF2 at 25", "00:00:00:00", "25", SMPTEFlags{BinaryGroupFlags: BGF2}, 0x00800000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, _, err := ParseFrameRate(tt.fps)
+			require.NoError(t, err)
+			tc, err := NewTimecodeFromString(tt.timecode, rate)
+			require.NoError(t, err)
+
+			word, err := tc.PackSMPTE(tt.flags)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, word, "%08X", word)
+
+			back, flags, err := UnpackSMPTE(word, rate)
+			require.NoError(t, err)
+			require.Equal(t, tt.timecode, back.GetTimecode())
+			require.Equal(t, tt.flags, flags)
+		})
+	}
+}
+
+func TestUnpackSMPTEErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		word      uint32
+		fps       string
+		expectErr error
+	}{
+		{"Frames units not BCD", 0x0000000A, "25", ErrMalformed},
+		{"Hours units not BCD", 0x0F000000, "25", ErrMalformed},
+		{"Frames too high", 0x00000025, "25", ErrFrameOutOfRange},
+		{"Hours too high", 0x24000000, "25", ErrFrameOutOfRange},
+		{"Dropped frame", 0x00010040, "29.97", ErrInvalidDropFrame},
+		{"Drop frame at 25", 0x00000040, "25", ErrInvalidDropFrame},
+		{"Above 30 fps", 0x00000000, "50", ErrUnsupportedRate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, _, err := ParseFrameRate(tt.fps)
+			require.NoError(t, err)
+			_, _, err = UnpackSMPTE(tt.word, rate)
+			require.ErrorIs(t, err, tt.expectErr)
+		})
+	}
+}
+
+func TestTimecodeBinaryMarshal(t *testing.T) {
+	rate := FrameRateFromFloat(29.97)
+	tc, err := NewTimecodeFromString("01:02:03;04", rate)
+	require.NoError(t, err)
+
+	data, err := tc.MarshalBinary()
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x01, 0x02, 0x03, 0x44}, data)
+
+	back := &Timecode{FrameRate: rate}
+	require.NoError(t, back.UnmarshalBinary(data))
+	require.Equal(t, "01:02:03;04", back.GetTimecode())
+	require.True(t, back.DropFrame)
+
+	require.ErrorIs(t, (&Timecode{}).UnmarshalBinary(data), ErrUnsupportedRate)
+	require.ErrorIs(t, back.UnmarshalBinary(data[:3]), ErrMalformed)
+}
+
+func TestUserBitsFormat(t *testing.T) {
+	flags := SMPTEFlags{BinaryGroupFlags: BGF1}
+	require.Equal(t, UserBitsUnspecified, flags.UserBitsFormat())
+
+	flags.SetUserBitsFormat(UserBitsDate)
+	require.Equal(t, UserBitsDate, flags.UserBitsFormat())
+	require.Equal(t, BGF1|BGF2, flags.BinaryGroupFlags, "the clock flag should be kept")
+
+	flags.SetUserBitsFormat(UserBitsCharacters)
+	require.Equal(t, UserBitsCharacters, flags.UserBitsFormat())
+}
+
+func TestUserBits(t *testing.T) {
+	u, err := ParseUserBits("12 34 56 78")
+	require.NoError(t, err)
+	require.Equal(t, UserBits(0x12345678), u)
+	require.Equal(t, 8, u.Group(1))
+	require.Equal(t, 1, u.Group(8))
+	require.Equal(t, "12345678", u.String())
+
+	_, err = ParseUserBits("123456789")
+	require.ErrorIs(t, err, ErrMalformed)
+	_, err = ParseUserBits("XYZ")
+	require.ErrorIs(t, err, ErrMalformed)
+
+	reel, err := NewUserBitsFromReelID("a001")
+	require.NoError(t, err)
+	require.Equal(t, UserBits(0xA001), reel)
+	require.Equal(t, "A001", reel.ReelID())
+	_, err = NewUserBitsFromReelID("REEL1")
+	require.ErrorIs(t, err, ErrMalformed)
+}
+
+func TestUserBitsDate(t *testing.T) {
+	tests := []struct {
+		date     time.Time
+		expected UserBits
+	}{
+		{time.Date(2024, time.December, 31, 0, 0, 0, 0, time.UTC), 0x00241231},
+		{time.Date(1999, time.January, 5, 0, 0, 0, 0, time.UTC), 0x00990105},
+		{time.Date(2068, time.February, 29, 0, 0, 0, 0, time.UTC), 0x00680229},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.date.Format("2006-01-02"), func(t *testing.T) {
+			u := NewUserBitsFromDate(tt.date)
+			require.Equal(t, tt.expected, u, u.String())
+			date, err := u.Date()
+			require.NoError(t, err)
+			require.Equal(t, tt.date, date)
+		})
+	}
+
+	_, err := UserBits(0x00241331).Date()
+	require.ErrorIs(t, err, ErrMalformed)
+	_, err = UserBits(0x0024023A).Date()
+	require.ErrorIs(t, err, ErrMalformed)
+}
+
+func TestUserBitsASCII(t *testing.T) {
+	words, err := NewUserBitsFromASCII("TAPE0042")
+	require.NoError(t, err)
+	require.Len(t, words, 2)
+	// T is 0x54, in groups 1 and 2.
+	require.Equal(t, 4, words[0].Group(1))
+	require.Equal(t, 5, words[0].Group(2))
+	require.Equal(t, "TAPE0042", ASCIIFromUserBits(words))
+
+	words, err = NewUserBitsFromASCII("A1")
+	require.NoError(t, err)
+	require.Len(t, words, 1)
+	require.Equal(t, "A1", ASCIIFromUserBits(words))
+
+	_, err = NewUserBitsFromASCII("tab\t")
+	require.ErrorIs(t, err, ErrMalformed)
+}
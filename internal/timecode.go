@@ -1,32 +1,46 @@
 package internal
 
 import (
-	"fmt"
-	"math"
 	"regexp"
 	"strconv"
 	"strings"
 )
 
 type Timecode struct {
-	FrameRate float64
+	FrameRate FrameRate
 	DropFrame bool
 	_hours    int
 	_mins     int
 	_secs     int
 	_frames   int
 	_timecode string
+	// _field is 0 for the first field and 1 for the second, when _hasField
+	// is set. Without a field indicator the timecode is the whole frame.
+	_field    int
+	_hasField bool
+	// _subframes are in 1/_subframeDivisor of a frame. A divisor of 0 is
+	// no subframes.
+	_subframes       int
+	_subframeDivisor int
+	// _sampleOffset is in samples at _sampleRate after the first sample of
+	// the frame. A sample rate of 0 is no sample offset.
+	_sampleOffset int64
+	_sampleRate   int
 }
 
 // NewTimecodeFromFrames will create a Timecode object for given frames.
 // The only time it will return an error is if DF is specified for a non-DF framerate.
-func NewTimecodeFromFrames(inputFrameIdx int64, frameRate float64, isDropframe bool) (*Timecode, error) {
+func NewTimecodeFromFrames(inputFrameIdx int64, frameRate FrameRate, isDropframe bool) (*Timecode, error) {
+
+	if isDropframe && !frameRate.SupportsDropFrame() {
+		return nil, NewError(ErrInvalidDropFrame, "%s is not a valid framerate for drop frame timecode", frameRate)
+	}
 
 	if isDropframe {
 		//CONVERT A FRAME NUMBER TO DROP FRAME TIMECODE
 		//Code by David Heidelberger, adapted from Andrew Duncan
 		//Given an int called framenumber and a double called framerate
-		//Framerate should be 29.97 or 59.94, otherwise the calculations will be off.
+		//Framerate should be 29.97, 59.94 or 119.88, otherwise the calculations will be off.
 
 		// adapted from https://www.davidheidelberger.com/2010/06/10/drop-frame-timecode/
 
@@ -38,11 +52,12 @@ func NewTimecodeFromFrames(inputFrameIdx int64, frameRate float64, isDropframe b
 		var d int
 		var m int
 
-		dropFrames := int(math.Round(frameRate * 0.066666))               //Number of frames to drop on the minute marks is the nearest integer to 6% of the framerate
-		framesPerHour := int(math.Round(frameRate * 60 * 60))             //Number of frames in an hour
-		framesPer24Hours := framesPerHour * 24                            //Number of frames in a day - timecode rolls over after 24 hours
-		framesPer10Minutes := int(math.Round(frameRate * 60 * 10))        //Number of frames per ten minutes
-		framesPerMinute := (int(math.Round(frameRate)) * 60) - dropFrames //Number of frames per minute is the round of the framerate * 60 minus the number of dropped frames
+		timeBase := frameRate.Timebase()
+		dropFrames := frameRate.DropFramesPerMinute()             //Number of frames to drop on the minute marks
+		framesPerMinute := (timeBase * 60) - dropFrames           //Number of frames per minute is the timebase * 60 minus the number of dropped frames
+		framesPer10Minutes := (timeBase * 60 * 10) - dropFrames*9 //Number of frames per ten minutes. The tenth minute doesn't drop.
+		framesPerHour := framesPer10Minutes * 6                   //Number of frames in an hour
+		framesPer24Hours := framesPerHour * 24                    //Number of frames in a day - timecode rolls over after 24 hours
 
 		//In some languages, a % operation will work here
 		//But since % for negative numbers varies by language, we'll do it manually
@@ -64,62 +79,79 @@ func NewTimecodeFromFrames(inputFrameIdx int64, frameRate float64, isDropframe b
 			framenumber = framenumber + dropFrames*9*d
 		}
 
-		frRound := int(math.Round(frameRate))
+		frRound := timeBase
 		frames := framenumber % frRound
 		seconds := (framenumber / frRound) % 60
 		minutes := ((framenumber / frRound) / 60) % 60
 		hours := (((framenumber / frRound) / 60) / 60)
-		tc_string := formatTimecode(int64(hours), int64(minutes), int64(seconds), int64(frames), true)
+		tc_string := formatTimecode(int64(hours), int64(minutes), int64(seconds), int64(frames), true, frameRate)
 
-		// Fix this deref and deal with the error
 		return NewTimecodeFromString(tc_string, frameRate)
 
 	} else {
 
-		sr, frames := divmod(inputFrameIdx, int64(getTimeBase(frameRate)))
+		sr, frames := divmod(inputFrameIdx, int64(frameRate.Timebase()))
 		mr, seconds := divmod(sr, 60)
 		hr, minutes := divmod(mr, 60)
 		//_, _ := divmod(hr, 24)
-		tc_string := formatTimecode(hr, minutes, seconds, frames, isDropframe)
+		tc_string := formatTimecode(hr, minutes, seconds, frames, isDropframe, frameRate)
 
-		// Fix this deref and deal with the error
 		return NewTimecodeFromString(tc_string, frameRate)
 	}
 
 }
 
-func NewTimecodeFromString(inputTimecode string, frameRate float64) (*Timecode, error) {
+// timecodeRe matches hh:mm:ss:ff or hh:mm:ss;ff. The frames field can be
+// three digits for rates above 100 fps. Interlaced timecode can end with a
+// field indicator, ".0" or ".1", or "*" for the second field. Two digits
+// after the "." are subframes, in 1/100 of a frame as Pro Tools shows them.
+var timecodeRe = regexp.MustCompile(`^([0-9]{2}):([0-5][0-9]):([0-5][0-9])[;:]([0-9]{2,3})(\.[01]|\*|\.[0-9]{2})?$`)
 
-	_timecode := inputTimecode
+func NewTimecodeFromString(inputTimecode string, frameRate FrameRate) (*Timecode, error) {
 
-	re := regexp.MustCompile(`^([0-9]{2}):([0-5][0-9]):([0-5][0-9])[;:]([0-9]{2})$`)
+	_timecode := inputTimecode
 
-	if !re.MatchString(inputTimecode) {
-		return nil, fmt.Errorf("Timecode is malformed. Please format as hh:mm:ss:ff or hh:mm:ss;ff")
+	if !timecodeRe.MatchString(inputTimecode) {
+		return nil, NewError(ErrMalformed, "Timecode is malformed. Please format as hh:mm:ss:ff or hh:mm:ss;ff")
 	}
 
 	dropFrame := strings.Contains(inputTimecode, ";")
 
+	suffix := timecodeRe.FindStringSubmatch(inputTimecode)[5]
+	field, hasField := 0, false
+	subframes, subframeDivisor := 0, 0
+	switch {
+	case suffix == "":
+	case len(suffix) == 3:
+		subframes, _ = strconv.Atoi(suffix[1:])
+		subframeDivisor = Subframes100
+	case suffix == ".1" || suffix == "*":
+		field, hasField = 1, true
+	default:
+		hasField = true
+	}
+	inputTimecode = strings.TrimSuffix(inputTimecode, suffix)
+
 	inputTimecode = strings.Replace(inputTimecode, ";", ":", -1)
 
 	hmsf := strings.Split(inputTimecode, ":")
 
 	_hours, err := strconv.Atoi(hmsf[0])
 	if err != nil {
-		panic("Hours are malformed.")
+		return nil, NewError(ErrMalformed, "Hours are malformed.")
 	}
 	_mins, err := strconv.Atoi(hmsf[1])
 	if err != nil {
-		panic("Minutes are malformed.")
+		return nil, NewError(ErrMalformed, "Minutes are malformed.")
 	}
 	_secs, err := strconv.Atoi(hmsf[2])
 	if err != nil {
-		panic("Seconds are malformed.")
+		return nil, NewError(ErrMalformed, "Seconds are malformed.")
 	}
 	_frames, err := strconv.Atoi(hmsf[3])
 	// println(_frames)
 	if err != nil {
-		panic("Seconds are malformed.")
+		return nil, NewError(ErrMalformed, "Frames are malformed.")
 	}
 
 	return &Timecode{
@@ -130,19 +162,37 @@ func NewTimecodeFromString(inputTimecode string, frameRate float64) (*Timecode,
 		_secs:     _secs,
 		_frames:   _frames,
 		_timecode: _timecode,
+		_field:    field,
+		_hasField: hasField,
+
+		_subframes:       subframes,
+		_subframeDivisor: subframeDivisor,
 	}, nil
 }
 
+// NewTimecodeFromFields will create a Timecode with a field indicator for the
+// given field index, where 0 is the first field of 00:00:00:00 and 1 is its
+// second field.
+func NewTimecodeFromFields(inputFieldIdx int64, frameRate FrameRate, isDropframe bool) (*Timecode, error) {
+	frameIdx, field := floorDivmod(inputFieldIdx, 2)
+	t, err := NewTimecodeFromFrames(frameIdx, frameRate, isDropframe)
+	if err != nil {
+		return nil, err
+	}
+	t._field = int(field)
+	t._hasField = true
+	return t, nil
+}
+
 func (t *Timecode) GetFramerateString() string {
-	s := strconv.FormatFloat(t.FrameRate, 'f', -1, 64)
-	return s
+	return t.FrameRate.String()
 }
 
 func (t *Timecode) GetTimecode() string {
 	// calling this will spit out the normalized timecode. For instance, you can instantiate
 	// a timecode with a string that contains something like 00:00:10:99 (you can't have 99 frames)
 	// But we will run divmod to convert that to a real timecode.
-	fq, fr := divmod(int64(t._frames), int64(getTimeBase(t.FrameRate)))
+	fq, fr := divmod(int64(t._frames), int64(t.FrameRate.Timebase()))
 	// println(fq, fr)
 	mq, sr := divmod(int64(t._secs)+fq, 60)
 	// println(mq, sr)
@@ -152,39 +202,60 @@ func (t *Timecode) GetTimecode() string {
 	// println(o, hr)
 	_ = o
 
-	return formatTimecode(int64(hr), int64(mr), int64(sr), int64(fr), t.DropFrame)
+	tc := formatTimecode(int64(hr), int64(mr), int64(sr), int64(fr), t.DropFrame, t.FrameRate)
+	if t._hasField {
+		return formatField(tc, t._field)
+	}
+	if t._subframeDivisor != 0 {
+		return formatSubframes(tc, t._subframes)
+	}
+	return tc
 }
 
 func (t *Timecode) Validate() error {
 	// println("+++")
 	if t._hours > 23 {
-		return fmt.Errorf("Hours cannot be higher than 23")
+		return NewError(ErrFrameOutOfRange, "Hours cannot be higher than 23")
 	}
+	return t.ValidateLength()
+}
 
+// ValidateLength is Validate for a timecode that is a length rather than a
+// time of day, so the hours can be 24 or more.
+func (t *Timecode) ValidateLength() error {
 	if t._mins > 59 {
-		return fmt.Errorf("Minutes cannot be higher than 59")
+		return NewError(ErrFrameOutOfRange, "Minutes cannot be higher than 59")
 	}
 
 	if t._secs > 59 {
-		return fmt.Errorf("Minutes cannot be higher than 59")
+		return NewError(ErrFrameOutOfRange, "Seconds cannot be higher than 59")
 	}
 
-	lastAllowedFrame := int(math.Ceil(t.FrameRate)) - 1
+	lastAllowedFrame := t.FrameRate.Timebase() - 1
 	if t._frames > int(lastAllowedFrame) {
-		return fmt.Errorf("Frames cannot be higher than %d", lastAllowedFrame)
+		return NewError(ErrFrameOutOfRange, "Frames cannot be higher than %d", lastAllowedFrame)
 	}
 
-	if t.DropFrame {
+	if t._hasField && t.FrameRate.Timebase() > 30 {
+		return NewError(ErrUnsupportedRate, "Fields are only for interlaced rates up to 30 fps, %s is progressive", t.GetFramerateString())
+	}
 
-		validDfTimecode := false
-		for _, fr := range []float64{29.97, 59.94} {
-			if t.FrameRate == fr {
-				validDfTimecode = true
-				break
-			}
+	if t._subframeDivisor != 0 && t._subframes >= t._subframeDivisor {
+		return NewError(ErrFrameOutOfRange, "Subframes cannot be higher than %d", t._subframeDivisor-1)
+	}
+
+	if t._sampleRate != 0 {
+		idx := int64(t.GetFrameIdx())
+		perFrame := t.FrameRate.FramesToSamples(idx+1, t._sampleRate) - t.FrameRate.FramesToSamples(idx, t._sampleRate)
+		if t._sampleOffset >= perFrame {
+			return NewError(ErrFrameOutOfRange, "The sample offset cannot be higher than %d", perFrame-1)
 		}
-		if !validDfTimecode {
-			return fmt.Errorf("%s is not a valid framerate for drop frame timecode", t.GetFramerateString())
+	}
+
+	if t.DropFrame {
+
+		if !t.FrameRate.SupportsDropFrame() {
+			return NewError(ErrInvalidDropFrame, "%s is not a valid framerate for drop frame timecode", t.GetFramerateString())
 		}
 
 		fc := t.GetFrameIdx()
@@ -194,70 +265,101 @@ func (t *Timecode) Validate() error {
 			return err
 		}
 
+		tccTest._field, tccTest._hasField = t._field, t._hasField
+		tccTest._subframes, tccTest._subframeDivisor = t._subframes, t._subframeDivisor
 		if tccTest.GetTimecode() != t.GetTimecode() {
-			return fmt.Errorf("%s is not valid drop frame timecode", t.GetTimecode())
+			return NewError(ErrInvalidDropFrame, "%s is not valid drop frame timecode", t.GetTimecode())
 		}
 	}
 
 	return nil
 }
 
+// AddFrames moves the timecode by the given amount of frames, which can be
+// negative. The timecode rolls over at midnight in either direction.
 func (t *Timecode) AddFrames(frames int) {
-	if t.DropFrame {
-		// todo: investigate why we need this +1
-		//  update: I have commented it out, as it seems wrong...
-		newFrames := int64(t.GetFrameIdx()) + int64(frames) //+ 1
-
-		if newFrames < 0 {
-			// this means we have rolled over backwards.
-
-			// last possible timecode
-			// note: This is calculated slightly different than
-			// for NDF. In our case, its much easier to construct the
-			// last timecode str and then derive the frame versus
-			// calculating the last frame.
-			lastTimecodeStr := fmt.Sprintf("23:59:59;%d", getTimeBase(t.FrameRate)-1)
-			lastTimecode, _ := NewTimecodeFromString(
-				lastTimecodeStr,
-				t.FrameRate,
-			)
-			lastFrame := int64(lastTimecode.GetFrameIdx() + 1)
-			newFrames = lastFrame + newFrames
-		}
+	// A drop frame timecode at a rate without drop frame will fail Validate,
+	// so it is just counted as non drop frame here.
+	dropFrame := t.DropFrame && t.FrameRate.SupportsDropFrame()
+
+	newFrames := int64(t.GetFrameIdx()) + int64(frames)
+
+	// This handles rolling over backwards, and more than a day in either direction.
+	_, newFrames = floorDivmod(newFrames, t.FrameRate.FramesPerDay(dropFrame))
 
-		tt, _ := NewTimecodeFromFrames(newFrames, t.FrameRate, t.DropFrame)
+	if dropFrame {
+		tt, err := NewTimecodeFromFrames(newFrames, t.FrameRate, true)
+		if err != nil {
+			return
+		}
 		t._hours = tt._hours
 		t._mins = tt._mins
 		t._secs = tt._secs
 		t._frames = tt._frames
-		t.DropFrame = tt.DropFrame
 
 	} else {
 
-		newFrames := t._frames + frames
-		if newFrames < 0 {
-			// this means we have rolled over backwards.
-
-			// last possible frame
-			lastFrame := (24 * 60 * 60 * getTimeBase(t.FrameRate))
-			newFrames = lastFrame + newFrames
-		}
-
-		fq, fr := divmod(int64(newFrames), int64(getTimeBase(t.FrameRate)))
+		sr, fr := divmod(newFrames, int64(t.FrameRate.Timebase()))
 		t._frames = int(fr)
 
-		mq, sr := divmod(int64(t._secs)+fq, 60)
+		mr, sr := divmod(sr, 60)
 		t._secs = int(sr)
 
-		hq, mr := divmod(int64(t._mins)+mq, 60)
+		hr, mr := divmod(mr, 60)
 		t._mins = int(mr)
-
-		o, hr := divmod(int64(t._hours)+hq, 24)
 		t._hours = int(hr)
+	}
+
+}
+
+// AddFields moves the timecode by the given amount of fields, which can be
+// negative. A timecode without a field indicator is taken to be on its first
+// field, and gets one in place of any subframes or sample offset.
+func (t *Timecode) AddFields(fields int) {
+	frames, field := floorDivmod(int64(t._field)+int64(fields), 2)
+	t.AddFrames(int(frames))
+	t.clearPosition()
+	t._field = int(field)
+	t._hasField = true
+}
+
+// HasField reports whether the timecode has a field indicator.
+func (t *Timecode) HasField() bool {
+	return t._hasField
+}
 
-		_ = o
+// GetField returns 0 for the first field and 1 for the second. A timecode
+// without a field indicator is on its first field.
+func (t *Timecode) GetField() int {
+	return t._field
+}
+
+// SetField sets the field indicator, 0 for the first field and 1 for the
+// second. It replaces subframes or a sample offset.
+func (t *Timecode) SetField(field int) error {
+	if field != 0 && field != 1 {
+		return NewError(ErrFrameOutOfRange, "A frame has fields 0 and 1, not %d", field)
 	}
+	t.clearPosition()
+	t._field = field
+	t._hasField = true
+	return nil
+}
 
+// ClearField removes the field indicator, so the timecode is the whole frame.
+func (t *Timecode) ClearField() {
+	t._field = 0
+	t._hasField = false
+}
+
+// GetComponents returns the normalized hours, minutes, seconds and frames
+// fields, the same as shown by GetTimecode.
+func (t *Timecode) GetComponents() (hours, minutes, seconds, frames int) {
+	fq, fr := divmod(int64(t._frames), int64(t.FrameRate.Timebase()))
+	mq, sr := divmod(int64(t._secs)+fq, 60)
+	hq, mr := divmod(int64(t._mins)+mq, 60)
+	_, hr := divmod(int64(t._hours)+hq, 24)
+	return int(hr), int(mr), int(sr), int(fr)
 }
 
 func (t *Timecode) GetFrameCount() int {
@@ -266,7 +368,7 @@ func (t *Timecode) GetFrameCount() int {
 
 func (t *Timecode) GetFrameIdx() int {
 
-	timeBase := getTimeBase(t.FrameRate)
+	timeBase := t.FrameRate.Timebase()
 	frameCount := 0
 	if t.DropFrame == false {
 		hrsToSecs := t._hours * 60 * 60
@@ -281,8 +383,7 @@ func (t *Timecode) GetFrameIdx() int {
 
 		// adapted from https://www.davidheidelberger.com/2010/06/10/drop-frame-timecode/
 
-		dropFrames := int(math.Round(t.FrameRate * 0.066666)) //Number of drop frames is 6% of framerate rounded to nearest integer
-		// Should this ^ be round or int? todo: This could be wrong.
+		dropFrames := t.FrameRate.DropFramesPerMinute() //Number of drop frames per minute
 
 		hourFrames := timeBase * 60 * 60          //Number of frames per hour (non-drop)
 		minuteFrames := timeBase * 60             //Number of frames per minute (non-drop)
@@ -292,3 +393,18 @@ func (t *Timecode) GetFrameIdx() int {
 
 	return frameCount
 }
+
+// GetFieldIdx returns the field index, which is two for every frame. A
+// timecode without a field indicator is its first field.
+func (t *Timecode) GetFieldIdx() int64 {
+	return int64(t.GetFrameIdx())*2 + int64(t._field)
+}
+
+// fieldIdx is GetFieldIdx, with the given field for a timecode without a
+// field indicator.
+func (t *Timecode) fieldIdx(field int) int64 {
+	if t._hasField {
+		field = t._field
+	}
+	return int64(t.GetFrameIdx())*2 + int64(field)
+}
@@ -11,7 +11,7 @@ import (
 // for a valid return value.
 func TestNDFIndexes(t *testing.T) {
 
-	tc, _ := NewTimecodeFromFrames(0, 23.98, false)
+	tc, _ := NewTimecodeFromFrames(0, FrameRateFromFloat(23.98), false)
 	if tc.GetFrameIdx() != 0 {
 		t.Fatalf(`%v != 0`, tc.GetFrameIdx())
 	}
@@ -20,7 +20,7 @@ func TestNDFIndexes(t *testing.T) {
 
 func TestAddNDFIndexes(t *testing.T) {
 
-	tc, _ := NewTimecodeFromFrames(0, 23.98, false)
+	tc, _ := NewTimecodeFromFrames(0, FrameRateFromFloat(23.98), false)
 	tc.AddFrames(1)
 	if tc.GetFrameIdx() != 1 {
 		t.Fatalf(`%v != 1`, tc.GetFrameIdx())
@@ -35,7 +35,7 @@ func TestAddNDFIndexes(t *testing.T) {
 
 func TestDFIndexes(t *testing.T) {
 
-	tcdf, _ := NewTimecodeFromFrames(0, 29.97, true)
+	tcdf, _ := NewTimecodeFromFrames(0, FrameRateFromFloat(29.97), true)
 
 	if tcdf.GetFrameIdx() != 0 {
 		t.Fatalf(`%v != 0`, tcdf.GetFrameIdx())
@@ -43,7 +43,7 @@ func TestDFIndexes(t *testing.T) {
 }
 
 func TestRolloverForwardsDF(t *testing.T) {
-	tc, err := NewTimecodeFromString("23:59:59:29", 29.97)
+	tc, err := NewTimecodeFromString("23:59:59:29", FrameRateFromFloat(29.97))
 	require.Nil(t, err)
 	tc.AddFrames(1)
 	require.Equal(t, "00:00:00:00", tc.GetTimecode())
@@ -51,21 +51,21 @@ func TestRolloverForwardsDF(t *testing.T) {
 
 // Not implemented!
 func TestRolloverBackwardsDF(t *testing.T) {
-	tc, err := NewTimecodeFromString("00:00:00;00", 29.97)
+	tc, err := NewTimecodeFromString("00:00:00;00", FrameRateFromFloat(29.97))
 	require.Nil(t, err)
 	tc.AddFrames(-1)
 	require.Equal(t, "23:59:59;29", tc.GetTimecode())
 }
 
 func TestRolloverForwardsNDF(t *testing.T) {
-	tc, err := NewTimecodeFromString("23:59:59:23", 23.976)
+	tc, err := NewTimecodeFromString("23:59:59:23", FrameRateFromFloat(23.976))
 	require.Nil(t, err)
 	tc.AddFrames(1)
 	require.Equal(t, "00:00:00:00", tc.GetTimecode())
 }
 
 func TestRolloverBackwardsNDF(t *testing.T) {
-	tc, err := NewTimecodeFromString("00:00:00:00", 23.976)
+	tc, err := NewTimecodeFromString("00:00:00:00", FrameRateFromFloat(23.976))
 	require.Nil(t, err)
 	tc.AddFrames(-1)
 	require.Equal(t, "23:59:59:23", tc.GetTimecode())
@@ -136,7 +136,7 @@ func Test_TimecodeIndexes(t *testing.T) {
 	t.Parallel()
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			tc, err := NewTimecodeFromString(tt.timecode, tt.framerate)
+			tc, err := NewTimecodeFromString(tt.timecode, FrameRateFromFloat(tt.framerate))
 			require.NoError(t, err)
 			require.Equal(t, tt.expectedIdx, tc.GetFrameIdx())
 		})
@@ -162,6 +162,12 @@ func TestDfIFrameRates(t *testing.T) {
 			timecode:  "00:00:00;10",
 			framerate: 59.94,
 		},
+		{
+			name:      "valid 119.88",
+			error:     nil,
+			timecode:  "00:00:00;110",
+			framerate: 119.88,
+		},
 		{
 			name:      "valid 23.976",
 			error:     fmt.Errorf("23.976 is not a valid framerate for drop frame timecode"),
@@ -171,7 +177,7 @@ func TestDfIFrameRates(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		tc, err := NewTimecodeFromString(tt.timecode, tt.framerate)
+		tc, err := NewTimecodeFromString(tt.timecode, FrameRateFromFloat(tt.framerate))
 		require.Nil(t, err)
 
 		err = tc.Validate()
@@ -356,7 +362,7 @@ func TestSeriesTc(t *testing.T) {
 
 		for _, timecode := range tt.timecodes {
 			t.Run(tt.name, func(t *testing.T) {
-				tc, err := NewTimecodeFromString(timecode.timecode, tt.framerate)
+				tc, err := NewTimecodeFromString(timecode.timecode, FrameRateFromFloat(tt.framerate))
 				require.Nil(t, err)
 				err = tc.Validate()
 				if timecode.valid {
@@ -396,7 +402,7 @@ func TestDFNonValid(t *testing.T) {
 	t.Parallel()
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			tcdf, err := NewTimecodeFromString(tt.timecode, tt.framerate)
+			tcdf, err := NewTimecodeFromString(tt.timecode, FrameRateFromFloat(tt.framerate))
 			err = tcdf.Validate()
 
 			if tt.error != nil {
@@ -408,3 +414,179 @@ func TestDFNonValid(t *testing.T) {
 	}
 
 }
+
+func TestHighFrameRateTimecodes(t *testing.T) {
+	tests := []struct {
+		name      string
+		timecode  string
+		framerate string
+		valid     bool
+		frameIdx  int
+	}{
+		{"100 three digit frames", "00:00:01:99", "100", true, 199},
+		{"120 three digit frames", "00:00:01:119", "120", true, 239},
+		{"120 frames overflow", "00:00:01:120", "120", false, 0},
+		{"119.88 DF dropped label", "00:01:00;07", "119.88", false, 0},
+		{"119.88 DF first label", "00:01:00;08", "119.88", true, 7200},
+		{"119.88 DF tenth minute", "00:10:00;00", "119.88", true, 71928},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, _, err := ParseFrameRate(tt.framerate)
+			require.NoError(t, err)
+			tc, err := NewTimecodeFromString(tt.timecode, rate)
+			require.NoError(t, err)
+
+			err = tc.Validate()
+			if !tt.valid {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.frameIdx, tc.GetFrameIdx())
+		})
+	}
+}
+
+// TestRoundTripEveryFrame checks that every frame of the day survives frames
+// -> timecode string -> frames, and that AddFrames agrees with the next frame
+// index, for each supported rate. That is over 80 million frames, so it is
+// skipped with -short.
+func TestRoundTripEveryFrame(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Round tripping every frame of the day is slow")
+	}
+	rates := []struct {
+		framerate string
+		dropFrame bool
+	}{
+		{"23.976", false},
+		{"24", false},
+		{"25", false},
+		{"29.97", false},
+		{"29.97", true},
+		{"30", false},
+		{"47.952", false},
+		{"48", false},
+		{"50", false},
+		{"59.94", false},
+		{"59.94", true},
+		{"60", false},
+		{"100", false},
+		{"119.88", false},
+		{"119.88", true},
+		{"120", false},
+	}
+
+	for _, rr := range rates {
+		name := rr.framerate
+		if rr.dropFrame {
+			name += "DF"
+		}
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			rate, _, err := ParseFrameRate(rr.framerate)
+			require.NoError(t, err)
+
+			lastTc, err := NewTimecodeFromString(formatTimecode(23, 59, 59, int64(rate.Timebase()-1), rr.dropFrame, rate), rate)
+			require.NoError(t, err)
+			lastIdx := int64(lastTc.GetFrameIdx())
+			require.Equal(t, rate.FramesPerDay(rr.dropFrame), lastIdx+1)
+
+			// require is only called on a failure, as formatting its
+			// arguments for every frame is most of the time.
+			for idx := int64(0); idx <= lastIdx; idx++ {
+				tc, err := NewTimecodeFromFrames(idx, rate, rr.dropFrame)
+				require.NoError(t, err)
+				timecode := tc.GetTimecode()
+
+				parsed, err := NewTimecodeFromString(timecode, rate)
+				if err == nil {
+					err = parsed.Validate()
+				}
+				if err != nil {
+					require.NoError(t, err, timecode)
+				}
+				if parsed.GetFrameIdx() != int(idx) {
+					require.Equal(t, int(idx), parsed.GetFrameIdx(), timecode)
+				}
+
+				parsed.AddFrames(1)
+				if next := int((idx + 1) % (lastIdx + 1)); parsed.GetFrameIdx() != next {
+					require.Equal(t, next, parsed.GetFrameIdx(), timecode)
+				}
+			}
+		})
+	}
+}
+
+func TestFieldTimecodes(t *testing.T) {
+	tests := []struct {
+		name      string
+		timecode  string
+		fps       float64
+		expected  string
+		field     int
+		fieldIdx  int64
+		expectErr error
+	}{
+		{"Whole frame", "01:00:00:00", 25, "01:00:00:00", 0, 180000, nil},
+		{"First field", "01:00:00:00.0", 25, "01:00:00:00.0", 0, 180000, nil},
+		{"Second field", "01:00:00:00.1", 25, "01:00:00:00.1", 1, 180001, nil},
+		{"Asterisk is the second field", "01:00:00:00*", 25, "01:00:00:00.1", 1, 180001, nil},
+		{"59.94i drop frame", "00:01:00;02.1", 29.97, "00:01:00;02.1", 1, 3601, nil},
+		{"Progressive rate", "01:00:00:00.1", 50, "", 0, 0, ErrUnsupportedRate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tc, err := NewTimecodeFromString(tt.timecode, FrameRateFromFloat(tt.fps))
+			require.NoError(t, err)
+			if tt.expectErr != nil {
+				require.ErrorIs(t, tc.Validate(), tt.expectErr)
+				return
+			}
+			require.NoError(t, tc.Validate())
+			require.Equal(t, tt.expected, tc.GetTimecode())
+			require.Equal(t, tt.field, tc.GetField())
+			require.Equal(t, tt.fieldIdx, tc.GetFieldIdx())
+
+			back, err := NewTimecodeFromFields(tt.fieldIdx, tc.FrameRate, tc.DropFrame)
+			require.NoError(t, err)
+			require.Equal(t, tt.fieldIdx, back.GetFieldIdx())
+			require.True(t, back.HasField())
+		})
+	}
+
+	for _, malformed := range []string{"01:00:00:00.2", "01:00:00:00.", "01:00:00:00**"} {
+		_, err := NewTimecodeFromString(malformed, FrameRateFromFloat(25))
+		require.ErrorIs(t, err, ErrMalformed, malformed)
+	}
+}
+
+func TestAddFields(t *testing.T) {
+	rate := FrameRateFromFloat(29.97)
+	tc, err := NewTimecodeFromString("00:00:59;29", rate)
+	require.NoError(t, err)
+
+	tc.AddFields(1)
+	require.Equal(t, "00:00:59;29.1", tc.GetTimecode())
+	tc.AddFields(1)
+	require.Equal(t, "00:01:00;02.0", tc.GetTimecode())
+	tc.AddFields(-3)
+	require.Equal(t, "00:00:59;28.1", tc.GetTimecode())
+
+	// Frames keep the field.
+	tc.AddFrames(2)
+	require.Equal(t, "00:01:00;02.1", tc.GetTimecode())
+
+	tc, err = NewTimecodeFromString("00:00:00:00.0", FrameRateFromFloat(25))
+	require.NoError(t, err)
+	tc.AddFields(-1)
+	require.Equal(t, "23:59:59:24.1", tc.GetTimecode())
+
+	require.ErrorIs(t, tc.SetField(2), ErrFrameOutOfRange)
+	tc.ClearField()
+	require.Equal(t, "23:59:59:24", tc.GetTimecode())
+}
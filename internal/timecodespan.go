@@ -2,65 +2,179 @@ package internal
 
 import (
 	"fmt"
-	"math"
-	"strings"
 )
 
 type TimecodeSpan struct {
 	StartTimecode *Timecode
 	LastTimecode  *Timecode
-	Framerate     float64
+	Framerate     FrameRate
 	Dropframe     bool
+	// Days is the number of midnights between the start and last timecode.
+	// A negative value means the last timecode is on an earlier day.
+	Days int
 }
 
+// NewTimecodeSpan will create a span from the first timecode up to and including
+// the last timecode. If the last timecode is before the first timecode, the span
+// is taken to cross midnight (ie 23:59:00:00 -> 00:01:00:00 is two minutes).
 func NewTimecodeSpan(firstTimecode, lastTimecode *Timecode) (*TimecodeSpan, error) {
+	if firstTimecode == nil || lastTimecode == nil {
+		return nil, NewError(ErrMalformed, "A span needs both a first and last timecode")
+	}
+
+	// A timecode without a field indicator is both of its fields, so it is
+	// only before a field of the same frame when it is the first timecode.
+	days := 0
+	if lastTimecode.fieldIdx(1) < firstTimecode.fieldIdx(0) {
+		days = 1
+	}
+
+	return NewTimecodeSpanWithDays(firstTimecode, lastTimecode, days)
+}
+
+// NewSignedTimecodeSpan will create a span that is negative when the last
+// timecode is before the first timecode, rather than crossing midnight.
+func NewSignedTimecodeSpan(firstTimecode, lastTimecode *Timecode) (*TimecodeSpan, error) {
+	return NewTimecodeSpanWithDays(firstTimecode, lastTimecode, 0)
+}
+
+// NewTimecodeSpanWithDays will create a span where the last timecode is the
+// given number of days after the first timecode. This is how spans longer
+// than 24 hours are represented.
+func NewTimecodeSpanWithDays(firstTimecode, lastTimecode *Timecode, days int) (*TimecodeSpan, error) {
+	if firstTimecode == nil || lastTimecode == nil {
+		return nil, NewError(ErrMalformed, "A span needs both a first and last timecode")
+	}
+	if firstTimecode.FrameRate != lastTimecode.FrameRate {
+		return nil, NewError(ErrUnsupportedRate, "The first and last timecode of a span must have the same framerate")
+	}
 
 	return &TimecodeSpan{
 		StartTimecode: firstTimecode,
 		LastTimecode:  lastTimecode,
 		Framerate:     firstTimecode.FrameRate,
 		Dropframe:     firstTimecode.DropFrame,
+		Days:          days,
 	}, nil
 }
 
-// TOdo: This calc appears to be wrong
+// NewTimecodeSpanFromOffset will create a span from the first timecode to the
+// timecode offset frames away. The offset can be negative or longer than a day.
+func NewTimecodeSpanFromOffset(firstTimecode *Timecode, offset int64) (*TimecodeSpan, error) {
+	if firstTimecode == nil {
+		return nil, NewError(ErrMalformed, "A span needs a first timecode")
+	}
+	framesPerDay := firstTimecode.FrameRate.FramesPerDay(firstTimecode.DropFrame)
+
+	lastIdx := int64(firstTimecode.GetFrameIdx()) + offset
+	days, lastIdx := floorDivmod(lastIdx, framesPerDay)
+
+	lastTimecode, err := NewTimecodeFromFrames(lastIdx, firstTimecode.FrameRate, firstTimecode.DropFrame)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewTimecodeSpanWithDays(firstTimecode, lastTimecode, int(days))
+}
+
+// CrossesMidnight reports whether the span goes past 23:59:59:xx (or before
+// 00:00:00:00 for negative spans).
+func (t *TimecodeSpan) CrossesMidnight() bool {
+	return t.Days != 0
+}
+
 func (t *TimecodeSpan) GetTotalSeconds() float64 {
 	tf := t.GetTotalFrames()
 
-	return float64(tf) / t.Framerate
+	return t.Framerate.FramesToSeconds(int64(tf))
 }
 
-// todo: note these two heavily rely on the fact that there is at
-//
-//	least one frame in the span (see +1)
-
+// GetTotalFrames counts the frames in the span, inclusive of the first and
+// last timecode. A negative span counts both ends too, so a span going back
+// from 00:00:00:10 to 00:00:00:05 is -6 frames.
 func (t *TimecodeSpan) GetTotalFrames() int {
-	tf := t.LastTimecode.GetFrameIdx() - t.StartTimecode.GetFrameIdx() + 1
-	return tf
+	framesPerDay := t.Framerate.FramesPerDay(t.Dropframe)
+	tf := int64(t.LastTimecode.GetFrameIdx()-t.StartTimecode.GetFrameIdx()) + int64(t.Days)*framesPerDay
+	if tf < 0 {
+		return int(tf - 1)
+	}
+	return int(tf + 1)
 }
 
-func (t *TimecodeSpan) GetSpanTimecode() string {
-	_t, _ := NewTimecodeFromFrames(int64(t.GetTotalFrames()), t.Framerate, t.Dropframe)
-	return _t.GetTimecode()
+// GetTotalFields counts the fields in the span, inclusive of the first and
+// last timecode. A timecode without a field indicator is both of its fields,
+// so a span of frames is twice as many fields.
+func (t *TimecodeSpan) GetTotalFields() int {
+	fieldsPerDay := 2 * t.Framerate.FramesPerDay(t.Dropframe)
+	between := func(firstField, lastField int) int64 {
+		return t.LastTimecode.fieldIdx(lastField) - t.StartTimecode.fieldIdx(firstField) + int64(t.Days)*fieldsPerDay
+	}
+	// Going forwards the span is from the first field of the first timecode
+	// to the second field of the last, and the other way round backwards.
+	if tf := between(0, 1); tf >= 0 {
+		return int(tf + 1)
+	}
+	return int(between(1, 0) - 1)
 }
 
-func (t *TimecodeSpan) GetSpanRealtime() string {
-	_totalSeconds := t.GetTotalSeconds()
+// GetTotalSamples counts the audio samples in the frames of the span, from
+// the first sample of the first frame to the end of the last frame. At pull
+// down rates frames aren't a whole number of samples, so this depends on
+// where the span starts. A negative span gives a negative count.
+func (t *TimecodeSpan) GetTotalSamples(sampleRate int) int64 {
+	start := int64(t.StartTimecode.GetFrameIdx())
+	frames := int64(t.GetTotalFrames())
+	if frames < 0 {
+		return t.Framerate.FramesToSamples(start+frames+1, sampleRate) - t.Framerate.FramesToSamples(start+1, sampleRate)
+	}
+	return t.Framerate.FramesToSamples(start+frames, sampleRate) - t.Framerate.FramesToSamples(start, sampleRate)
+}
 
-	_sq, _sr := divmod(int64(_totalSeconds), int64(60))
+// GetSpanTimecode returns the length of the span as a timecode. Hours are
+// not wrapped at 24, and negative spans are prefixed with "-".
+func (t *TimecodeSpan) GetSpanTimecode() string {
+	return formatLength(int64(t.GetTotalFrames()), t.Framerate, t.Dropframe)
+}
 
-	_hq, mr := divmod(int64(_sq), 60)
+// formatLength formats a number of frames as a timecode. Hours are not
+// wrapped at 24, and negative lengths are prefixed with "-".
+func formatLength(frames int64, rate FrameRate, dropFrame bool) string {
+	sign := ""
+	if frames < 0 {
+		sign = "-"
+		frames = -frames
+	}
+
+	dropFrame = dropFrame && rate.SupportsDropFrame()
+	days, rem := divmod(frames, rate.FramesPerDay(dropFrame))
+	_t, err := NewTimecodeFromFrames(rem, rate, dropFrame)
+	if err != nil {
+		return ""
+	}
+
+	// GetTimecode would wrap the hours, so format it here.
+	return sign + formatTimecode(int64(_t._hours)+days*24, int64(_t._mins), int64(_t._secs), int64(_t._frames), _t.DropFrame, rate)
+}
+
+// GetSpanRealtime returns the length of the span as hh:mm:ss.mmm. Hours are
+// not wrapped at 24, and negative spans are prefixed with "-".
+func (t *TimecodeSpan) GetSpanRealtime() string {
+	// Work in whole milliseconds so the exact rate is used all the
+	// way through, rather than a rounded float.
+	_totalMs := t.Framerate.FramesToMilliseconds(int64(t.GetTotalFrames()))
 
-	_o, hr := divmod(int64(_hq), 24)
+	sign := ""
+	if _totalMs < 0 {
+		sign = "-"
+		_totalMs = -_totalMs
+	}
 
-	_ = _o
+	_secs, ms := divmod(_totalMs, 1000)
 
-	ms := _totalSeconds - math.Floor(_totalSeconds)
+	_sq, _sr := divmod(_secs, int64(60))
 
-	str := fmt.Sprintf("%.3f", ms)
-	// Remove the "0." prefix
-	result := strings.TrimPrefix(str, "0.")
+	hr, mr := divmod(int64(_sq), 60)
 
-	return formatTimeSpan(int64(hr), int64(mr), int64(_sr), result)
+	return sign + formatTimeSpan(int64(hr), int64(mr), int64(_sr), fmt.Sprintf("%03d", ms))
 
 }
@@ -7,8 +7,8 @@ import (
 )
 
 func TestNewTimecodeSpan(t *testing.T) {
-	start := &Timecode{FrameRate: 24.0, DropFrame: false, _frames: 0}
-	end := &Timecode{FrameRate: 24.0, DropFrame: false, _frames: 240}
+	start := &Timecode{FrameRate: FrameRate{Num: 24, Den: 1}, DropFrame: false, _frames: 0}
+	end := &Timecode{FrameRate: FrameRate{Num: 24, Den: 1}, DropFrame: false, _frames: 240}
 
 	span, err := NewTimecodeSpan(start, end)
 	if err != nil {
@@ -21,7 +21,7 @@ func TestNewTimecodeSpan(t *testing.T) {
 	if span.LastTimecode != end {
 		t.Errorf("expected LastTimecode to be %v, got %v", end, span.LastTimecode)
 	}
-	if span.Framerate != 24.0 {
+	if span.Framerate != (FrameRate{Num: 24, Den: 1}) {
 		t.Errorf("expected Framerate to be 24.0, got %v", span.Framerate)
 	}
 	if span.Dropframe {
@@ -30,8 +30,8 @@ func TestNewTimecodeSpan(t *testing.T) {
 }
 
 func TestGetTotalSeconds(t *testing.T) {
-	start := &Timecode{FrameRate: 24.0, DropFrame: false, _frames: 0}
-	end := &Timecode{FrameRate: 24.0, DropFrame: false, _frames: 240}
+	start := &Timecode{FrameRate: FrameRate{Num: 24, Den: 1}, DropFrame: false, _frames: 0}
+	end := &Timecode{FrameRate: FrameRate{Num: 24, Den: 1}, DropFrame: false, _frames: 240}
 	span, _ := NewTimecodeSpan(start, end)
 
 	expected := 10.041666666666666
@@ -41,8 +41,8 @@ func TestGetTotalSeconds(t *testing.T) {
 }
 
 func TestGetTotalFrames(t *testing.T) {
-	start := &Timecode{FrameRate: 24.0, DropFrame: false, _frames: 0}
-	end := &Timecode{FrameRate: 24.0, DropFrame: false, _frames: 240}
+	start := &Timecode{FrameRate: FrameRate{Num: 24, Den: 1}, DropFrame: false, _frames: 0}
+	end := &Timecode{FrameRate: FrameRate{Num: 24, Den: 1}, DropFrame: false, _frames: 240}
 	span, _ := NewTimecodeSpan(start, end)
 
 	expected := 241 // inclusive of start and end frames
@@ -52,8 +52,8 @@ func TestGetTotalFrames(t *testing.T) {
 }
 
 func TestGetSpanTimecode(t *testing.T) {
-	start := &Timecode{FrameRate: 24.0, DropFrame: false, _frames: 0}
-	end := &Timecode{FrameRate: 24.0, DropFrame: false, _frames: 240}
+	start := &Timecode{FrameRate: FrameRate{Num: 24, Den: 1}, DropFrame: false, _frames: 0}
+	end := &Timecode{FrameRate: FrameRate{Num: 24, Den: 1}, DropFrame: false, _frames: 240}
 	span, _ := NewTimecodeSpan(start, end)
 
 	expected := "00:00:10:01" // 241 frames at 24 fps
@@ -63,8 +63,8 @@ func TestGetSpanTimecode(t *testing.T) {
 }
 
 func TestGetSpanRealtime(t *testing.T) {
-	start := &Timecode{FrameRate: 24.0, DropFrame: false, _frames: 0}
-	end := &Timecode{FrameRate: 24.0, DropFrame: false, _frames: 240}
+	start := &Timecode{FrameRate: FrameRate{Num: 24, Den: 1}, DropFrame: false, _frames: 0}
+	end := &Timecode{FrameRate: FrameRate{Num: 24, Den: 1}, DropFrame: false, _frames: 240}
 	span, _ := NewTimecodeSpan(start, end)
 
 	expected := "00:00:10.042" // 10.042 seconds in real time
@@ -108,9 +108,9 @@ func Test_SpanIndexes(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			start, err := NewTimecodeFromString(test.startTimecode, test.fps)
+			start, err := NewTimecodeFromString(test.startTimecode, FrameRateFromFloat(test.fps))
 			require.NoError(t, err)
-			end, err := NewTimecodeFromString(test.endTimecode, test.fps)
+			end, err := NewTimecodeFromString(test.endTimecode, FrameRateFromFloat(test.fps))
 			require.NoError(t, err)
 			span, err := NewTimecodeSpan(start, end)
 			require.NoError(t, err)
@@ -122,3 +122,240 @@ func Test_SpanIndexes(t *testing.T) {
 		})
 	}
 }
+
+func TestGetSpanRealtimeExactRate(t *testing.T) {
+	tests := []struct {
+		name          string
+		startTimecode string
+		endTimecode   string
+		fps           string
+		expected      string
+	}{
+		{
+			// An hour of drop frame is 3.6ms short of an hour.
+			name:          "29.97 DF hour",
+			startTimecode: "00:00:00;00",
+			endTimecode:   "00:59:59;29",
+			fps:           "29.97",
+			expected:      "00:59:59.996",
+		},
+		{
+			name:          "23.976 hour",
+			startTimecode: "00:00:00:00",
+			endTimecode:   "00:59:59:23",
+			fps:           "24000/1001",
+			expected:      "01:00:03.600",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r, _, err := ParseFrameRate(test.fps)
+			require.NoError(t, err)
+			start, err := NewTimecodeFromString(test.startTimecode, r)
+			require.NoError(t, err)
+			end, err := NewTimecodeFromString(test.endTimecode, r)
+			require.NoError(t, err)
+			span, err := NewTimecodeSpan(start, end)
+			require.NoError(t, err)
+
+			require.Equal(t, test.expected, span.GetSpanRealtime())
+		})
+	}
+}
+
+func TestSpanCrossingMidnight(t *testing.T) {
+	rate := FrameRate{24, 1}
+	start, err := NewTimecodeFromString("23:59:00:00", rate)
+	require.NoError(t, err)
+	end, err := NewTimecodeFromString("00:01:00:00", rate)
+	require.NoError(t, err)
+
+	span, err := NewTimecodeSpan(start, end)
+	require.NoError(t, err)
+	require.True(t, span.CrossesMidnight())
+	require.Equal(t, 2*60*24+1, span.GetTotalFrames())
+	require.Equal(t, "00:02:00:01", span.GetSpanTimecode())
+
+	signed, err := NewSignedTimecodeSpan(start, end)
+	require.NoError(t, err)
+	require.False(t, signed.CrossesMidnight())
+	require.Equal(t, -(23*60*60*24 + 58*60*24 + 1), signed.GetTotalFrames())
+	require.Equal(t, "-23:58:00:01", signed.GetSpanTimecode())
+	require.Equal(t, "-23:58:00.042", signed.GetSpanRealtime())
+
+	// A frame includes its second field, so it isn't before it.
+	field, err := NewTimecodeFromString("01:00:00:00.1", FrameRate{25, 1})
+	require.NoError(t, err)
+	frame, err := NewTimecodeFromString("01:00:00:00", FrameRate{25, 1})
+	require.NoError(t, err)
+	span, err = NewTimecodeSpan(field, frame)
+	require.NoError(t, err)
+	require.False(t, span.CrossesMidnight())
+	require.Equal(t, 1, span.GetTotalFrames())
+}
+
+func TestSpanFromOffset(t *testing.T) {
+	tests := []struct {
+		name            string
+		startTimecode   string
+		fps             string
+		offset          int64
+		lastTimecode    string
+		crossesMidnight bool
+		spanFrameCount  int
+		spanTimecode    string
+		spanRealtime    string
+	}{
+		{
+			name:           "Forward",
+			startTimecode:  "01:00:00:00",
+			fps:            "25",
+			offset:         25,
+			lastTimecode:   "01:00:01:00",
+			spanFrameCount: 26,
+			spanTimecode:   "00:00:01:01",
+			spanRealtime:   "00:00:01.040",
+		},
+		{
+			name:           "Backward",
+			startTimecode:  "01:00:00:00",
+			fps:            "25",
+			offset:         -25,
+			lastTimecode:   "00:59:59:00",
+			spanFrameCount: -26,
+			spanTimecode:   "-00:00:01:01",
+			spanRealtime:   "-00:00:01.040",
+		},
+		{
+			name:            "Backward over midnight",
+			startTimecode:   "00:00:00:00",
+			fps:             "25",
+			offset:          -1,
+			lastTimecode:    "23:59:59:24",
+			crossesMidnight: true,
+			spanFrameCount:  -2,
+			spanTimecode:    "-00:00:00:02",
+			spanRealtime:    "-00:00:00.080",
+		},
+		{
+			name:            "Longer than a day",
+			startTimecode:   "00:00:00:00",
+			fps:             "24",
+			offset:          (26*60*60+14*60+3)*24 + 11,
+			lastTimecode:    "02:14:03:11",
+			crossesMidnight: true,
+			spanFrameCount:  (26*60*60+14*60+3)*24 + 12,
+			spanTimecode:    "26:14:03:12",
+			spanRealtime:    "26:14:03.500",
+		},
+		{
+			name:            "Longer than a day DF",
+			startTimecode:   "00:00:00;00",
+			fps:             "29.97",
+			offset:          2589408 + 107892 - 1,
+			lastTimecode:    "00:59:59;29",
+			crossesMidnight: true,
+			spanFrameCount:  2589408 + 107892,
+			spanTimecode:    "25:00:00;00",
+			spanRealtime:    "24:59:59.910",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rate, _, err := ParseFrameRate(test.fps)
+			require.NoError(t, err)
+			start, err := NewTimecodeFromString(test.startTimecode, rate)
+			require.NoError(t, err)
+
+			span, err := NewTimecodeSpanFromOffset(start, test.offset)
+			require.NoError(t, err)
+
+			require.Equal(t, test.lastTimecode, span.LastTimecode.GetTimecode())
+			require.Equal(t, test.crossesMidnight, span.CrossesMidnight())
+			require.Equal(t, test.spanFrameCount, span.GetTotalFrames())
+			require.Equal(t, test.spanTimecode, span.GetSpanTimecode())
+			require.Equal(t, test.spanRealtime, span.GetSpanRealtime())
+		})
+	}
+}
+
+func TestGetTotalFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		first    string
+		last     string
+		signed   bool
+		expected int
+	}{
+		{"Whole frames", "01:00:00:00", "01:00:00:09", false, 20},
+		{"Second field to first field", "01:00:00:00.1", "01:00:00:01.0", false, 2},
+		{"One field", "01:00:00:00.1", "01:00:00:00.1", false, 1},
+		{"Frame to field", "01:00:00:00", "01:00:00:01.0", false, 3},
+		{"Crossing midnight", "23:59:59:24.1", "00:00:00:00.0", false, 2},
+		{"Back a field", "01:00:00:00.1", "01:00:00:00.0", true, -2},
+		{"Back whole frames", "01:00:00:10", "01:00:00:05", true, -12},
+		{"Earlier field wraps a day", "01:00:00:00.1", "01:00:00:00.0", false, 2 * 25 * 60 * 60 * 24},
+		{"Second field to its frame", "01:00:00:00.1", "01:00:00:00", false, 1},
+		{"Frame to its first field", "01:00:00:00", "01:00:00:00.0", false, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate := FrameRateFromFloat(25)
+			first, err := NewTimecodeFromString(tt.first, rate)
+			require.NoError(t, err)
+			last, err := NewTimecodeFromString(tt.last, rate)
+			require.NoError(t, err)
+
+			var span *TimecodeSpan
+			if tt.signed {
+				span, err = NewSignedTimecodeSpan(first, last)
+			} else {
+				span, err = NewTimecodeSpan(first, last)
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, span.GetTotalFields())
+		})
+	}
+}
+
+func TestGetTotalSamples(t *testing.T) {
+	tests := []struct {
+		name       string
+		first      string
+		last       string
+		fps        float64
+		sampleRate int
+		signed     bool
+		expected   int64
+	}{
+		{"One second at 25", "01:00:00:00", "01:00:00:24", 25, 48000, false, 48000},
+		{"One frame at 29.97", "00:00:00:00", "00:00:00:00", 29.97, 48000, false, 1602},
+		{"Third frame at 29.97", "00:00:00:02", "00:00:00:02", 29.97, 48000, false, 1601},
+		{"Five frames at 29.97", "00:00:00:00", "00:00:00:04", 29.97, 48000, false, 8008},
+		{"A minute of 29.97DF", "00:00:00;00", "00:00:59;29", 29.97, 48000, false, 2882880},
+		{"23.976 at 96k", "00:00:00:00", "00:00:00:23", 23.976, 96000, false, 96096},
+		{"Backwards", "00:00:00:04", "00:00:00:00", 29.97, 48000, true, -8008},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate := FrameRateFromFloat(tt.fps)
+			first, err := NewTimecodeFromString(tt.first, rate)
+			require.NoError(t, err)
+			last, err := NewTimecodeFromString(tt.last, rate)
+			require.NoError(t, err)
+
+			var span *TimecodeSpan
+			if tt.signed {
+				span, err = NewSignedTimecodeSpan(first, last)
+			} else {
+				span, err = NewTimecodeSpan(first, last)
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, span.GetTotalSamples(tt.sampleRate))
+		})
+	}
+}
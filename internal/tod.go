@@ -0,0 +1,70 @@
+package internal
+
+import (
+	"time"
+)
+
+// Time of day timecode is jammed to the clock at midnight and then counts
+// frames. At 25, 24 or 30 fps it keeps the same time as the clock, but at
+// 29.97 the frames are 1.001 times longer than the labels say. Non drop frame
+// falls 3.6 seconds an hour behind the clock. Drop frame skips labels to keep
+// up, but still gets 86.4 ms (about 2.6 frames) ahead of the clock each day.
+
+// TimeOfDay returns how long after midnight the timecode is, in real time,
+// when it was jammed to 00:00:00:00 at midnight. It is the same as
+// GetDuration.
+func (t *Timecode) TimeOfDay() time.Duration {
+	return t.GetDuration()
+}
+
+// LabelTimeOfDay returns the time the timecode reads as, with each frame
+// label taken as 1/timebase of a second.
+func (t *Timecode) LabelTimeOfDay() time.Duration {
+	hours, minutes, seconds, frames := t.GetComponents()
+	n, d := t.framePosition()
+	label := time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute + time.Duration(seconds)*time.Second
+	return label + time.Duration(roundDiv((int64(frames)*d+n)*int64(time.Second), d*int64(t.FrameRate.Timebase())))
+}
+
+// TODDrift returns how far the timecode label is ahead of the clock, which
+// is negative when it is behind.
+func (t *Timecode) TODDrift() time.Duration {
+	return t.LabelTimeOfDay() - t.TimeOfDay()
+}
+
+// TODDriftPerDay returns how far time of day timecode gets ahead of the clock
+// in a day, which is negative when it falls behind. It is 0 at whole rates,
+// 86.4 ms at 29.97 drop frame and -86.4 s at 29.97 non drop frame.
+func (r FrameRate) TODDriftPerDay(dropFrame bool) time.Duration {
+	return 24*time.Hour - r.FramesToDuration(r.FramesPerDay(dropFrame))
+}
+
+// Time returns the clock time of the timecode on the given date in loc. Only
+// the year, month and day of date are used. The timecode is placed by the
+// clock, so on the day daylight saving starts or ends it is still the time
+// the clocks show.
+func (t *Timecode) Time(date time.Time, loc *time.Location) time.Time {
+	year, month, day := date.Date()
+	// time.Date normalizes the nanoseconds into the clock fields.
+	return time.Date(year, month, day, 0, 0, 0, int(t.TimeOfDay()), loc)
+}
+
+// NewTimecodeFromTime will create a Timecode for the frame that a clock time
+// is in, for timecode jammed at midnight. Drop frame timecode is ahead of the
+// clock, so the last few frames of the day are after 23:59:59;29 and wrap to
+// 00:00:00;00.
+func NewTimecodeFromTime(clock time.Time, frameRate FrameRate, isDropframe bool) (*Timecode, error) {
+	if isDropframe && !frameRate.SupportsDropFrame() {
+		return nil, NewError(ErrInvalidDropFrame, "%s is not a valid framerate for drop frame timecode", frameRate)
+	}
+	hours, minutes, seconds := clock.Clock()
+	secs := int64(hours*3600 + minutes*60 + seconds)
+
+	// Whole seconds and nanoseconds are done separately, as nanoseconds in a
+	// day times the rate would overflow.
+	frames, rem := floorDivmod(secs*frameRate.Num, frameRate.Den)
+	extra, _ := floorDivmod(rem*int64(time.Second)+int64(clock.Nanosecond())*frameRate.Num, frameRate.Den*int64(time.Second))
+	_, frames = floorDivmod(frames+extra, frameRate.FramesPerDay(isDropframe))
+
+	return NewTimecodeFromFrames(frames, frameRate, isDropframe)
+}
@@ -0,0 +1,94 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTODDriftPerDay(t *testing.T) {
+	tests := []struct {
+		fps       float64
+		dropFrame bool
+		expected  time.Duration
+	}{
+		{25, false, 0},
+		{30, false, 0},
+		{29.97, true, 86400 * time.Microsecond},
+		{29.97, false, -86400 * time.Millisecond},
+		{59.94, true, 86400 * time.Microsecond},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.expected, FrameRateFromFloat(tt.fps).TODDriftPerDay(tt.dropFrame), "%v fps", tt.fps)
+	}
+}
+
+func TestTimecodeTime(t *testing.T) {
+	tests := []struct {
+		name      string
+		timecode  string
+		fps       float64
+		expected  time.Duration
+		drift     time.Duration
+		expectErr bool
+	}{
+		{"25", "14:32:05:06", 25, 14*time.Hour + 32*time.Minute + 5240*time.Millisecond, 0, false},
+		{"29.97DF a minute in", "00:01:00;02", 29.97, 60060 * time.Millisecond, 6667 * time.Microsecond, false},
+		{"29.97NDF an hour in", "01:00:00:00", 29.97, 3603600 * time.Millisecond, -3600 * time.Millisecond, false},
+		{"Second field at 25", "01:00:00:00.1", 25, time.Hour + 20*time.Millisecond, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tc, err := NewTimecodeFromString(tt.timecode, FrameRateFromFloat(tt.fps))
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, tc.TimeOfDay())
+			require.InDelta(t, tt.drift, tc.TODDrift(), float64(time.Microsecond))
+
+			loc, err := time.LoadLocation("America/New_York")
+			require.NoError(t, err)
+			clock := tc.Time(time.Date(2024, time.July, 4, 0, 0, 0, 0, time.UTC), loc)
+			require.Equal(t, time.Date(2024, time.July, 4, 0, 0, 0, int(tt.expected), loc), clock)
+
+			back, err := NewTimecodeFromTime(clock, tc.FrameRate, tc.DropFrame)
+			require.NoError(t, err)
+			require.Equal(t, tc.GetFrameIdx(), back.GetFrameIdx())
+		})
+	}
+}
+
+func TestNewTimecodeFromTime(t *testing.T) {
+	tests := []struct {
+		name      string
+		clock     time.Time
+		fps       float64
+		dropFrame bool
+		expected  string
+	}{
+		{"25", time.Date(2024, time.May, 1, 14, 32, 5, 250e6, time.UTC), 25, false, "14:32:05:06"},
+		{"29.97DF is ahead of the clock", time.Date(2024, time.May, 1, 14, 32, 5, 250e6, time.UTC), 29.97, true, "14:32:05;09"},
+		{"29.97NDF is behind the clock", time.Date(2024, time.May, 1, 14, 32, 5, 250e6, time.UTC), 29.97, false, "14:31:12:29"},
+		{"119.88DF", time.Date(2024, time.May, 1, 23, 0, 0, 0, time.UTC), 119.88, true, "23:00:00;009"},
+		{"29.97DF wraps before midnight", time.Date(2024, time.May, 1, 23, 59, 59, 990e6, time.UTC), 29.97, true, "00:00:00;02"},
+		{"Daylight saving keeps the clock", time.Date(2024, time.March, 10, 4, 0, 0, 0, mustLoadLocation(t, "America/New_York")), 25, false, "04:00:00:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tc, err := NewTimecodeFromTime(tt.clock, FrameRateFromFloat(tt.fps), tt.dropFrame)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, tc.GetTimecode())
+		})
+	}
+
+	_, err := NewTimecodeFromTime(time.Now(), FrameRateFromFloat(25), true)
+	require.ErrorIs(t, err, ErrInvalidDropFrame)
+}
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	require.NoError(t, err)
+	return loc
+}
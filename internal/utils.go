@@ -2,20 +2,30 @@ package internal
 
 import (
 	"fmt"
-	"math"
 	"strconv"
 )
 
-// ParseStringToTimecode Will take a string that is either a timecode string or a frame count string
+// ParseStringToTimecode Will take a string that is either a timecode string, a frame count string,
+// feet+frames (ie 123+08, which needs a gauge) or hours, minutes and seconds of timecode (ie 1h2m3.5s, see unitsToFrames)
 // and return a bonefied timecode object. This is only used in the context of calculate
 // where we know if it's df or ndf. This dropframeness is ignored if it's a timecode string
 // excludeLastTimecode will only work for inputs that are a timecode string - not a frame count.
-func ParseStringToTimecode(in string, fps float64, excludeLastTimecode bool, dropFrame bool) (*Timecode, error) {
+func ParseStringToTimecode(in string, fps FrameRate, excludeLastTimecode bool, dropFrame bool, gauge FilmGauge) (*Timecode, error) {
 	frames, err := strconv.Atoi(in)
 	if err == nil {
 		frameIdx := int64(frames - 1)
 		return NewTimecodeFromFrames(frameIdx, fps, dropFrame)
 	}
+	if IsFootage(in) {
+		footage, err := ParseFootage(in, gauge)
+		if err != nil {
+			return nil, err
+		}
+		return NewTimecodeFromFrames(footage.GetFrameCount()-1, fps, dropFrame)
+	}
+	if d, ok := parseUnits(in); ok {
+		return NewTimecodeFromFrames(unitsToFrames(d, fps, dropFrame)-1, fps, dropFrame)
+	}
 	time, err := NewTimecodeFromString(in, fps)
 	if excludeLastTimecode && err == nil {
 		time.AddFrames(-1)
@@ -29,18 +39,49 @@ func divmod(numerator, denominator int64) (quotient, remainder int64) {
 	return
 }
 
-func formatTimecode(hours int64, minutes int64, seconds int64, frames int64, isDropframe bool) string {
+// floorDivmod is divmod rounding towards negative infinity, so the
+// remainder always has the sign of the denominator.
+func floorDivmod(numerator, denominator int64) (quotient, remainder int64) {
+	quotient, remainder = divmod(numerator, denominator)
+	if remainder != 0 && (remainder < 0) != (denominator < 0) {
+		quotient--
+		remainder += denominator
+	}
+	return
+}
+
+// ceilDiv divides and rounds up, towards positive infinity.
+func ceilDiv(numerator, denominator int64) int64 {
+	q, _ := floorDivmod(-numerator, denominator)
+	return -q
+}
+
+// formatTimecode will zero pad each field to two digits. Frames are padded
+// to three digits at rates above 99 fps, so every timecode at 100, 119.88 or
+// 120 is the same width.
+func formatTimecode(hours int64, minutes int64, seconds int64, frames int64, isDropframe bool, rate FrameRate) string {
 	delim := ":"
 	if isDropframe {
 		delim = ";"
 	}
-	return fmt.Sprintf("%02d:%02d:%02d%s%02d", hours, minutes, seconds, delim, frames)
+	frameDigits := 2
+	if rate.Timebase() > 99 {
+		frameDigits = 3
+	}
+	return fmt.Sprintf("%02d:%02d:%02d%s%0*d", hours, minutes, seconds, delim, frameDigits, frames)
 }
 
-func formatTimeSpan(hours int64, minutes int64, seconds int64, ms string) string {
-	return fmt.Sprintf("%02d:%02d:%02d.%s", hours, minutes, seconds, ms)
+// formatField adds a field indicator to a formatted timecode, ".0" for the
+// first field and ".1" for the second.
+func formatField(timecode string, field int) string {
+	return fmt.Sprintf("%s.%d", timecode, field)
 }
 
-func getTimeBase(framerate float64) int {
-	return int(math.Ceil(framerate))
+// formatSubframes adds two digits of subframes to a formatted timecode.
+func formatSubframes(timecode string, subframes int) string {
+	return fmt.Sprintf("%s.%02d", timecode, subframes)
+}
+
+func formatTimeSpan(hours int64, minutes int64, seconds int64, ms string) string {
+	return fmt.Sprintf("%02d:%02d:%02d.%s", hours, minutes, seconds, ms)
 }
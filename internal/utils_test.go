@@ -13,17 +13,23 @@ func TestParseStringToTimecode(t *testing.T) {
 		dropFrame           bool
 		expectedFrames      int
 		expectError         bool
+		gauge               FilmGauge
 	}{
-		{"Valid Frame Count", "300", 30.0, false, false, 300, false},
-		{"Frame Count With Exclude", "300", 30.0, true, false, 300, false},
-		{"Valid Timecode", "00:00:10:00", 30.0, false, false, 301, false},
-		{"Valid Timecode", "00:00:10:00", 30.0, true, false, 300, false},
-		{"Invalid Timecode", "invalid", 30.0, false, false, 0, true},
+		{"Valid Frame Count", "300", 30.0, false, false, 300, false, FilmGauge{}},
+		{"Frame Count With Exclude", "300", 30.0, true, false, 300, false, FilmGauge{}},
+		{"Valid Timecode", "00:00:10:00", 30.0, false, false, 301, false, FilmGauge{}},
+		{"Valid Timecode", "00:00:10:00", 30.0, true, false, 300, false, FilmGauge{}},
+		{"Invalid Timecode", "invalid", 30.0, false, false, 0, true, FilmGauge{}},
+		{"Feet and frames", "123+08", 24.0, false, false, 1976, false, Gauge35mm4Perf},
+		{"Feet and frames 16mm", "10+39", 24.0, false, false, 439, false, Gauge16mm},
+		{"Feet and frames without a gauge", "123+08", 24.0, false, false, 0, true, FilmGauge{}},
+		{"Go duration", "1m0.5s", 25.0, false, false, 1513, false, FilmGauge{}},
+		{"Go duration at 29.97 is timecode", "1h2m3.5s", 29.97, false, false, 111705, false, FilmGauge{}},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			tc, err := ParseStringToTimecode(tt.input, tt.fps, tt.excludeLastTimecode, tt.dropFrame)
+			tc, err := ParseStringToTimecode(tt.input, FrameRateFromFloat(tt.fps), tt.excludeLastTimecode, tt.dropFrame, tt.gauge)
 			if (err != nil) != tt.expectError {
 				t.Fatalf("Expected error: %v, got: %v", tt.expectError, err)
 			}
@@ -62,15 +68,19 @@ func TestFormatTimecode(t *testing.T) {
 		seconds     int64
 		frames      int64
 		isDropframe bool
+		fps         float64
 		expected    string
 	}{
-		{1, 2, 3, 4, false, "01:02:03:04"},
-		{10, 20, 30, 15, true, "10:20:30;15"},
-		{0, 0, 0, 0, false, "00:00:00:00"},
+		{1, 2, 3, 4, false, 25, "01:02:03:04"},
+		{10, 20, 30, 15, true, 29.97, "10:20:30;15"},
+		{0, 0, 0, 0, false, 24, "00:00:00:00"},
+		{0, 0, 1, 5, false, 120, "00:00:01:005"},
+		{0, 0, 1, 105, true, 119.88, "00:00:01;105"},
+		{0, 0, 1, 99, false, 100, "00:00:01:099"},
 	}
 
 	for _, c := range cases {
-		result := formatTimecode(c.hours, c.minutes, c.seconds, c.frames, c.isDropframe)
+		result := formatTimecode(c.hours, c.minutes, c.seconds, c.frames, c.isDropframe, FrameRateFromFloat(c.fps))
 		if result != c.expected {
 			t.Errorf("formatTimecode(%d, %d, %d, %d, %v) = %q; expected %q", c.hours, c.minutes, c.seconds, c.frames, c.isDropframe, result, c.expected)
 		}
@@ -97,20 +107,23 @@ func TestFormatTimeSpan(t *testing.T) {
 	}
 }
 
-func TestGetTimeBase(t *testing.T) {
+func TestFloorDivmod(t *testing.T) {
 	cases := []struct {
-		framerate float64
-		expected  int
+		numerator   int64
+		denominator int64
+		quotient    int64
+		remainder   int64
 	}{
-		{29.97, 30},
-		{59.94, 60},
-		{24.0, 24},
+		{10, 3, 3, 1},
+		{-10, 3, -4, 2},
+		{-3, 3, -1, 0},
+		{0, 3, 0, 0},
 	}
 
 	for _, c := range cases {
-		result := getTimeBase(c.framerate)
-		if result != c.expected {
-			t.Errorf("getTimeBase(%f) = %d; expected %d", c.framerate, result, c.expected)
+		q, r := floorDivmod(c.numerator, c.denominator)
+		if q != c.quotient || r != c.remainder {
+			t.Errorf("floorDivmod(%d, %d) = (%d, %d); expected (%d, %d)", c.numerator, c.denominator, q, r, c.quotient, c.remainder)
 		}
 	}
 }
@@ -0,0 +1,171 @@
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// WAV is the audio of a WAV file. Samples are per channel and scaled to
+// -1..1, whatever the bit depth of the file.
+type WAV struct {
+	SampleRate    int
+	BitsPerSample int
+	Samples       [][]float64
+}
+
+const (
+	wavFormatPCM        = 1
+	wavFormatFloat      = 3
+	wavFormatExtensible = 0xFFFE
+)
+
+// ReadWAV reads a PCM (8, 16, 24 or 32 bit) or float (32 or 64 bit) WAV file.
+func ReadWAV(r io.Reader) (*WAV, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return nil, NewError(ErrMalformed, "This is not a WAV file")
+	}
+
+	var (
+		format, channels, bits int
+		sampleRate             int
+		samples                []byte
+		haveFormat             bool
+	)
+	for pos := 12; pos+8 <= len(data); {
+		id := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4 : pos+8]))
+		start := pos + 8
+		end := start + size
+		if size < 0 || end > len(data) {
+			// A file that was cut short still has some audio in it.
+			end = len(data)
+		}
+		chunk := data[start:end]
+
+		switch id {
+		case "fmt ":
+			if len(chunk) < 16 {
+				return nil, NewError(ErrMalformed, "The fmt chunk of the WAV file is too short")
+			}
+			format = int(binary.LittleEndian.Uint16(chunk[0:2]))
+			channels = int(binary.LittleEndian.Uint16(chunk[2:4]))
+			sampleRate = int(binary.LittleEndian.Uint32(chunk[4:8]))
+			bits = int(binary.LittleEndian.Uint16(chunk[14:16]))
+			if format == wavFormatExtensible && len(chunk) >= 26 {
+				// The format is the start of the sub format GUID.
+				format = int(binary.LittleEndian.Uint16(chunk[24:26]))
+			}
+			haveFormat = true
+		case "data":
+			samples = chunk
+		}
+
+		// Chunks are padded to an even length.
+		pos = end + size%2
+	}
+
+	if !haveFormat {
+		return nil, NewError(ErrMalformed, "The WAV file has no fmt chunk")
+	}
+	if samples == nil {
+		return nil, NewError(ErrMalformed, "The WAV file has no data chunk")
+	}
+	if channels < 1 || sampleRate < 1 {
+		return nil, NewError(ErrMalformed, "The WAV file has %d channels at %d Hz", channels, sampleRate)
+	}
+
+	var decode func(b []byte) float64
+	switch {
+	case format == wavFormatPCM && bits == 8:
+		decode = func(b []byte) float64 { return (float64(b[0]) - 128) / 128 }
+	case format == wavFormatPCM && bits == 16:
+		decode = func(b []byte) float64 { return float64(int16(binary.LittleEndian.Uint16(b))) / (1 << 15) }
+	case format == wavFormatPCM && bits == 24:
+		decode = func(b []byte) float64 {
+			v := int32(uint32(b[0])<<8|uint32(b[1])<<16|uint32(b[2])<<24) >> 8
+			return float64(v) / (1 << 23)
+		}
+	case format == wavFormatPCM && bits == 32:
+		decode = func(b []byte) float64 { return float64(int32(binary.LittleEndian.Uint32(b))) / (1 << 31) }
+	case format == wavFormatFloat && bits == 32:
+		decode = func(b []byte) float64 { return float64(math.Float32frombits(binary.LittleEndian.Uint32(b))) }
+	case format == wavFormatFloat && bits == 64:
+		decode = func(b []byte) float64 { return math.Float64frombits(binary.LittleEndian.Uint64(b)) }
+	default:
+		return nil, NewError(ErrMalformed, "WAV files with format %d at %d bits are not supported. Use PCM or float", format, bits)
+	}
+
+	bytesPerSample := bits / 8
+	frameSize := bytesPerSample * channels
+	frames := len(samples) / frameSize
+
+	wav := &WAV{SampleRate: sampleRate, BitsPerSample: bits, Samples: make([][]float64, channels)}
+	for c := range wav.Samples {
+		wav.Samples[c] = make([]float64, frames)
+	}
+	for i := 0; i < frames; i++ {
+		for c := 0; c < channels; c++ {
+			offset := i*frameSize + c*bytesPerSample
+			wav.Samples[c][i] = decode(samples[offset : offset+bytesPerSample])
+		}
+	}
+	return wav, nil
+}
+
+// WriteWAV writes the audio as a PCM WAV file at 16 or 24 bits. Samples
+// outside of -1..1 are clipped.
+func WriteWAV(w io.Writer, wav *WAV) error {
+	bits := wav.BitsPerSample
+	if bits != 16 && bits != 24 {
+		return NewError(ErrInvalidOption, "WAV files can be written at 16 or 24 bits, not %d", bits)
+	}
+	channels := len(wav.Samples)
+	if channels == 0 {
+		return NewError(ErrMalformed, "There is no audio to write")
+	}
+	frames := len(wav.Samples[0])
+	bytesPerSample := bits / 8
+	dataSize := frames * channels * bytesPerSample
+
+	var header bytes.Buffer
+	header.WriteString("RIFF")
+	binary.Write(&header, binary.LittleEndian, uint32(36+dataSize))
+	header.WriteString("WAVEfmt ")
+	binary.Write(&header, binary.LittleEndian, uint32(16))
+	binary.Write(&header, binary.LittleEndian, uint16(wavFormatPCM))
+	binary.Write(&header, binary.LittleEndian, uint16(channels))
+	binary.Write(&header, binary.LittleEndian, uint32(wav.SampleRate))
+	binary.Write(&header, binary.LittleEndian, uint32(wav.SampleRate*channels*bytesPerSample))
+	binary.Write(&header, binary.LittleEndian, uint16(channels*bytesPerSample))
+	binary.Write(&header, binary.LittleEndian, uint16(bits))
+	header.WriteString("data")
+	binary.Write(&header, binary.LittleEndian, uint32(dataSize))
+
+	bw := bufio.NewWriter(w)
+	if _, err := bw.Write(header.Bytes()); err != nil {
+		return err
+	}
+
+	scale := float64(int64(1)<<(bits-1) - 1)
+	buf := make([]byte, 4)
+	for i := 0; i < frames; i++ {
+		for c := 0; c < channels; c++ {
+			v := math.Max(-1, math.Min(1, wav.Samples[c][i]))
+			binary.LittleEndian.PutUint32(buf, uint32(int32(math.Round(v*scale))))
+			if _, err := bw.Write(buf[:bytesPerSample]); err != nil {
+				return err
+			}
+		}
+	}
+	if dataSize%2 == 1 {
+		bw.WriteByte(0)
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,77 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// wavFile builds a WAV file from raw sample data.
+func wavFile(format, channels, sampleRate, bits int, data []byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, uint32(36+len(data)))
+	buf.WriteString("WAVEfmt ")
+	binary.Write(&buf, binary.LittleEndian, uint32(16))
+	binary.Write(&buf, binary.LittleEndian, uint16(format))
+	binary.Write(&buf, binary.LittleEndian, uint16(channels))
+	binary.Write(&buf, binary.LittleEndian, uint32(sampleRate))
+	binary.Write(&buf, binary.LittleEndian, uint32(sampleRate*channels*bits/8))
+	binary.Write(&buf, binary.LittleEndian, uint16(channels*bits/8))
+	binary.Write(&buf, binary.LittleEndian, uint16(bits))
+	buf.WriteString("data")
+	binary.Write(&buf, binary.LittleEndian, uint32(len(data)))
+	buf.Write(data)
+	return buf.Bytes()
+}
+
+func TestReadWAV(t *testing.T) {
+	tests := []struct {
+		name      string
+		file      []byte
+		expected  [][]float64
+		expectErr error
+	}{
+		{"8 bit", wavFile(1, 1, 8000, 8, []byte{128, 255, 0}), [][]float64{{0, 127.0 / 128, -1}}, nil},
+		{"16 bit stereo", wavFile(1, 2, 48000, 16, []byte{0x00, 0x40, 0x00, 0xC0}), [][]float64{{0.5}, {-0.5}}, nil},
+		{"24 bit", wavFile(1, 1, 48000, 24, []byte{0x00, 0x00, 0xC0}), [][]float64{{-0.5}}, nil},
+		{"32 bit float", wavFile(3, 1, 48000, 32, []byte{0x00, 0x00, 0x80, 0x3E}), [][]float64{{0.25}}, nil},
+		{"Not a WAV", []byte("RIFF....AVI LIST"), nil, ErrMalformed},
+		{"Unsupported format", wavFile(2, 1, 48000, 4, []byte{0}), nil, ErrMalformed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wav, err := ReadWAV(bytes.NewReader(tt.file))
+			if tt.expectErr != nil {
+				require.ErrorIs(t, err, tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, wav.Samples)
+		})
+	}
+}
+
+func TestWriteWAV(t *testing.T) {
+	for _, bits := range []int{16, 24} {
+		in := &WAV{SampleRate: 44100, BitsPerSample: bits, Samples: [][]float64{{0, 0.5, -0.5, 2}, {1, -1, 0.25, -2}}}
+		var buf bytes.Buffer
+		require.NoError(t, WriteWAV(&buf, in))
+
+		out, err := ReadWAV(&buf)
+		require.NoError(t, err)
+		require.Equal(t, 44100, out.SampleRate)
+		require.Equal(t, bits, out.BitsPerSample)
+		require.Len(t, out.Samples, 2)
+		// Clipped to -1..1, and within a step of the bit depth.
+		for c, channel := range [][]float64{{0, 0.5, -0.5, 1}, {1, -1, 0.25, -1}} {
+			require.InDeltaSlice(t, channel, out.Samples[c], 1/float64(int(1)<<(bits-2)))
+		}
+	}
+
+	err := WriteWAV(&bytes.Buffer{}, &WAV{SampleRate: 48000, BitsPerSample: 8, Samples: [][]float64{{0}}})
+	require.ErrorIs(t, err, ErrInvalidOption)
+}
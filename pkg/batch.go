@@ -0,0 +1,269 @@
+package timecodetool
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/marcrleonard/TimecodeTool/internal"
+)
+
+// Batch input formats.
+const (
+	BatchFormatAuto = "auto"
+	BatchFormatCSV  = "csv"
+	BatchFormatJSON = "jsonl"
+)
+
+// BatchJob is one row of a batch. Args are the same as the positional
+// arguments of the command on the CLI.
+//
+// As JSON Lines: {"command":"span","fps":"25","args":["01:00:00:00","01:00:10:00"]}
+//
+// As CSV: command,fps,args...  ie span,25,01:00:00:00,01:00:10:00
+type BatchJob struct {
+	Command             string   `json:"command"` // validate, span or calculate
+	Fps                 string   `json:"fps,omitempty"`
+	Args                []string `json:"args"`
+	ExcludeLastTimecode bool     `json:"excludeLastTimecode,omitempty"`
+	Signed              bool     `json:"signed,omitempty"` // span only
+}
+
+// BatchOptions are the defaults for every job of a batch.
+type BatchOptions struct {
+	Format string // auto, csv or jsonl
+	// Fps is used for jobs that don't have one.
+	Fps string
+	// ExcludeLastTimecode is set for every job when true.
+	ExcludeLastTimecode bool
+}
+
+// BatchError is written in place of a response when a row can't be run at
+// all, ie an unknown command or the wrong number of args.
+type BatchError struct {
+	Line      int    `json:"line"`
+	Command   string `json:"command"`
+	Valid     bool   `json:"valid"`
+	ErrorMsg  string `json:"errorMsg"`
+	ErrorCode string `json:"errorCode"`
+}
+
+// BatchSummary is written as the last line of a batch, as {"summary": {...}}.
+type BatchSummary struct {
+	Jobs   int `json:"jobs"`
+	Valid  int `json:"valid"`
+	Failed int `json:"failed"`
+}
+
+// RunBatch reads jobs from r and writes one JSON response per line to w, in
+// the same order as the jobs. A job that fails doesn't stop the batch. The
+// last line is the summary. An error is only returned if r can't be read
+// or w can't be written to.
+func RunBatch(r io.Reader, w io.Writer, options BatchOptions) (*BatchSummary, error) {
+	br := bufio.NewReader(r)
+
+	format := strings.ToLower(options.Format)
+	if format == "" || format == BatchFormatAuto {
+		format = detectBatchFormat(br)
+	}
+
+	var next func() (job *BatchJob, line int, err error)
+	switch format {
+	case BatchFormatCSV:
+		next = csvBatchJobs(br)
+	case BatchFormatJSON:
+		next = jsonBatchJobs(br)
+	default:
+		return nil, internal.NewError(internal.ErrInvalidOption, "%s is not a valid batch format. Use csv or jsonl", options.Format)
+	}
+
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	summary := &BatchSummary{}
+
+	for {
+		job, line, err := next()
+		if err == io.EOF {
+			break
+		}
+		var ioErr *batchReadError
+		if errors.As(err, &ioErr) {
+			return summary, ioErr.err
+		}
+
+		var resp any
+		valid := false
+		if err == nil {
+			resp, valid, err = runBatchJob(job, options)
+		}
+		if err != nil {
+			command := ""
+			if job != nil {
+				command = job.Command
+			}
+			resp = &BatchError{Line: line, Command: command, ErrorMsg: err.Error(), ErrorCode: errorCode(err)}
+		}
+
+		summary.Jobs++
+		if valid {
+			summary.Valid++
+		} else {
+			summary.Failed++
+		}
+
+		if err := enc.Encode(resp); err != nil {
+			return summary, err
+		}
+		// Flush every line so the output can be streamed.
+		if err := bw.Flush(); err != nil {
+			return summary, err
+		}
+	}
+
+	if err := enc.Encode(struct {
+		Summary *BatchSummary `json:"summary"`
+	}{summary}); err != nil {
+		return summary, err
+	}
+	return summary, bw.Flush()
+}
+
+func runBatchJob(job *BatchJob, options BatchOptions) (resp any, valid bool, err error) {
+	fps := job.Fps
+	if fps == "" {
+		fps = options.Fps
+	}
+	exclude := job.ExcludeLastTimecode || options.ExcludeLastTimecode
+
+	switch strings.ToLower(job.Command) {
+	case "validate":
+		if len(job.Args) != 1 {
+			return nil, false, internal.NewError(internal.ErrMalformed, "validate takes 1 timecode, got %d args", len(job.Args))
+		}
+		r := NewValidateTimecode(job.Args[0], fps)
+		return r, r.Valid, nil
+	case "span":
+		if len(job.Args) != 2 {
+			return nil, false, internal.NewError(internal.ErrMalformed, "span takes 2 timecodes, got %d args", len(job.Args))
+		}
+		var r *SpanResponse
+		if job.Signed {
+			r = NewSignedSpanTimecode(job.Args[0], job.Args[1], fps, exclude)
+		} else {
+			r = NewSpanTimecode(job.Args[0], job.Args[1], fps, exclude)
+		}
+		return r, r.Valid, nil
+	case "calculate":
+		if len(job.Args) < 2 {
+			return nil, false, internal.NewError(internal.ErrMalformed, "calculate takes at least 2 args, got %d", len(job.Args))
+		}
+		r := NewCalculateTimecodes(job.Args[0], job.Args[1:], fps, exclude)
+		return r, r.Valid, nil
+	default:
+		return nil, false, internal.NewError(internal.ErrInvalidOption, "%s is not a valid batch command. Use validate, span or calculate", job.Command)
+	}
+}
+
+// batchReadError is a failure to read the input, as opposed to a bad row.
+type batchReadError struct {
+	err error
+}
+
+func (e *batchReadError) Error() string {
+	return e.err.Error()
+}
+
+// detectBatchFormat peeks at the input. JSON Lines start with "{".
+func detectBatchFormat(br *bufio.Reader) string {
+	for n := 1; ; n++ {
+		peek, err := br.Peek(n)
+		if len(peek) < n {
+			return BatchFormatCSV
+		}
+		trimmed := bytes.TrimLeft(peek, " \t\r\n")
+		if len(trimmed) > 0 {
+			if trimmed[0] == '{' {
+				return BatchFormatJSON
+			}
+			return BatchFormatCSV
+		}
+		if err != nil {
+			return BatchFormatCSV
+		}
+	}
+}
+
+func jsonBatchJobs(br *bufio.Reader) func() (*BatchJob, int, error) {
+	scanner := bufio.NewScanner(br)
+	line := 0
+	return func() (*BatchJob, int, error) {
+		for scanner.Scan() {
+			line++
+			text := strings.TrimSpace(scanner.Text())
+			if text == "" {
+				continue
+			}
+			job := &BatchJob{}
+			if err := json.Unmarshal([]byte(text), job); err != nil {
+				return nil, line, internal.NewError(internal.ErrMalformed, "line %d is not a valid job: %s", line, err)
+			}
+			return job, line, nil
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, line, &batchReadError{err}
+		}
+		return nil, line, io.EOF
+	}
+}
+
+func csvBatchJobs(br *bufio.Reader) func() (*BatchJob, int, error) {
+	reader := csv.NewReader(br)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+	first := true
+
+	return func() (*BatchJob, int, error) {
+		for {
+			record, err := reader.Read()
+			if err == io.EOF {
+				return nil, 0, io.EOF
+			}
+			if err != nil {
+				var parseErr *csv.ParseError
+				if errors.As(err, &parseErr) {
+					return nil, parseErr.Line, internal.NewError(internal.ErrMalformed, "line %d is not valid CSV: %s", parseErr.Line, parseErr.Err)
+				}
+				return nil, 0, &batchReadError{err}
+			}
+			line, _ := reader.FieldPos(0)
+
+			// Spreadsheets pad rows out with empty columns.
+			for len(record) > 0 && strings.TrimSpace(record[len(record)-1]) == "" {
+				record = record[:len(record)-1]
+			}
+			if len(record) == 0 {
+				continue
+			}
+			for i := range record {
+				record[i] = strings.TrimSpace(record[i])
+			}
+
+			// An optional header row.
+			isHeader := first && strings.EqualFold(record[0], "command")
+			first = false
+			if isHeader {
+				continue
+			}
+
+			if len(record) < 2 {
+				return &BatchJob{Command: record[0]}, line, internal.NewError(internal.ErrMalformed, "line %d needs at least a command and fps column", line)
+			}
+			return &BatchJob{Command: record[0], Fps: record[1], Args: record[2:]}, line, nil
+		}
+	}
+}
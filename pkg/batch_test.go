@@ -0,0 +1,126 @@
+package timecodetool
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// batchLine is the fields of a batch output line that every response, error
+// row and summary line can be told apart by.
+type batchLine struct {
+	Line          int           `json:"line"`
+	Command       string        `json:"command"`
+	Valid         bool          `json:"valid"`
+	ErrorCode     string        `json:"errorCode"`
+	LengthFrames  int           `json:"lengthFrames"`
+	FrameRate     string        `json:"frameRate"`
+	Summary       *BatchSummary `json:"summary"`
+	InputTimecode string        `json:"inputTimecode"`
+}
+
+func TestRunBatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		options  BatchOptions
+		expected []batchLine
+		summary  BatchSummary
+	}{
+		{
+			"CSV with a header",
+			"command,fps,first,last\nspan,25,01:00:00:00,01:00:10:00\nvalidate,,01:00:00:00\n",
+			BatchOptions{Fps: "24"},
+			[]batchLine{
+				{Valid: true, LengthFrames: 251, FrameRate: "25/1"},
+				{Valid: true, FrameRate: "24/1", InputTimecode: "01:00:00:00"},
+			},
+			BatchSummary{Jobs: 2, Valid: 2},
+		},
+		{
+			"CSV without a header",
+			"span,25,01:00:00:00,01:00:10:00\n",
+			BatchOptions{ExcludeLastTimecode: true},
+			[]batchLine{{Valid: true, LengthFrames: 250, FrameRate: "25/1"}},
+			BatchSummary{Jobs: 1, Valid: 1},
+		},
+		{
+			"Ragged CSV rows",
+			"# from a spreadsheet\nvalidate,25,01:00:00:00,,,\n,,,,\nspan , 25 , 01:00:00:00 , 01:00:00:24\n",
+			BatchOptions{},
+			[]batchLine{
+				{Valid: true, FrameRate: "25/1", InputTimecode: "01:00:00:00"},
+				{Valid: true, LengthFrames: 25, FrameRate: "25/1"},
+			},
+			BatchSummary{Jobs: 2, Valid: 2},
+		},
+		{
+			"JSON lines",
+			"\n  {\"command\":\"span\",\"fps\":\"25\",\"args\":[\"01:00:00:00\",\"01:00:10:00\"]}\n\n" +
+				"{\"command\":\"calculate\",\"args\":[\"01:00:00:00\",\"+\",\"10\"]}\n",
+			BatchOptions{Fps: "24"},
+			[]batchLine{
+				{Valid: true, LengthFrames: 251, FrameRate: "25/1"},
+				{Valid: true, LengthFrames: 11, FrameRate: "24/1"},
+			},
+			BatchSummary{Jobs: 2, Valid: 2},
+		},
+		{
+			"Invalid rows become error rows",
+			"span,25,01:00:00:00\nrender,25,01:00:00:00\nvalidate\nvalidate,25,01:00:00:30\n",
+			BatchOptions{},
+			[]batchLine{
+				{Line: 1, Command: "span", ErrorCode: ErrorCodeMalformed},
+				{Line: 2, Command: "render", ErrorCode: ErrorCodeInvalidOption},
+				{Line: 3, Command: "validate", ErrorCode: ErrorCodeMalformed},
+				{FrameRate: "25/1", ErrorCode: ErrorCodeFrameOutOfRange, InputTimecode: "01:00:00:30"},
+			},
+			BatchSummary{Jobs: 4, Failed: 4},
+		},
+		{
+			"Invalid JSON line",
+			"{\"command\":\"validate\",\"fps\":\"25\",\"args\":[\"01:00:00:00\"]}\n{\"command\":\n",
+			BatchOptions{},
+			[]batchLine{
+				{Valid: true, FrameRate: "25/1", InputTimecode: "01:00:00:00"},
+				{Line: 2, ErrorCode: ErrorCodeMalformed},
+			},
+			BatchSummary{Jobs: 2, Valid: 1, Failed: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			summary, err := RunBatch(strings.NewReader(tt.input), &out, tt.options)
+			require.NoError(t, err)
+			require.Equal(t, tt.summary, *summary)
+
+			lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+			require.Len(t, lines, len(tt.expected)+1)
+			for i, expected := range tt.expected {
+				var got batchLine
+				require.NoError(t, json.Unmarshal([]byte(lines[i]), &got), lines[i])
+				require.Equal(t, expected, got, lines[i])
+			}
+
+			var last batchLine
+			require.NoError(t, json.Unmarshal([]byte(lines[len(lines)-1]), &last))
+			require.Equal(t, &tt.summary, last.Summary)
+		})
+	}
+}
+
+func TestRunBatchFormat(t *testing.T) {
+	var out strings.Builder
+	_, err := RunBatch(strings.NewReader("validate,25,01:00:00:00\n"), &out, BatchOptions{Format: "xml"})
+	require.ErrorIs(t, err, ErrInvalidOption)
+
+	// A forced format reads the input as that format, even if it looks like
+	// the other one.
+	summary, err := RunBatch(strings.NewReader("{\"command\":\"validate\"}\n"), &out, BatchOptions{Format: BatchFormatCSV})
+	require.NoError(t, err)
+	require.Equal(t, BatchSummary{Jobs: 1, Failed: 1}, *summary)
+}
@@ -0,0 +1,53 @@
+package timecodetool
+
+import (
+	"errors"
+
+	"github.com/marcrleonard/TimecodeTool/internal"
+)
+
+// These are the errors behind a failed response. Check the Err field of a
+// response against them with errors.Is.
+var (
+	ErrMalformed        = internal.ErrMalformed
+	ErrInvalidDropFrame = internal.ErrInvalidDropFrame
+	ErrFrameOutOfRange  = internal.ErrFrameOutOfRange
+	ErrUnsupportedRate  = internal.ErrUnsupportedRate
+	ErrInvalidOption    = internal.ErrInvalidOption
+	ErrEmptySpan        = internal.ErrEmptySpan
+)
+
+// Error codes set in the errorCode field of a failed response. Unlike the
+// error message, these will not change between versions.
+const (
+	ErrorCodeMalformed        = "malformed"
+	ErrorCodeInvalidDropFrame = "invalid_drop_frame"
+	ErrorCodeFrameOutOfRange  = "frame_out_of_range"
+	ErrorCodeUnsupportedRate  = "unsupported_rate"
+	ErrorCodeInvalidOption    = "invalid_option"
+	ErrorCodeEmptySpan        = "empty_span"
+	ErrorCodeUnknown          = "unknown"
+)
+
+// errorCodes is checked in order, so when several errors are joined
+// together the code is that of the first one listed here.
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{ErrMalformed, ErrorCodeMalformed},
+	{ErrUnsupportedRate, ErrorCodeUnsupportedRate},
+	{ErrInvalidOption, ErrorCodeInvalidOption},
+	{ErrFrameOutOfRange, ErrorCodeFrameOutOfRange},
+	{ErrInvalidDropFrame, ErrorCodeInvalidDropFrame},
+	{ErrEmptySpan, ErrorCodeEmptySpan},
+}
+
+func errorCode(err error) string {
+	for _, c := range errorCodes {
+		if errors.Is(err, c.err) {
+			return c.code
+		}
+	}
+	return ErrorCodeUnknown
+}
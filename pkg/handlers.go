@@ -1,46 +1,142 @@
 package timecodetool
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/marcrleonard/TimecodeTool/internal"
 )
 
-func NewValidateTimecode(startTc string, fps float64) *ValidateResponse {
+// newTimecode parses a timecode string at the given rate. If the framerate
+// string asked for drop frame (ie "29.97DF") the timecode is treated as
+// drop frame regardless of its delimiter.
+func newTimecode(tc string, rate internal.FrameRate, dropFrame bool) (*internal.Timecode, error) {
+	t, err := internal.NewTimecodeFromString(tc, rate)
+	if err != nil {
+		return nil, err
+	}
+	if dropFrame {
+		t.DropFrame = true
+	}
+	return t, nil
+}
+
+func NewValidateTimecode(startTc string, fps string) *ValidateResponse {
+
+	rate, df, err := internal.ParseFrameRate(fps)
+	if err != nil {
+		return newFailedValidateResponse(startTc, fps, "", false, err)
+	}
 
-	firstTc, err := internal.NewTimecodeFromString(startTc, fps)
+	firstTc, err := newTimecode(startTc, rate, df)
 	if err != nil {
-		return newFailedValidateResponse(startTc, fps, false, err.Error())
+		return newFailedValidateResponse(startTc, fps, rate.Rational(), false, err)
 	}
 	if err := firstTc.Validate(); err != nil {
-		return newFailedValidateResponse(startTc, fps, firstTc.DropFrame, err.Error())
+		return newFailedValidateResponse(startTc, fps, rate.Rational(), firstTc.DropFrame, err)
 	}
 
-	nextFrame, _ := internal.NewTimecodeFromString(startTc, fps)
-	nextFrame.AddFrames(1)
+	nextFrame, err := newTimecode(startTc, rate, df)
+	if err != nil {
+		return newFailedValidateResponse(startTc, fps, rate.Rational(), firstTc.DropFrame, err)
+	}
+	// A field timecode is followed by the next field.
+	if nextFrame.HasField() {
+		nextFrame.AddFields(1)
+	} else {
+		nextFrame.AddFrames(1)
+	}
+
+	resp := newOkValidateResponse(startTc, fps, rate.Rational(), firstTc.DropFrame, firstTc.GetFrameIdx(), nextFrame.GetTimecode())
+	if firstTc.HasField() {
+		fieldIdx := firstTc.GetFieldIdx()
+		resp.FieldIdx = &fieldIdx
+	}
+	// Rates above 30 fps don't have a time address. The second field sets
+	// the field mark.
+	flags := internal.SMPTEFlags{Field: firstTc.HasField() && firstTc.GetField() == 1}
+	if word, err := firstTc.PackSMPTE(flags); err == nil {
+		resp.SmpteWord = fmt.Sprintf("%08X", word)
+	}
+	return resp
 
-	return newOkValidateResponse(startTc, fps, firstTc.DropFrame, firstTc.GetFrameIdx(), nextFrame.GetTimecode())
+}
 
+// NewSpanTimecode gets the duration information spanning two timecodes. If the
+// last timecode is before the first timecode, the span crosses midnight.
+func NewSpanTimecode(startTc string, endTc string, fps string, excludeLastTimecode bool) *SpanResponse {
+	return NewSpan(startTc, endTc, fps, SpanOptions{ExcludeLastTimecode: excludeLastTimecode})
 }
 
-func NewSpanTimecode(startTc string, endTc string, fps float64, excludeLastTimecode bool) *SpanResponse {
+// NewSignedSpanTimecode is the same as NewSpanTimecode, except a last timecode
+// that is before the first timecode gives a negative span.
+func NewSignedSpanTimecode(startTc string, endTc string, fps string, excludeLastTimecode bool) *SpanResponse {
+	return NewSpan(startTc, endTc, fps, SpanOptions{ExcludeLastTimecode: excludeLastTimecode, Signed: true})
+}
+
+// SpanOptions are the options of NewSpan.
+type SpanOptions struct {
+	ExcludeLastTimecode bool
+	// Signed gives a negative span when the last timecode is before the
+	// first timecode, rather than crossing midnight.
+	Signed bool
+	// FilmGauge adds the length in feet+frames, ie "35mm-4perf".
+	FilmGauge string
+	// Fields adds the length in fields, for interlaced material.
+	Fields bool
+	// SampleRate adds the length in audio samples at the sample rate, ie 48000.
+	SampleRate int
+}
+
+// NewSpan is NewSpanTimecode with all of the options.
+func NewSpan(startTc string, endTc string, fps string, options SpanOptions) *SpanResponse {
+	excludeLastTimecode := options.ExcludeLastTimecode
+	signed := options.Signed
+
+	rate, df, err := internal.ParseFrameRate(fps)
+	if err != nil {
+		return newFailedSpanResponse(startTc, endTc, fps, "", excludeLastTimecode, err)
+	}
+	gauge, err := parseFilmGauge(options.FilmGauge)
+	if err != nil {
+		return newFailedSpanResponse(startTc, endTc, fps, rate.Rational(), excludeLastTimecode, err)
+	}
+	if options.SampleRate < 0 {
+		return newFailedSpanResponse(startTc, endTc, fps, rate.Rational(), excludeLastTimecode, internal.NewError(internal.ErrInvalidOption, "The sample rate must be above 0"))
+	}
 
 	var allErrors []error
 
-	firstTc, err := internal.NewTimecodeFromString(startTc, fps)
+	firstTc, err := newTimecode(startTc, rate, df)
 	if err != nil {
 		allErrors = append(allErrors, fmt.Errorf("First timecode error: %w", err))
 	}
-	lastTimecode, err := internal.NewTimecodeFromString(endTc, fps)
+	lastTimecode, err := newTimecode(endTc, rate, df)
 	if err != nil {
 		allErrors = append(allErrors, fmt.Errorf("Last timecode error: %w", err))
 	}
+	if firstTc == nil || lastTimecode == nil {
+		return newFailedSpanResponse(startTc, endTc, fps, rate.Rational(), excludeLastTimecode, errors.Join(allErrors...))
+	}
+
 	if excludeLastTimecode {
-		if firstTc.GetFrameIdx() == lastTimecode.GetFrameIdx() {
-			allErrors = append(allErrors, errors.New("This is span has no frames in it."))
+		// The last timecode moves one frame back towards the first timecode,
+		// which is forwards for a negative span. A last timecode with a
+		// field indicator moves one field.
+		step := lastTimecode.AddFrames
+		if lastTimecode.HasField() {
+			step = lastTimecode.AddFields
+		}
+		if firstTc.GetFieldIdx() == lastTimecode.GetFieldIdx() {
+			allErrors = append(allErrors, internal.NewError(internal.ErrEmptySpan, "This is span has no frames in it."))
+		} else if signed && lastTimecode.GetFieldIdx() < firstTc.GetFieldIdx() {
+			step(1)
 		} else {
-			lastTimecode.AddFrames(-1)
+			step(-1)
 		}
 
 	}
@@ -54,24 +150,34 @@ func NewSpanTimecode(startTc string, endTc string, fps float64, excludeLastTimec
 	}
 
 	if len(allErrors) > 0 {
-		return newFailedSpanResponse(startTc, endTc, fps, excludeLastTimecode, errors.Join(allErrors...).Error())
+		return newFailedSpanResponse(startTc, endTc, fps, rate.Rational(), excludeLastTimecode, errors.Join(allErrors...))
 	}
 
-	span, err := internal.NewTimecodeSpan(firstTc, lastTimecode)
+	var span *internal.TimecodeSpan
+	if signed {
+		span, err = internal.NewSignedTimecodeSpan(firstTc, lastTimecode)
+	} else {
+		span, err = internal.NewTimecodeSpan(firstTc, lastTimecode)
+	}
 	if err != nil {
-		return newFailedSpanResponse(startTc, endTc, fps, excludeLastTimecode, err.Error())
+		return newFailedSpanResponse(startTc, endTc, fps, rate.Rational(), excludeLastTimecode, err)
 	}
 
-	nextTimecode, err := internal.NewTimecodeFromString(endTc, fps)
+	nextTimecode, err := newTimecode(endTc, rate, df)
 	if err != nil {
-		panic("Error generating next timecode")
+		return newFailedSpanResponse(startTc, endTc, fps, rate.Rational(), excludeLastTimecode, err)
+	}
+	if nextTimecode.HasField() {
+		nextTimecode.AddFields(1)
+	} else {
+		nextTimecode.AddFrames(1)
 	}
-	nextTimecode.AddFrames(1)
 
-	return newOkSpanResponse(
+	resp := newOkSpanResponse(
 		startTc,
 		endTc,
 		fps,
+		rate.Rational(),
 		span.Dropframe,
 		excludeLastTimecode,
 		span.StartTimecode.GetFrameIdx(),
@@ -80,71 +186,1400 @@ func NewSpanTimecode(startTc string, endTc string, fps float64, excludeLastTimec
 		span.GetSpanRealtime(),
 		span.GetSpanTimecode(),
 		span.GetTotalSeconds(),
+		span.CrossesMidnight(),
 		nextTimecode.GetTimecode(),
 	)
+	setFeetFrames(resp, gauge)
+	if options.Fields {
+		resp.LengthFields = span.GetTotalFields()
+	}
+	if options.SampleRate > 0 {
+		resp.SampleRate = options.SampleRate
+		resp.LengthSamples = span.GetTotalSamples(options.SampleRate)
+	}
+	return resp
 }
 
-func NewCalculateTimecodes(inTc string, operations []string, fps float64, excludeLastTimecode bool) *CalcResponse {
-	firstTc, _ := internal.NewTimecodeFromString(inTc, fps)
-	lastTimecode, _ := internal.NewTimecodeFromString(inTc, fps)
-	curIdx := 0
-
-	calcSteps := []CalculationStep{}
+// parseFilmGauge returns the zero gauge when no gauge was asked for.
+func parseFilmGauge(in string) (internal.FilmGauge, error) {
+	if in == "" {
+		return internal.FilmGauge{}, nil
+	}
+	return internal.ParseFilmGauge(in)
+}
 
-	for {
-		if curIdx >= len(operations)-1 {
-			break
-		}
+// setFeetFrames adds the length in feet+frames when there is a gauge.
+func setFeetFrames(resp *SpanResponse, gauge internal.FilmGauge) {
+	if gauge.IsZero() {
+		return
+	}
+	footage, err := internal.NewFootageFromFrames(int64(resp.LengthFrames), gauge)
+	if err != nil {
+		return
+	}
+	resp.FilmGauge = gauge.String()
+	resp.LengthFeetFrames = footage.String()
+}
 
-		opperator := operations[curIdx]
+func NewCalculateTimecodes(inTc string, operations []string, fps string, excludeLastTimecode bool) *CalcResponse {
+	return NewCalculate(inTc, operations, fps, CalculateOptions{ExcludeLastTimecode: excludeLastTimecode})
+}
 
-		nextTime := operations[curIdx+1]
+// CalculateOptions are the options of NewCalculate.
+type CalculateOptions struct {
+	ExcludeLastTimecode bool
+	// FilmGauge allows feet+frames (ie 123+08) in the operations, and adds
+	// the length in feet+frames.
+	FilmGauge string
+}
 
-		nexTc, err := internal.ParseStringToTimecode(nextTime, fps, excludeLastTimecode, firstTc.DropFrame)
+// NewCalculate is NewCalculateTimecodes with all of the options.
+func NewCalculate(inTc string, operations []string, fps string, options CalculateOptions) *CalcResponse {
+	excludeLastTimecode := options.ExcludeLastTimecode
 
-		if err != nil {
-			return newFailedCalcResponse(
-				inTc,
-				"",
-				fps,
-				excludeLastTimecode,
-				err.Error(),
-				[]CalculationStep{},
-			)
-		}
+	rate, df, err := internal.ParseFrameRate(fps)
+	if err != nil {
+		return newFailedCalcResponse(inTc, "", fps, "", excludeLastTimecode, err, []CalculationStep{})
+	}
+	gauge, err := parseFilmGauge(options.FilmGauge)
+	if err != nil {
+		return newFailedCalcResponse(inTc, "", fps, rate.Rational(), excludeLastTimecode, err, []CalculationStep{})
+	}
 
-		switch opperator {
-		case "-":
-			lastTimecode.AddFrames(int(nexTc.GetFrameCount()) * -1)
-		case "+":
-			lastTimecode.AddFrames(int(nexTc.GetFrameCount()))
-		}
+	firstTc, err := newTimecode(inTc, rate, df)
+	if err != nil {
+		return newFailedCalcResponse(inTc, "", fps, rate.Rational(), excludeLastTimecode, err, []CalculationStep{})
+	}
+	if err := firstTc.Validate(); err != nil {
+		return newFailedCalcResponse(inTc, "", fps, rate.Rational(), excludeLastTimecode, err, []CalculationStep{})
+	}
+	// The operations are one expression, ie ["+", "(00:00:30:00", "*", "4)"]
+	// is the same as ["+ (00:00:30:00 * 4)"].
+	expression := strings.TrimSpace(strings.Join(operations, " "))
+	if expression != "" && expression[0] != '+' && expression[0] != '-' {
+		err := internal.NewError(internal.ErrMalformed, "The calculation must start with + or -, ie + 00:00:10:00")
+		return newFailedCalcResponse(inTc, "", fps, rate.Rational(), excludeLastTimecode, err, []CalculationStep{})
+	}
+	expr, err := internal.ParseExpression(expression)
+	if err != nil {
+		return newFailedCalcResponse(inTc, "", fps, rate.Rational(), excludeLastTimecode, err, []CalculationStep{})
+	}
 
+	// offset is kept as a plain frame count, so results can be negative or
+	// longer than a day.
+	offset, steps, err := expr.Evaluate(rate, firstTc.DropFrame, excludeLastTimecode, gauge)
+	calcSteps := []CalculationStep{}
+	for _, step := range steps {
 		calcSteps = append(calcSteps, CalculationStep{
-			Operation: opperator,
-			Timecode:  nexTc.GetTimecode(), // fix this
-			Frames:    int(nexTc.GetFrameCount()),
+			Operation:      step.Operation,
+			Expression:     step.Expression,
+			Timecode:       step.Timecode,
+			Frames:         int(step.Frames),
+			Scalar:         step.Scalar,
+			Result:         int(step.Result),
+			ResultTimecode: step.ResultTimecode,
 		})
+	}
+	if err != nil {
+		return newFailedCalcResponse(inTc, "", fps, rate.Rational(), excludeLastTimecode, err, calcSteps)
+	}
+
+	result, err := internal.NewTimecodeSpanFromOffset(firstTc, offset)
+	if err != nil {
+		return newFailedCalcResponse(inTc, "", fps, rate.Rational(), excludeLastTimecode, err, calcSteps)
+	}
+	lastTimecode := result.LastTimecode
 
-		curIdx += 2
+	// Same as span, the last timecode moves one frame back towards the first timecode.
+	spanOffset := offset
+	if excludeLastTimecode {
+		switch {
+		case offset > 0:
+			spanOffset--
+		case offset < 0:
+			spanOffset++
+		default:
+			return newFailedCalcResponse(inTc, "", fps, rate.Rational(), excludeLastTimecode, internal.NewError(internal.ErrEmptySpan, "This is span has no frames in it."), calcSteps)
+		}
+	}
+	span, err := internal.NewTimecodeSpanFromOffset(firstTc, spanOffset)
+	if err != nil {
+		return newFailedCalcResponse(inTc, "", fps, rate.Rational(), excludeLastTimecode, err, calcSteps)
 	}
 
-	span := NewSpanTimecode(inTc, lastTimecode.GetTimecode(), fps, excludeLastTimecode)
+	nextTimecode, err := internal.NewTimecodeFromFrames(int64(lastTimecode.GetFrameIdx()), rate, lastTimecode.DropFrame)
+	if err != nil {
+		return newFailedCalcResponse(inTc, "", fps, rate.Rational(), excludeLastTimecode, err, calcSteps)
+	}
+	nextTimecode.AddFrames(1)
 
-	return newOkCalcResponse(
+	resp := newOkCalcResponse(
 		firstTc.GetTimecode(),
 		lastTimecode.GetTimecode(),
 		fps,
+		rate.Rational(),
 		firstTc.DropFrame,
 		excludeLastTimecode,
 		firstTc.GetFrameIdx(),
 		lastTimecode.GetFrameIdx(),
-		span.LengthFrames,
-		span.LengthTime,
-		span.LengthTimecode,
-		span.LengthSeconds,
-		span.NextTimecode,
+		span.GetTotalFrames(),
+		span.GetSpanRealtime(),
+		span.GetSpanTimecode(),
+		span.GetTotalSeconds(),
+		span.CrossesMidnight(),
+		nextTimecode.GetTimecode(),
 		calcSteps,
 	)
+	setFeetFrames(&resp.SpanResponse, gauge)
+	return resp
+}
+
+// NewConvertTimecode will convert a timecode, or a span when endTc is not empty,
+// from fps to targetFps. The target is drop frame only when targetFps asks for
+// it (ie "29.97DF"). See internal.ConvertStrategies for the valid strategies.
+func NewConvertTimecode(startTc string, endTc string, fps string, targetFps string, strategy string) *ConvertResponse {
+
+	fail := func(err error) *ConvertResponse {
+		return newFailedConvertResponse(startTc, endTc, fps, targetFps, strategy, err)
+	}
+
+	rate, df, err := internal.ParseFrameRate(fps)
+	if err != nil {
+		return fail(err)
+	}
+	targetRate, targetDf, err := internal.ParseFrameRate(targetFps)
+	if err != nil {
+		return fail(fmt.Errorf("Target framerate error: %w", err))
+	}
+	convertStrategy, err := internal.ParseConvertStrategy(strategy)
+	if err != nil {
+		return fail(err)
+	}
+
+	firstTc, err := newTimecode(startTc, rate, df)
+	if err != nil {
+		return fail(err)
+	}
+	if err := firstTc.Validate(); err != nil {
+		return fail(err)
+	}
+	first, err := internal.ConvertTimecode(firstTc, targetRate, targetDf, convertStrategy)
+	if err != nil {
+		return fail(err)
+	}
+
+	resp := &ConvertResponse{
+		InputTimecode:        startTc,
+		InputLastTimecode:    endTc,
+		InputFps:             fps,
+		FrameRate:            rate.Rational(),
+		TargetFps:            targetFps,
+		TargetFrameRate:      targetRate.Rational(),
+		Strategy:             string(convertStrategy),
+		Valid:                true,
+		IsDf:                 firstTc.DropFrame,
+		TargetIsDf:           targetDf,
+		FrameIdx:             firstTc.GetFrameIdx(),
+		Timecode:             first.Timecode.GetTimecode(),
+		TargetFrameIdx:       first.Timecode.GetFrameIdx(),
+		RoundingErrorSeconds: first.ErrorSeconds,
+		RoundingErrorFrames:  first.ErrorFrames,
+	}
+
+	if endTc == "" {
+		return resp
+	}
+
+	lastTc, err := newTimecode(endTc, rate, df)
+	if err != nil {
+		return fail(fmt.Errorf("Last timecode error: %w", err))
+	}
+	if err := lastTc.Validate(); err != nil {
+		return fail(fmt.Errorf("Last timecode error: %w", err))
+	}
+	last, err := internal.ConvertTimecode(lastTc, targetRate, targetDf, convertStrategy)
+	if err != nil {
+		return fail(err)
+	}
+
+	span, err := internal.NewTimecodeSpan(firstTc, lastTc)
+	if err != nil {
+		return fail(err)
+	}
+	targetSpan, err := internal.NewTimecodeSpan(first.Timecode, last.Timecode)
+	if err != nil {
+		return fail(err)
+	}
+
+	resp.LastFrameIdx = lastTc.GetFrameIdx()
+	resp.LastTimecode = last.Timecode.GetTimecode()
+	resp.TargetLastFrameIdx = last.Timecode.GetFrameIdx()
+	resp.LastRoundingErrorSeconds = last.ErrorSeconds
+	resp.LastRoundingErrorFrames = last.ErrorFrames
+	resp.LengthFrames = span.GetTotalFrames()
+	resp.TargetLengthFrames = targetSpan.GetTotalFrames()
+
+	return resp
+}
+
+// NewFixTimecode will attempt to repair a broken timecode. The response lists
+// everything that was wrong with the input along with the corrected timecode.
+// See internal.RepairPolicies for the valid policies.
+func NewFixTimecode(inputTc string, fps string, policy string) *FixResponse {
+
+	rate, df, err := internal.ParseFrameRate(fps)
+	if err != nil {
+		return newFailedFixResponse(inputTc, fps, "", policy, err)
+	}
+	repairPolicy, err := internal.ParseRepairPolicy(policy)
+	if err != nil {
+		return newFailedFixResponse(inputTc, fps, rate.Rational(), policy, err)
+	}
+
+	repair, err := internal.RepairTimecode(inputTc, rate, df, repairPolicy)
+	if err != nil {
+		return newFailedFixResponse(inputTc, fps, rate.Rational(), policy, err)
+	}
+
+	issues := []FixIssue{}
+	for _, issue := range repair.Issues {
+		issues = append(issues, FixIssue{Kind: string(issue.Kind), Message: issue.Message})
+	}
+
+	return newOkFixResponse(
+		inputTc,
+		fps,
+		rate.Rational(),
+		string(repairPolicy),
+		issues,
+		repair.Timecode.GetTimecode(),
+		repair.Timecode.DropFrame,
+		repair.Timecode.GetFrameIdx(),
+	)
+}
+
+// NewEdlAnalysis will parse a CMX3600 EDL and report the length of each event,
+// the total record length and any gaps or overlaps on the record side. EDLs
+// don't carry their frame rate, so fps has to be given.
+func NewEdlAnalysis(edl string, fps string) *EdlResponse {
+
+	rate, df, err := internal.ParseFrameRate(fps)
+	if err != nil {
+		return newFailedEdlResponse(fps, "", err)
+	}
+
+	fail := func(err error) *EdlResponse {
+		return newFailedEdlResponse(fps, rate.Rational(), err)
+	}
+
+	parsed, err := internal.ParseEDL(strings.NewReader(edl), rate, df)
+	if err != nil {
+		return fail(err)
+	}
+
+	resp := &EdlResponse{
+		InputFps:   fps,
+		FrameRate:  rate.Rational(),
+		Valid:      true,
+		Title:      parsed.Title,
+		IsDf:       parsed.DropFrame,
+		Comments:   append([]string{}, parsed.Comments...),
+		EventCount: len(parsed.Events),
+		Events:     []EdlEvent{},
+		Gaps:       []EdlDiscontinuity{},
+		Overlaps:   []EdlDiscontinuity{},
+	}
+
+	for _, event := range parsed.Events {
+		e := EdlEvent{
+			Number:           event.Number,
+			Reel:             event.Reel,
+			Track:            event.Track,
+			Transition:       event.Transition,
+			TransitionFrames: event.TransitionFrames,
+			ClipName:         event.ClipName,
+			IsDf:             event.DropFrame,
+			SourceIn:         event.SourceIn.GetTimecode(),
+			SourceOut:        event.SourceOut.GetTimecode(),
+			RecordIn:         event.RecordIn.GetTimecode(),
+			RecordOut:        event.RecordOut.GetTimecode(),
+			LengthTime:       "00:00:00.000",
+			Comments:         append([]string{}, event.Comments...),
+		}
+
+		sourceSpan, err := event.SourceSpan()
+		if err != nil {
+			return fail(err)
+		}
+		if sourceSpan != nil {
+			e.SourceLengthFrames = sourceSpan.GetTotalFrames()
+		}
+
+		recordSpan, err := event.RecordSpan()
+		if err != nil {
+			return fail(err)
+		}
+		if recordSpan == nil {
+			// A zero length event, such as the outgoing side of a dissolve.
+			zero, err := internal.NewTimecodeFromFrames(0, rate, event.DropFrame)
+			if err != nil {
+				return fail(err)
+			}
+			e.LengthTimecode = zero.GetTimecode()
+		} else {
+			e.LengthFrames = recordSpan.GetTotalFrames()
+			e.LengthTime = recordSpan.GetSpanRealtime()
+			e.LengthTimecode = recordSpan.GetSpanTimecode()
+			e.LengthSeconds = recordSpan.GetTotalSeconds()
+		}
+
+		if event.Speed != nil {
+			e.SpeedChange = &EdlSpeedChange{
+				Reel:          event.Speed.Reel,
+				Speed:         event.Speed.Speed,
+				Percent:       event.Speed.Speed / rate.Float64() * 100,
+				EntryTimecode: event.Speed.EntryTimecode.GetTimecode(),
+			}
+		}
+
+		resp.Events = append(resp.Events, e)
+	}
+
+	total, err := parsed.RecordSpan()
+	if err != nil {
+		return fail(err)
+	}
+	if total != nil {
+		resp.RecordStartTimecode = total.StartTimecode.GetTimecode()
+		end, err := internal.NewTimecodeFromFrames(int64(total.LastTimecode.GetFrameIdx())+1, rate, total.Dropframe)
+		if err != nil {
+			return fail(err)
+		}
+		resp.RecordEndTimecode = end.GetTimecode()
+		resp.LengthFrames = total.GetTotalFrames()
+		resp.LengthTime = total.GetSpanRealtime()
+		resp.LengthTimecode = total.GetSpanTimecode()
+		resp.LengthSeconds = total.GetTotalSeconds()
+	}
+
+	gaps, overlaps, err := parsed.Discontinuities()
+	if err != nil {
+		return fail(err)
+	}
+	for _, g := range gaps {
+		resp.Gaps = append(resp.Gaps, newEdlDiscontinuity(g))
+	}
+	for _, o := range overlaps {
+		resp.Overlaps = append(resp.Overlaps, newEdlDiscontinuity(o))
+	}
+
+	return resp
+}
+
+func newEdlDiscontinuity(d internal.EDLDiscontinuity) EdlDiscontinuity {
+	return EdlDiscontinuity{
+		Track:          d.Track,
+		AfterEvent:     d.After,
+		BeforeEvent:    d.Before,
+		StartTimecode:  d.Span.StartTimecode.GetTimecode(),
+		LastTimecode:   d.Span.LastTimecode.GetTimecode(),
+		LengthFrames:   d.Span.GetTotalFrames(),
+		LengthTimecode: d.Span.GetSpanTimecode(),
+	}
+}
+
+// EdlConformOptions are the operations applied by NewEdlConform. They are
+// applied in the order they are listed here, so Offset and SplitAt are at
+// the target frame rate.
+type EdlConformOptions struct {
+	// TargetFps converts the EDL to another frame rate, or between drop frame
+	// and non drop frame (ie "29.97NDF"), when it is set.
+	TargetFps string
+	// Strategy is the conversion strategy, "realtime" when empty.
+	Strategy string
+	// Offset moves every record timecode by a timecode or a frame count.
+	// Prefix it with "-" to move back.
+	Offset string
+	// SplitAt splits the EDL in two at a record timecode.
+	SplitAt string
+	// Renumber numbers the events of each EDL from 1.
+	Renumber bool
+}
+
+// NewEdlConform will merge the given CMX3600 EDLs (sorted by record in) and
+// apply the conform options to them. The result is one EDL, or two when
+// SplitAt is set.
+func NewEdlConform(edls []string, fps string, options EdlConformOptions) *EdlConformResponse {
+
+	fail := func(err error) *EdlConformResponse {
+		return newFailedEdlConformResponse(fps, options, err)
+	}
+
+	rate, df, err := internal.ParseFrameRate(fps)
+	if err != nil {
+		return fail(err)
+	}
+
+	var parsed []*internal.EDL
+	for i, edl := range edls {
+		p, err := internal.ParseEDL(strings.NewReader(edl), rate, df)
+		if err != nil {
+			if len(edls) > 1 {
+				err = fmt.Errorf("EDL %d: %w", i+1, err)
+			}
+			return fail(err)
+		}
+		parsed = append(parsed, p)
+	}
+	edl, err := internal.MergeEDLs(parsed...)
+	if err != nil {
+		return fail(err)
+	}
 
+	targetRate, targetDf := rate, edl.DropFrame
+	if options.TargetFps != "" {
+		if targetRate, targetDf, err = internal.ParseFrameRate(options.TargetFps); err != nil {
+			return fail(fmt.Errorf("Target framerate error: %w", err))
+		}
+		strategy := options.Strategy
+		if strategy == "" {
+			strategy = string(internal.ConvertRealtimeNearest)
+		}
+		convertStrategy, err := internal.ParseConvertStrategy(strategy)
+		if err != nil {
+			return fail(err)
+		}
+		if err := edl.ConvertEDL(targetRate, targetDf, convertStrategy); err != nil {
+			return fail(err)
+		}
+	}
+
+	var offset int64
+	if options.Offset != "" {
+		if offset, err = parseOffset(options.Offset, targetRate, targetDf); err != nil {
+			return fail(fmt.Errorf("Offset error: %w", err))
+		}
+		edl.Offset(offset)
+	}
+
+	results := []*internal.EDL{edl}
+	if options.SplitAt != "" {
+		at, err := newTimecode(options.SplitAt, targetRate, targetDf)
+		if err != nil {
+			return fail(fmt.Errorf("Split timecode error: %w", err))
+		}
+		if err := at.Validate(); err != nil {
+			return fail(fmt.Errorf("Split timecode error: %w", err))
+		}
+		before, after, err := internal.SplitEDL(edl, at)
+		if err != nil {
+			return fail(err)
+		}
+		results = []*internal.EDL{before, after}
+	}
+
+	resp := &EdlConformResponse{
+		InputFps:        fps,
+		FrameRate:       rate.Rational(),
+		TargetFps:       options.TargetFps,
+		TargetFrameRate: targetRate.Rational(),
+		Strategy:        options.Strategy,
+		Offset:          options.Offset,
+		OffsetFrames:    int(offset),
+		SplitAt:         options.SplitAt,
+		Renumber:        options.Renumber,
+		Valid:           true,
+		Edls:            []EdlConformed{},
+	}
+
+	for _, result := range results {
+		if options.Renumber {
+			result.Renumber(1)
+		}
+
+		var text strings.Builder
+		if err := result.WriteEDL(&text); err != nil {
+			return fail(err)
+		}
+
+		conformed := EdlConformed{
+			Title:      result.Title,
+			IsDf:       result.DropFrame,
+			EventCount: len(result.Events),
+			Edl:        text.String(),
+		}
+		span, err := result.RecordSpan()
+		if err != nil {
+			return fail(err)
+		}
+		if span != nil {
+			end, err := internal.NewTimecodeFromFrames(int64(span.LastTimecode.GetFrameIdx())+1, targetRate, span.Dropframe)
+			if err != nil {
+				return fail(err)
+			}
+			conformed.RecordStartTimecode = span.StartTimecode.GetTimecode()
+			conformed.RecordEndTimecode = end.GetTimecode()
+		}
+		resp.Edls = append(resp.Edls, conformed)
+	}
+
+	return resp
+}
+
+// parseOffset reads a timecode or a frame count as a number of frames. A
+// leading "-" makes it negative.
+func parseOffset(in string, rate internal.FrameRate, dropFrame bool) (int64, error) {
+	s := strings.TrimSpace(in)
+	sign := int64(1)
+	if rest, ok := strings.CutPrefix(s, "-"); ok {
+		sign = -1
+		s = rest
+	} else {
+		s = strings.TrimPrefix(s, "+")
+	}
+
+	if frames, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return sign * frames, nil
+	}
+
+	tc, err := newTimecode(s, rate, dropFrame)
+	if err != nil {
+		return 0, err
+	}
+	if err := tc.Validate(); err != nil {
+		return 0, err
+	}
+	return sign * int64(tc.GetFrameIdx()), nil
+}
+
+// CaptionsRetimeOptions are the operations applied by NewCaptionsRetime.
+// They are applied in the order they are listed here, so Offset is at the
+// target frame rate.
+type CaptionsRetimeOptions struct {
+	// Format is srt, vtt or scc. It is worked out from the file when empty.
+	Format string
+	// TargetFps converts the captions to another frame rate, or between drop
+	// frame and non drop frame (ie "29.97NDF"), when it is set.
+	TargetFps string
+	// Strategy is the conversion strategy, "frames" when empty, which keeps
+	// each cue on the same frame of video.
+	Strategy string
+	// Offset moves every cue by a Go duration (ie "-1.5s"), a timecode or a
+	// frame count. Prefix it with "-" to move back.
+	Offset string
+}
+
+// NewCaptionsRetime will retime an SRT, WebVTT or SCC file and report every
+// cue along with any cues that overlap or aren't on screen for a frame. SRT
+// and WebVTT are timed in milliseconds, so fps is the rate of the video they
+// go with. SCC is timed in 29.97 timecode.
+func NewCaptionsRetime(captions string, fps string, options CaptionsRetimeOptions) *CaptionsRetimeResponse {
+
+	fail := func(err error) *CaptionsRetimeResponse {
+		return newFailedCaptionsRetimeResponse(fps, options, err)
+	}
+
+	rate, df, err := internal.ParseFrameRate(fps)
+	if err != nil {
+		return fail(err)
+	}
+
+	var format internal.CaptionFormat
+	if options.Format != "" {
+		if format, err = internal.ParseCaptionFormat(options.Format); err != nil {
+			return fail(err)
+		}
+	}
+	parsed, err := internal.ParseCaptions(strings.NewReader(captions), format, rate, df)
+	if err != nil {
+		return fail(err)
+	}
+
+	targetRate, targetDf := rate, parsed.DropFrame
+	if options.TargetFps != "" {
+		if targetRate, targetDf, err = internal.ParseFrameRate(options.TargetFps); err != nil {
+			return fail(fmt.Errorf("Target framerate error: %w", err))
+		}
+		strategy := options.Strategy
+		if strategy == "" {
+			strategy = string(internal.ConvertFrameCount)
+		}
+		convertStrategy, err := internal.ParseConvertStrategy(strategy)
+		if err != nil {
+			return fail(err)
+		}
+		if err := parsed.ConvertCaptions(targetRate, targetDf, convertStrategy); err != nil {
+			return fail(err)
+		}
+	}
+
+	var offset time.Duration
+	if options.Offset != "" {
+		if offset, err = parseCaptionOffset(options.Offset, targetRate, targetDf); err != nil {
+			return fail(fmt.Errorf("Offset error: %w", err))
+		}
+		if err := parsed.Offset(offset); err != nil {
+			return fail(err)
+		}
+	}
+
+	var text strings.Builder
+	if err := parsed.WriteCaptions(&text); err != nil {
+		return fail(err)
+	}
+
+	resp := &CaptionsRetimeResponse{
+		InputFps:        fps,
+		FrameRate:       rate.Rational(),
+		TargetFps:       options.TargetFps,
+		TargetFrameRate: targetRate.Rational(),
+		Strategy:        options.Strategy,
+		Offset:          options.Offset,
+		OffsetSeconds:   offset.Seconds(),
+		Valid:           true,
+		Format:          string(parsed.Format),
+		IsDf:            parsed.DropFrame,
+		CueCount:        len(parsed.Cues),
+		Cues:            []CaptionCue{},
+		Issues:          []CaptionIssue{},
+		Captions:        text.String(),
+	}
+
+	for i, cue := range parsed.Cues {
+		start, end := parsed.CueTimes(cue)
+		in, out, err := parsed.CueTimecodes(cue)
+		if err != nil {
+			return fail(err)
+		}
+		c := CaptionCue{
+			Number:        i + 1,
+			Id:            cue.ID,
+			StartTime:     parsed.FormatTime(start),
+			EndTime:       parsed.FormatTime(end),
+			StartTimecode: in.GetTimecode(),
+			EndTimecode:   out.GetTimecode(),
+			StartSeconds:  start.Seconds(),
+			EndSeconds:    end.Seconds(),
+			Text:          cue.Text,
+		}
+		span, err := parsed.CueSpan(cue)
+		if err != nil {
+			return fail(err)
+		}
+		if span != nil {
+			c.LengthFrames = span.GetTotalFrames()
+		}
+		resp.Cues = append(resp.Cues, c)
+	}
+
+	issues, err := parsed.Issues()
+	if err != nil {
+		return fail(err)
+	}
+	for _, issue := range issues {
+		i := CaptionIssue{
+			Kind:     string(issue.Kind),
+			Cue:      issue.Cue,
+			OtherCue: issue.Other,
+			Message:  issue.Message,
+		}
+		if issue.Span != nil {
+			i.StartTimecode = issue.Span.StartTimecode.GetTimecode()
+			i.LastTimecode = issue.Span.LastTimecode.GetTimecode()
+			i.LengthFrames = issue.Span.GetTotalFrames()
+			i.LengthTimecode = issue.Span.GetSpanTimecode()
+		}
+		resp.Issues = append(resp.Issues, i)
+	}
+
+	return resp
+}
+
+// parseCaptionOffset reads a Go duration (ie "-1.5s"), a timecode or a frame
+// count as real time.
+func parseCaptionOffset(in string, rate internal.FrameRate, dropFrame bool) (time.Duration, error) {
+	if d, err := time.ParseDuration(strings.TrimSpace(in)); err == nil {
+		return d, nil
+	}
+	frames, err := parseOffset(in, rate, dropFrame)
+	if err != nil {
+		return 0, err
+	}
+	return rate.FramesToDuration(frames), nil
+}
+
+// NewFcpxmlAnalysis will parse an FCPXML document and list the clips of each
+// project's sequence with their record and source timecodes. FCPXML carries
+// its frame rates, so no fps is needed. Clips with an edit that isn't on a
+// frame boundary are flagged.
+func NewFcpxmlAnalysis(fcpxml string) *FcpxmlResponse {
+
+	parsed, err := internal.ParseFCPXML(strings.NewReader(fcpxml))
+	if err != nil {
+		return newFailedFcpxmlResponse(err)
+	}
+
+	resp := &FcpxmlResponse{
+		Valid:         true,
+		Version:       parsed.Version,
+		SequenceCount: len(parsed.Sequences),
+		Sequences:     []FcpxmlSequence{},
+	}
+
+	for _, seq := range parsed.Sequences {
+		s, err := newFcpxmlSequence(seq)
+		if err != nil {
+			return newFailedFcpxmlResponse(fmt.Errorf("sequence %q: %w", seq.Name, err))
+		}
+		resp.Sequences = append(resp.Sequences, s)
+	}
+
+	return resp
+}
+
+func newFcpxmlSequence(seq *internal.FCPXMLSequence) (FcpxmlSequence, error) {
+	s := FcpxmlSequence{
+		Name:      seq.Name,
+		FrameRate: seq.FrameRate.Rational(),
+		IsDf:      seq.DropFrame,
+		ClipCount: len(seq.Clips),
+		Clips:     []FcpxmlClip{},
+	}
+
+	start, err := seq.Timecode(seq.TCStart)
+	if err != nil {
+		return s, err
+	}
+	end, err := seq.Timecode(seq.TCStart.Add(seq.Duration))
+	if err != nil {
+		return s, err
+	}
+	s.StartTimecode = start.GetTimecode()
+	s.EndTimecode = end.GetTimecode()
+
+	span, err := seq.Span()
+	if err != nil {
+		return s, err
+	}
+	zero, err := internal.NewTimecodeFromFrames(0, seq.FrameRate, seq.DropFrame)
+	if err != nil {
+		return s, err
+	}
+	s.LengthTimecode = zero.GetTimecode()
+	if span != nil {
+		s.LengthFrames = span.GetTotalFrames()
+		s.LengthTimecode = span.GetSpanTimecode()
+		s.LengthSeconds = span.GetTotalSeconds()
+	}
+
+	for _, clip := range seq.Clips {
+		c := FcpxmlClip{
+			Kind:            clip.Kind,
+			Name:            clip.Name,
+			Lane:            clip.Lane,
+			SourceFrameRate: clip.SourceRate.Rational(),
+			SourceIsDf:      clip.SourceDropFrame,
+			RecordInTime:    clip.RecordIn.String(),
+			RecordOutTime:   clip.RecordOut.String(),
+			LengthTimecode:  zero.GetTimecode(),
+			UnalignedEdits:  []string{},
+		}
+		if clip.Asset != nil {
+			c.Asset = clip.Asset.Name
+		}
+
+		recordIn, recordOut, err := seq.RecordTimecodes(clip)
+		if err != nil {
+			return s, fmt.Errorf("%s %q: %w", clip.Kind, clip.Name, err)
+		}
+		c.RecordIn = recordIn.GetTimecode()
+		c.RecordOut = recordOut.GetTimecode()
+
+		sourceIn, sourceOut, err := clip.SourceTimecodes()
+		if err != nil {
+			return s, fmt.Errorf("%s %q: %w", clip.Kind, clip.Name, err)
+		}
+		c.SourceIn = sourceIn.GetTimecode()
+		c.SourceOut = sourceOut.GetTimecode()
+
+		recordSpan, err := seq.RecordSpan(clip)
+		if err != nil {
+			return s, fmt.Errorf("%s %q: %w", clip.Kind, clip.Name, err)
+		}
+		if recordSpan != nil {
+			c.LengthFrames = recordSpan.GetTotalFrames()
+			c.LengthTimecode = recordSpan.GetSpanTimecode()
+			c.LengthSeconds = recordSpan.GetTotalSeconds()
+		}
+
+		c.UnalignedEdits = append(c.UnalignedEdits, seq.UnalignedEdits(clip)...)
+		c.FrameAligned = len(c.UnalignedEdits) == 0
+		if !c.FrameAligned {
+			s.UnalignedCount++
+		}
+
+		s.Clips = append(s.Clips, c)
+	}
+
+	return s, nil
+}
+
+// NewOtioAnalysis will read an OpenTimelineIO timeline and report the source
+// and record span of each clip, and the record span of each transition. The
+// timecode is at the timeline's rate unless fps is given, ie "29.97DF" for
+// drop frame. Clips and timelines at another rate are reported in
+// RateMismatches, and their record times are rescaled to the nearest frame.
+func NewOtioAnalysis(otio string, fps string) *OtioResponse {
+
+	timeline, err := internal.ParseOTIO(strings.NewReader(otio))
+	if err != nil {
+		return newFailedOtioResponse(fps, "", err)
+	}
+
+	timelineRate, err := timeline.FrameRate()
+	if err != nil {
+		return newFailedOtioResponse(fps, "", err)
+	}
+	rate, df := timelineRate, false
+	if fps != "" {
+		if rate, df, err = internal.ParseFrameRate(fps); err != nil {
+			return newFailedOtioResponse(fps, "", err)
+		}
+	}
+
+	fail := func(err error) *OtioResponse {
+		return newFailedOtioResponse(fps, rate.Rational(), err)
+	}
+
+	resp := &OtioResponse{
+		InputFps:       fps,
+		FrameRate:      rate.Rational(),
+		Valid:          true,
+		Name:           timeline.Name,
+		IsDf:           df,
+		TrackCount:     len(timeline.Tracks),
+		Clips:          []OtioClip{},
+		Transitions:    []OtioTransition{},
+		RateMismatches: []string{},
+	}
+
+	if timelineRate != rate {
+		resp.RateMismatches = append(resp.RateMismatches, fmt.Sprintf("Timeline %q is at %s fps, not %s fps", timeline.Name, timelineRate, rate))
+	}
+
+	start := internal.RationalTime{Rate: rate.Float64()}
+	if timeline.GlobalStartTime != nil {
+		start = *timeline.GlobalStartTime
+	}
+	resp.GlobalStartTime = OtioRationalTime{Value: start.Value, Rate: start.Rate}
+	startTc, err := start.RescaledTo(rate.Float64()).Timecode(rate, df)
+	if err != nil {
+		return fail(err)
+	}
+	resp.StartTimecode = startTc.GetTimecode()
+
+	for _, track := range timeline.Tracks {
+		for _, item := range track.Items {
+			recordSpan, err := timeline.RecordSpan(item, rate, df)
+			if err != nil {
+				return fail(fmt.Errorf("Track %q: %s %q: %w", track.Name, item.Kind, item.Name, err))
+			}
+
+			switch item.Kind {
+			case "Clip":
+				sourceRate := item.SourceRange.StartTime.FrameRate()
+				sourceSpan, err := item.SourceSpan(df && sourceRate == rate)
+				if err != nil {
+					return fail(fmt.Errorf("Track %q: %s %q: %w", track.Name, item.Kind, item.Name, err))
+				}
+
+				c := OtioClip{
+					Track:           track.Name,
+					TrackKind:       track.Kind,
+					Name:            item.Name,
+					MediaUrl:        item.MediaURL,
+					SourceStart:     OtioRationalTime{Value: item.SourceRange.StartTime.Value, Rate: item.SourceRange.StartTime.Rate},
+					SourceDuration:  OtioRationalTime{Value: item.SourceRange.Duration.Value, Rate: item.SourceRange.Duration.Rate},
+					SourceFrameRate: sourceRate.Rational(),
+					SourceSpan:      newOtioSpanResponse(sourceSpan),
+					RecordSpan:      newOtioSpanResponse(recordSpan),
+					RateMismatch:    item.RateMismatch(timelineRate),
+				}
+				if c.RateMismatch != "" {
+					resp.RateMismatches = append(resp.RateMismatches, c.RateMismatch)
+				}
+				resp.Clips = append(resp.Clips, c)
+
+			case "Transition":
+				resp.Transitions = append(resp.Transitions, OtioTransition{
+					Track:          track.Name,
+					Name:           item.Name,
+					TransitionType: item.TransitionType,
+					RecordSpan:     newOtioSpanResponse(recordSpan),
+				})
+			}
+		}
+	}
+	resp.ClipCount = len(resp.Clips)
+
+	return resp
+}
+
+// newOtioSpanResponse is the span JSON of a clip's span, as though the first
+// and last timecodes were given to the span command. It is nil for a clip
+// with no frames.
+func newOtioSpanResponse(span *internal.TimecodeSpan) *SpanResponse {
+	if span == nil {
+		return nil
+	}
+
+	fps := span.StartTimecode.FrameRate.String()
+	if span.Dropframe {
+		fps += "DF"
+	}
+	next := *span.LastTimecode
+	next.AddFrames(1)
+
+	return newOkSpanResponse(
+		span.StartTimecode.GetTimecode(),
+		span.LastTimecode.GetTimecode(),
+		fps,
+		span.StartTimecode.FrameRate.Rational(),
+		span.Dropframe,
+		false,
+		span.StartTimecode.GetFrameIdx(),
+		span.LastTimecode.GetFrameIdx(),
+		span.GetTotalFrames(),
+		span.GetSpanRealtime(),
+		span.GetSpanTimecode(),
+		span.GetTotalSeconds(),
+		span.CrossesMidnight(),
+		next.GetTimecode(),
+	)
+}
+
+// NewLtcDecode will decode the LTC on one channel (counting from 1) of a WAV
+// file. LTC doesn't carry its frame rate, so fps has to be given. Drop frame
+// comes from the LTC itself.
+func NewLtcDecode(wav []byte, fps string, channel int) *LtcDecodeResponse {
+
+	rate, _, err := internal.ParseFrameRate(fps)
+	if err != nil {
+		return newFailedLtcDecodeResponse(fps, "", channel, err)
+	}
+
+	fail := func(err error) *LtcDecodeResponse {
+		return newFailedLtcDecodeResponse(fps, rate.Rational(), channel, err)
+	}
+
+	audio, err := internal.ReadWAV(bytes.NewReader(wav))
+	if err != nil {
+		return fail(err)
+	}
+	if channel < 1 || channel > len(audio.Samples) {
+		return fail(internal.NewError(internal.ErrInvalidOption, "Channel %d doesn't exist, the WAV file has %d channels", channel, len(audio.Samples)))
+	}
+
+	frames, err := internal.DecodeLTC(audio.Samples[channel-1], audio.SampleRate, rate)
+	if err != nil {
+		return fail(err)
+	}
+
+	resp := &LtcDecodeResponse{
+		InputFps:   fps,
+		FrameRate:  rate.Rational(),
+		Valid:      true,
+		SampleRate: audio.SampleRate,
+		Channel:    channel,
+		Channels:   len(audio.Samples),
+		FrameCount: len(frames),
+		Frames:     []LtcFrame{},
+	}
+
+	forward, reverse := 0, 0
+	for i, frame := range frames {
+		if frame.Reverse {
+			reverse++
+		} else {
+			forward++
+		}
+		if i > 0 {
+			expected := *frames[i-1].Timecode
+			if frame.Reverse {
+				expected.AddFrames(-1)
+			} else {
+				expected.AddFrames(1)
+			}
+			if expected.GetFrameIdx() != frame.Timecode.GetFrameIdx() {
+				resp.Discontinuities++
+			}
+		}
+
+		resp.Frames = append(resp.Frames, LtcFrame{
+			Timecode:         frame.Timecode.GetTimecode(),
+			FrameIdx:         frame.Timecode.GetFrameIdx(),
+			IsDf:             frame.Timecode.DropFrame,
+			ColorFrame:       frame.ColorFrame,
+			UserBits:         frame.UserBits.String(),
+			BinaryGroupFlags: int(frame.BinaryGroupFlags),
+			Reverse:          frame.Reverse,
+			Sample:           int(frame.Sample),
+			Seconds:          float64(frame.Sample) / float64(audio.SampleRate),
+		})
+	}
+
+	switch {
+	case forward > 0 && reverse > 0:
+		resp.Direction = "mixed"
+	case forward > 0:
+		resp.Direction = "forward"
+	case reverse > 0:
+		resp.Direction = "reverse"
+	}
+	if len(frames) > 0 {
+		resp.FirstTimecode = resp.Frames[0].Timecode
+		resp.LastTimecode = resp.Frames[len(resp.Frames)-1].Timecode
+	}
+
+	return resp
+}
+
+// LtcEncodeOptions are the settings of the LTC rendered by NewLtcEncode.
+type LtcEncodeOptions struct {
+	// Duration is a timecode length or a frame count.
+	Duration      string
+	SampleRate    int
+	BitsPerSample int
+	// UserBits are up to 8 hex digits, user bit group 8 first.
+	UserBits   string
+	ColorFrame bool
+	// Amplitude is the peak level from 0 to 1. 0 is 0.5 (-6 dBFS).
+	Amplitude float64
+}
+
+// NewLtcEncode will render a mono WAV file of LTC counting up from startTc.
+func NewLtcEncode(startTc string, fps string, options LtcEncodeOptions) *LtcEncodeResponse {
+
+	rate, df, err := internal.ParseFrameRate(fps)
+	if err != nil {
+		return newFailedLtcEncodeResponse(startTc, fps, "", err)
+	}
+
+	fail := func(err error) *LtcEncodeResponse {
+		return newFailedLtcEncodeResponse(startTc, fps, rate.Rational(), err)
+	}
+
+	start, err := newTimecode(startTc, rate, df)
+	if err != nil {
+		return fail(err)
+	}
+	if err := start.Validate(); err != nil {
+		return fail(err)
+	}
+
+	frames, err := parseOffset(options.Duration, rate, start.DropFrame)
+	if err != nil {
+		return fail(fmt.Errorf("Duration: %w", err))
+	}
+	if frames < 1 {
+		return fail(internal.NewError(internal.ErrInvalidOption, "The duration must be at least one frame"))
+	}
+
+	var userBits internal.UserBits
+	if options.UserBits != "" {
+		if userBits, err = internal.ParseUserBits(options.UserBits); err != nil {
+			return fail(err)
+		}
+	}
+
+	samples, err := internal.EncodeLTC(start, int(frames), internal.LTCEncodeOptions{
+		SampleRate: options.SampleRate,
+		Amplitude:  options.Amplitude,
+		UserBits:   userBits,
+		ColorFrame: options.ColorFrame,
+	})
+	if err != nil {
+		return fail(err)
+	}
+
+	var wav bytes.Buffer
+	if err := internal.WriteWAV(&wav, &internal.WAV{
+		SampleRate:    options.SampleRate,
+		BitsPerSample: options.BitsPerSample,
+		Samples:       [][]float64{samples},
+	}); err != nil {
+		return fail(err)
+	}
+
+	last := *start
+	last.AddFrames(int(frames) - 1)
+
+	return &LtcEncodeResponse{
+		InputTimecode: startTc,
+		InputFps:      fps,
+		FrameRate:     rate.Rational(),
+		Valid:         true,
+		SampleRate:    options.SampleRate,
+		BitsPerSample: options.BitsPerSample,
+		IsDf:          start.DropFrame,
+		ColorFrame:    options.ColorFrame,
+		UserBits:      userBits.String(),
+		FrameCount:    int(frames),
+		StartTimecode: start.GetTimecode(),
+		LastTimecode:  last.GetTimecode(),
+		LengthSamples: len(samples),
+		LengthSeconds: float64(len(samples)) / float64(options.SampleRate),
+		Wav:           wav.Bytes(),
+	}
+}
+
+// NewMtcDump will read the MIDI timecode in a raw MIDI byte stream, both full
+// frame messages and quarter frames. fps can be left empty to use the MTC rate
+// codes, or set to read them at a rate with the same timebase (ie 23.976).
+func NewMtcDump(data []byte, fps string) *MtcDumpResponse {
+
+	var rate internal.FrameRate
+	if fps != "" {
+		var err error
+		if rate, _, err = internal.ParseFrameRate(fps); err != nil {
+			return newFailedMtcDumpResponse(fps, "", err)
+		}
+	}
+
+	events, invalid := internal.DecodeMTC(data, rate)
+
+	resp := &MtcDumpResponse{
+		InputFps:   fps,
+		Valid:      true,
+		EventCount: len(events),
+		Invalid:    invalid,
+		Events:     []MtcEvent{},
+	}
+	if rate.Num != 0 {
+		resp.FrameRate = rate.Rational()
+	}
+
+	forward, reverse := 0, 0
+	var previous *internal.MTCEvent
+	for _, event := range events {
+		tc := event.Timecode
+		e := MtcEvent{
+			Timecode:  tc.GetTimecode(),
+			FrameIdx:  tc.GetFrameIdx(),
+			IsDf:      tc.DropFrame,
+			Fps:       tc.FrameRate.String(),
+			Type:      "quarter-frame",
+			Direction: event.Direction.String(),
+			Offset:    event.Offset,
+		}
+		if tc.DropFrame {
+			e.Fps += "DF"
+		}
+
+		if event.FullFrame {
+			e.Type = "full-frame"
+			resp.FullFrames++
+		} else {
+			resp.QuarterFrames++
+			if event.Direction == internal.MTCReverse {
+				reverse++
+			} else {
+				forward++
+			}
+			// Each set of quarter frames is 2 frames on from the one before.
+			if previous != nil && !previous.FullFrame && previous.Direction == event.Direction {
+				expected := *previous.Timecode
+				if event.Direction == internal.MTCReverse {
+					expected.AddFrames(-2)
+				} else {
+					expected.AddFrames(2)
+				}
+				if expected.GetFrameIdx() != tc.GetFrameIdx() {
+					resp.Discontinuities++
+				}
+			}
+		}
+		if resp.FrameRate == "" {
+			resp.FrameRate = tc.FrameRate.Rational()
+		}
+
+		resp.Events = append(resp.Events, e)
+		previous = event
+	}
+
+	switch {
+	case forward > 0 && reverse > 0:
+		resp.Direction = "mixed"
+	case forward > 0:
+		resp.Direction = "forward"
+	case reverse > 0:
+		resp.Direction = "reverse"
+	}
+	if len(resp.Events) > 0 {
+		resp.FirstTimecode = resp.Events[0].Timecode
+		resp.LastTimecode = resp.Events[len(resp.Events)-1].Timecode
+	}
+
+	return resp
+}
+
+// MtcEncodeOptions are the settings of the MTC written by NewMtcEncode.
+type MtcEncodeOptions struct {
+	// Duration is a timecode length or a frame count.
+	Duration string
+	// Reverse counts down from the start timecode, with the quarter frames
+	// sent backwards.
+	Reverse bool
+}
+
+// NewMtcEncode will write a full frame message for startTc followed by the
+// quarter frames for the duration, as raw MIDI bytes.
+func NewMtcEncode(startTc string, fps string, options MtcEncodeOptions) *MtcEncodeResponse {
+
+	rate, df, err := internal.ParseFrameRate(fps)
+	if err != nil {
+		return newFailedMtcEncodeResponse(startTc, fps, "", err)
+	}
+
+	fail := func(err error) *MtcEncodeResponse {
+		return newFailedMtcEncodeResponse(startTc, fps, rate.Rational(), err)
+	}
+
+	start, err := newTimecode(startTc, rate, df)
+	if err != nil {
+		return fail(err)
+	}
+	if err := start.Validate(); err != nil {
+		return fail(err)
+	}
+	code, err := internal.MTCRateCode(rate, start.DropFrame)
+	if err != nil {
+		return fail(err)
+	}
+
+	frames, err := parseOffset(options.Duration, rate, start.DropFrame)
+	if err != nil {
+		return fail(fmt.Errorf("Duration: %w", err))
+	}
+	if frames < 1 {
+		return fail(internal.NewError(internal.ErrInvalidOption, "The duration must be at least one frame"))
+	}
+
+	mtc, err := internal.EncodeMTC(start, int(frames), options.Reverse)
+	if err != nil {
+		return fail(err)
+	}
+
+	last := *start
+	if options.Reverse {
+		last.AddFrames(-int(frames) + 1)
+	} else {
+		last.AddFrames(int(frames) - 1)
+	}
+
+	return &MtcEncodeResponse{
+		InputTimecode: startTc,
+		InputFps:      fps,
+		FrameRate:     rate.Rational(),
+		Valid:         true,
+		IsDf:          start.DropFrame,
+		RateCode:      code,
+		Reverse:       options.Reverse,
+		FrameCount:    int(frames),
+		StartTimecode: start.GetTimecode(),
+		LastTimecode:  last.GetTimecode(),
+		ByteCount:     len(mtc),
+		Mtc:           mtc,
+	}
+}
+
+// TodClockOptions are the options of NewTodClock.
+type TodClockOptions struct {
+	// Date is the day a timecode is on, as 2006-01-02. It defaults to today.
+	Date string
+	// Zone is an IANA time zone, ie "Europe/London". It defaults to the local
+	// time zone.
+	Zone string
+}
+
+// todClockLayouts are the clock times NewTodClock accepts, other than RFC 3339.
+var todClockLayouts = []string{"15:04:05.999999999", "15:04"}
+
+// NewTodClock converts between time of day timecode and the clock. The input
+// is either a timecode, which gives the clock time it is at, or a clock time
+// (ie "14:32:05.250" or an RFC 3339 time), which gives the timecode of that
+// frame. The timecode is taken to have been jammed to the clock at midnight.
+func NewTodClock(input string, fps string, options TodClockOptions) *TodClockResponse {
+
+	rate, df, err := internal.ParseFrameRate(fps)
+	if err != nil {
+		return newFailedTodClockResponse(input, fps, "", err)
+	}
+
+	fail := func(err error) *TodClockResponse {
+		return newFailedTodClockResponse(input, fps, rate.Rational(), err)
+	}
+
+	loc := time.Local
+	if options.Zone != "" {
+		if loc, err = time.LoadLocation(options.Zone); err != nil {
+			return fail(internal.NewError(internal.ErrInvalidOption, "%s is not a time zone. Use an IANA name, ie Europe/London", options.Zone))
+		}
+	}
+	date := time.Now().In(loc)
+	if options.Date != "" {
+		if date, err = time.Parse("2006-01-02", options.Date); err != nil {
+			return fail(internal.NewError(internal.ErrInvalidOption, "%s is not a date. Please format as yyyy-mm-dd", options.Date))
+		}
+	}
+
+	var tc *internal.Timecode
+	var clock time.Time
+	if t, tcErr := newTimecode(input, rate, df); tcErr == nil {
+		if err := t.Validate(); err != nil {
+			return fail(err)
+		}
+		tc = t
+		clock = tc.Time(date, loc)
+	} else {
+		clock, err = parseClock(input, date, loc)
+		if err != nil {
+			return fail(err)
+		}
+		if tc, err = internal.NewTimecodeFromTime(clock, rate, df); err != nil {
+			return fail(err)
+		}
+	}
+
+	return &TodClockResponse{
+		Input:              input,
+		InputFps:           fps,
+		FrameRate:          rate.Rational(),
+		Valid:              true,
+		IsDf:               tc.DropFrame,
+		Zone:               clock.Location().String(),
+		Timecode:           tc.GetTimecode(),
+		Clock:              clock.Format(time.RFC3339Nano),
+		TimeOfDay:          clock.Format("15:04:05.000"),
+		DriftSeconds:       tc.TODDrift().Seconds(),
+		DriftPerDaySeconds: rate.TODDriftPerDay(tc.DropFrame).Seconds(),
+	}
+}
+
+// parseClock parses an RFC 3339 time, which is moved into loc, or a clock
+// time on the date in loc.
+func parseClock(in string, date time.Time, loc *time.Location) (time.Time, error) {
+	if clock, err := time.Parse(time.RFC3339Nano, in); err == nil {
+		return clock.In(loc), nil
+	}
+	for _, layout := range todClockLayouts {
+		clock, err := time.Parse(layout, in)
+		if err != nil {
+			continue
+		}
+		year, month, day := date.Date()
+		hours, minutes, seconds := clock.Clock()
+		return time.Date(year, month, day, hours, minutes, seconds, clock.Nanosecond(), loc), nil
+	}
+	return time.Time{}, internal.NewError(internal.ErrMalformed, "%s is not a timecode or a clock time. Please format as hh:mm:ss:ff, hh:mm:ss.sss or an RFC 3339 time", in)
 }
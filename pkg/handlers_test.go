@@ -0,0 +1,49 @@
+package timecodetool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewValidateTimecodeSmpteWord(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		fps      string
+		expected string
+	}{
+		{"Frame", "01:00:00:00", "25", "01000000"},
+		{"First field", "01:00:00:00.0", "25", "01000000"},
+		{"Second field sets the field mark", "01:00:00:00.1", "25", "81000000"},
+		{"No time address above 30 fps", "01:00:00:00", "50", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := NewValidateTimecode(tt.input, tt.fps)
+			require.True(t, resp.Valid, resp.ErrorMsg)
+			require.Equal(t, tt.expected, resp.SmpteWord)
+		})
+	}
+}
+
+func TestNewValidateTimecodeFieldIdx(t *testing.T) {
+	resp := NewValidateTimecode("00:00:00:00", "25")
+	require.Nil(t, resp.FieldIdx)
+
+	// The first field of the first frame is still a field.
+	resp = NewValidateTimecode("00:00:00:00.0", "25")
+	require.NotNil(t, resp.FieldIdx)
+	require.Equal(t, int64(0), *resp.FieldIdx)
+
+	resp = NewValidateTimecode("00:00:00:01.1", "25")
+	require.Equal(t, int64(3), *resp.FieldIdx)
+}
+
+func TestNewConvertTimecodeRateBelowOneFps(t *testing.T) {
+	resp := NewConvertTimecode("00:00:01:00", "", "25", "0.001", "realtime")
+	require.False(t, resp.Valid)
+	require.Equal(t, ErrorCodeUnsupportedRate, resp.ErrorCode)
+	require.Equal(t, "Target framerate error: 0.001 is not a valid framerate", resp.ErrorMsg)
+}
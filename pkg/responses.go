@@ -1,20 +1,28 @@
 package timecodetool
 
 type ValidateResponse struct {
-	InputTimecode string  `json:"inputTimecode"`
-	InputFps      float64 `json:"inputFps"`
-	Valid         bool    `json:"valid"`
-	ErrorMsg      string  `json:"errorMsg"`
-	IsDf          bool    `json:"isDf"`
-	FrameIdx      int     `json:"frameIdx"`
-	NextTimecode  string  `json:"nextTimecode"`
+	InputTimecode string `json:"inputTimecode"`
+	InputFps      string `json:"inputFps"`
+	FrameRate     string `json:"frameRate"`
+	Valid         bool   `json:"valid"`
+	ErrorMsg      string `json:"errorMsg"`
+	ErrorCode     string `json:"errorCode"`
+	Err           error  `json:"-"`
+	IsDf          bool   `json:"isDf"`
+	FrameIdx      int    `json:"frameIdx"`
+	NextTimecode  string `json:"nextTimecode"`
+	SmpteWord     string `json:"smpteWord,omitempty"` // The SMPTE 12M packed BCD time address as hex, up to 30 fps
+	FieldIdx      *int64 `json:"fieldIdx,omitempty"`  // Set for a timecode with a field indicator, ie 01:00:00:00.1, even when it is 0
 }
 type SpanResponse struct {
 	InputFirstTimecode  string  `json:"inputFirstTimecode"`
 	InputLastTimecode   string  `json:"inputLastTimecode,omitempty"`
-	InputFps            float64 `json:"inputFps"`
+	InputFps            string  `json:"inputFps"`
+	FrameRate           string  `json:"frameRate"`
 	Valid               bool    `json:"valid"`
 	ErrorMsg            string  `json:"errorMsg"`
+	ErrorCode           string  `json:"errorCode"`
+	Err                 error   `json:"-"`
 	IsDf                bool    `json:"isDf"`
 	ExcludeLastTimecode bool    `json:"excludeLastTimecode"`
 	StartFrameIdx       int     `json:"startFrameIdx"`
@@ -23,13 +31,26 @@ type SpanResponse struct {
 	LengthTime          string  `json:"lengthTime"`
 	LengthTimecode      string  `json:"lengthTimecode"`
 	LengthSeconds       float64 `json:"lengthSeconds"`
+	CrossesMidnight     bool    `json:"crossesMidnight"`
 	NextTimecode        string  `json:"nextTimecode"`
+	FilmGauge           string  `json:"filmGauge,omitempty"`
+	LengthFeetFrames    string  `json:"lengthFeetFrames,omitempty"`
+	LengthFields        int     `json:"lengthFields,omitempty"`
+	SampleRate          int     `json:"sampleRate,omitempty"`
+	LengthSamples       int64   `json:"lengthSamples,omitempty"`
 }
 
+// CalculationStep is one operation of the calculation, in the order they
+// were evaluated. Timecode and Frames are the right side of the operation,
+// or Scalar when it is multiplied or divided by a number.
 type CalculationStep struct {
-	Operation string `json:"operation"` // "+" or "-"
-	Timecode  string `json:"timecode"`  // Timecode for the operation
-	Frames    int    `json:"frames"`    // Equivalent frames for the operation
+	Operation      string  `json:"operation"`        // "+", "-", "*" or "/"
+	Expression     string  `json:"expression"`       // The part of the expression, ie "00:00:30:00 * 4"
+	Timecode       string  `json:"timecode"`         // Timecode for the operation
+	Frames         int     `json:"frames"`           // Equivalent frames for the operation
+	Scalar         float64 `json:"scalar,omitempty"` // Number for * and /
+	Result         int     `json:"result"`           // Frames after the operation
+	ResultTimecode string  `json:"resultTimecode"`   // Result as a timecode
 }
 
 type CalcResponse struct {
@@ -42,7 +63,8 @@ type CalcResponse struct {
 func newOkCalcResponse(
 	InputFirstTimecode string,
 	LastTimecode string,
-	InputFps float64,
+	InputFps string,
+	FrameRate string,
 	IsDf bool,
 	ExcludeLastTimecode bool,
 	StartFrameIdx int,
@@ -51,6 +73,7 @@ func newOkCalcResponse(
 	LengthTime string,
 	LengthTimecode string,
 	LengthSeconds float64,
+	CrossesMidnight bool,
 	NextTimecode string,
 	Steps []CalculationStep) *CalcResponse {
 
@@ -59,6 +82,7 @@ func newOkCalcResponse(
 		InputFirstTimecode,
 		"",
 		InputFps,
+		FrameRate,
 		IsDf,
 		ExcludeLastTimecode,
 		StartFrameIdx,
@@ -67,6 +91,7 @@ func newOkCalcResponse(
 		LengthTime,
 		LengthTimecode,
 		LengthSeconds,
+		CrossesMidnight,
 		NextTimecode,
 	)
 
@@ -82,9 +107,10 @@ func newOkCalcResponse(
 func newFailedCalcResponse(
 	InputFirstTimecode string,
 	InputLastTimecode string,
-	InputFps float64,
+	InputFps string,
+	FrameRate string,
 	ExcludeLastTimecode bool,
-	ErrorMsg string,
+	Err error,
 	Steps []CalculationStep) *CalcResponse {
 
 	// Create the failed SpanResponse part
@@ -92,8 +118,9 @@ func newFailedCalcResponse(
 		InputFirstTimecode,
 		InputLastTimecode,
 		InputFps,
+		FrameRate,
 		ExcludeLastTimecode,
-		ErrorMsg,
+		Err,
 	)
 
 	// Return the CalcResponse with the embedded SpanResponse and the steps
@@ -103,10 +130,11 @@ func newFailedCalcResponse(
 	}
 }
 
-func newOkValidateResponse(InputTimecode string, InputFps float64, IsDf bool, FrameIdx int, NextTimecode string) *ValidateResponse {
+func newOkValidateResponse(InputTimecode string, InputFps string, FrameRate string, IsDf bool, FrameIdx int, NextTimecode string) *ValidateResponse {
 	return &ValidateResponse{
 		InputTimecode: InputTimecode,
 		InputFps:      InputFps,
+		FrameRate:     FrameRate,
 		Valid:         true,
 		IsDf:          IsDf,
 		FrameIdx:      FrameIdx,
@@ -114,20 +142,24 @@ func newOkValidateResponse(InputTimecode string, InputFps float64, IsDf bool, Fr
 	}
 }
 
-func newFailedValidateResponse(InputTimecode string, InputFps float64, IsDf bool, ErrorMsg string) *ValidateResponse {
+func newFailedValidateResponse(InputTimecode string, InputFps string, FrameRate string, IsDf bool, Err error) *ValidateResponse {
 	return &ValidateResponse{
 		InputTimecode: InputTimecode,
 		InputFps:      InputFps,
+		FrameRate:     FrameRate,
 		IsDf:          IsDf,
 		Valid:         false,
-		ErrorMsg:      ErrorMsg,
+		ErrorMsg:      Err.Error(),
+		ErrorCode:     errorCode(Err),
+		Err:           Err,
 	}
 }
 
 func newOkSpanResponse(
 	InputFirstTimecode string,
 	InputLastTimecode string,
-	InputFps float64,
+	InputFps string,
+	FrameRate string,
 	IsDf bool,
 	ExcludeLastTimecode bool,
 	StartFrameIdx int,
@@ -136,11 +168,13 @@ func newOkSpanResponse(
 	LengthTime string,
 	LengthTimecode string,
 	LengthSeconds float64,
+	CrossesMidnight bool,
 	NextTimecode string) *SpanResponse {
 	return &SpanResponse{
 		InputFirstTimecode:  InputFirstTimecode,
 		InputLastTimecode:   InputLastTimecode,
 		InputFps:            InputFps,
+		FrameRate:           FrameRate,
 		Valid:               true,
 		ErrorMsg:            "",
 		IsDf:                IsDf,
@@ -151,6 +185,7 @@ func newOkSpanResponse(
 		LengthTime:          LengthTime,
 		LengthTimecode:      LengthTimecode,
 		LengthSeconds:       LengthSeconds,
+		CrossesMidnight:     CrossesMidnight,
 		NextTimecode:        NextTimecode,
 	}
 }
@@ -158,15 +193,602 @@ func newOkSpanResponse(
 func newFailedSpanResponse(
 	InputFirstTimecode string,
 	InputLastTimecode string,
-	InputFps float64,
+	InputFps string,
+	FrameRate string,
 	ExcludeLastTimecode bool,
-	ErrorMsg string) *SpanResponse {
+	Err error) *SpanResponse {
 	return &SpanResponse{
 		InputFirstTimecode:  InputFirstTimecode,
 		InputLastTimecode:   InputLastTimecode,
 		InputFps:            InputFps,
+		FrameRate:           FrameRate,
 		Valid:               false,
-		ErrorMsg:            ErrorMsg,
+		ErrorMsg:            Err.Error(),
+		ErrorCode:           errorCode(Err),
+		Err:                 Err,
 		ExcludeLastTimecode: ExcludeLastTimecode,
 	}
 }
+
+type ConvertResponse struct {
+	InputTimecode            string  `json:"inputTimecode"`
+	InputLastTimecode        string  `json:"inputLastTimecode,omitempty"`
+	InputFps                 string  `json:"inputFps"`
+	FrameRate                string  `json:"frameRate"`
+	TargetFps                string  `json:"targetFps"`
+	TargetFrameRate          string  `json:"targetFrameRate"`
+	Strategy                 string  `json:"strategy"`
+	Valid                    bool    `json:"valid"`
+	ErrorMsg                 string  `json:"errorMsg"`
+	ErrorCode                string  `json:"errorCode"`
+	Err                      error   `json:"-"`
+	IsDf                     bool    `json:"isDf"`
+	TargetIsDf               bool    `json:"targetIsDf"`
+	FrameIdx                 int     `json:"frameIdx"`
+	Timecode                 string  `json:"timecode"`
+	TargetFrameIdx           int     `json:"targetFrameIdx"`
+	RoundingErrorSeconds     float64 `json:"roundingErrorSeconds"`
+	RoundingErrorFrames      float64 `json:"roundingErrorFrames"`
+	LastFrameIdx             int     `json:"lastFrameIdx,omitempty"`
+	LastTimecode             string  `json:"lastTimecode,omitempty"`
+	TargetLastFrameIdx       int     `json:"targetLastFrameIdx,omitempty"`
+	LastRoundingErrorSeconds float64 `json:"lastRoundingErrorSeconds,omitempty"`
+	LastRoundingErrorFrames  float64 `json:"lastRoundingErrorFrames,omitempty"`
+	LengthFrames             int     `json:"lengthFrames,omitempty"`
+	TargetLengthFrames       int     `json:"targetLengthFrames,omitempty"`
+}
+
+func newFailedConvertResponse(
+	InputTimecode string,
+	InputLastTimecode string,
+	InputFps string,
+	TargetFps string,
+	Strategy string,
+	Err error) *ConvertResponse {
+	return &ConvertResponse{
+		InputTimecode:     InputTimecode,
+		InputLastTimecode: InputLastTimecode,
+		InputFps:          InputFps,
+		TargetFps:         TargetFps,
+		Strategy:          Strategy,
+		Valid:             false,
+		ErrorMsg:          Err.Error(),
+		ErrorCode:         errorCode(Err),
+		Err:               Err,
+	}
+}
+
+type FixIssue struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+type FixResponse struct {
+	InputTimecode string     `json:"inputTimecode"`
+	InputFps      string     `json:"inputFps"`
+	FrameRate     string     `json:"frameRate"`
+	Policy        string     `json:"policy"`
+	Valid         bool       `json:"valid"`
+	ErrorMsg      string     `json:"errorMsg"`
+	ErrorCode     string     `json:"errorCode"`
+	Err           error      `json:"-"`
+	WasValid      bool       `json:"wasValid"`
+	Issues        []FixIssue `json:"issues"`
+	Timecode      string     `json:"timecode"`
+	IsDf          bool       `json:"isDf"`
+	FrameIdx      int        `json:"frameIdx"`
+}
+
+func newOkFixResponse(
+	InputTimecode string,
+	InputFps string,
+	FrameRate string,
+	Policy string,
+	Issues []FixIssue,
+	Timecode string,
+	IsDf bool,
+	FrameIdx int) *FixResponse {
+	return &FixResponse{
+		InputTimecode: InputTimecode,
+		InputFps:      InputFps,
+		FrameRate:     FrameRate,
+		Policy:        Policy,
+		Valid:         true,
+		WasValid:      len(Issues) == 0,
+		Issues:        Issues,
+		Timecode:      Timecode,
+		IsDf:          IsDf,
+		FrameIdx:      FrameIdx,
+	}
+}
+
+func newFailedFixResponse(InputTimecode string, InputFps string, FrameRate string, Policy string, Err error) *FixResponse {
+	return &FixResponse{
+		InputTimecode: InputTimecode,
+		InputFps:      InputFps,
+		FrameRate:     FrameRate,
+		Policy:        Policy,
+		Valid:         false,
+		ErrorMsg:      Err.Error(),
+		ErrorCode:     errorCode(Err),
+		Err:           Err,
+		Issues:        []FixIssue{},
+	}
+}
+
+type EdlSpeedChange struct {
+	Reel          string  `json:"reel"`
+	Speed         float64 `json:"speed"`   // Frames per second, negative for reverse
+	Percent       float64 `json:"percent"` // Speed relative to the frame rate
+	EntryTimecode string  `json:"entryTimecode"`
+}
+
+type EdlEvent struct {
+	Number             int             `json:"number"`
+	Reel               string          `json:"reel"`
+	Track              string          `json:"track"`
+	Transition         string          `json:"transition"`
+	TransitionFrames   int             `json:"transitionFrames"`
+	ClipName           string          `json:"clipName,omitempty"`
+	IsDf               bool            `json:"isDf"`
+	SourceIn           string          `json:"sourceIn"`
+	SourceOut          string          `json:"sourceOut"`
+	RecordIn           string          `json:"recordIn"`
+	RecordOut          string          `json:"recordOut"`
+	SourceLengthFrames int             `json:"sourceLengthFrames"`
+	LengthFrames       int             `json:"lengthFrames"`
+	LengthTime         string          `json:"lengthTime"`
+	LengthTimecode     string          `json:"lengthTimecode"`
+	LengthSeconds      float64         `json:"lengthSeconds"`
+	SpeedChange        *EdlSpeedChange `json:"speedChange,omitempty"`
+	Comments           []string        `json:"comments"`
+}
+
+// EdlDiscontinuity is a gap or an overlap between two events on the record side.
+type EdlDiscontinuity struct {
+	Track          string `json:"track"`
+	AfterEvent     int    `json:"afterEvent"`
+	BeforeEvent    int    `json:"beforeEvent"`
+	StartTimecode  string `json:"startTimecode"`
+	LastTimecode   string `json:"lastTimecode"`
+	LengthFrames   int    `json:"lengthFrames"`
+	LengthTimecode string `json:"lengthTimecode"`
+}
+
+type EdlResponse struct {
+	InputFps            string             `json:"inputFps"`
+	FrameRate           string             `json:"frameRate"`
+	Valid               bool               `json:"valid"`
+	ErrorMsg            string             `json:"errorMsg"`
+	ErrorCode           string             `json:"errorCode"`
+	Err                 error              `json:"-"`
+	Title               string             `json:"title"`
+	IsDf                bool               `json:"isDf"`
+	Comments            []string           `json:"comments"`
+	EventCount          int                `json:"eventCount"`
+	Events              []EdlEvent         `json:"events"`
+	RecordStartTimecode string             `json:"recordStartTimecode"`
+	RecordEndTimecode   string             `json:"recordEndTimecode"`
+	LengthFrames        int                `json:"lengthFrames"`
+	LengthTime          string             `json:"lengthTime"`
+	LengthTimecode      string             `json:"lengthTimecode"`
+	LengthSeconds       float64            `json:"lengthSeconds"`
+	Gaps                []EdlDiscontinuity `json:"gaps"`
+	Overlaps            []EdlDiscontinuity `json:"overlaps"`
+}
+
+func newFailedEdlResponse(InputFps string, FrameRate string, Err error) *EdlResponse {
+	return &EdlResponse{
+		InputFps:  InputFps,
+		FrameRate: FrameRate,
+		Valid:     false,
+		ErrorMsg:  Err.Error(),
+		ErrorCode: errorCode(Err),
+		Err:       Err,
+		Comments:  []string{},
+		Events:    []EdlEvent{},
+		Gaps:      []EdlDiscontinuity{},
+		Overlaps:  []EdlDiscontinuity{},
+	}
+}
+
+// EdlConformed is one EDL written out by a conform.
+type EdlConformed struct {
+	Title               string `json:"title"`
+	IsDf                bool   `json:"isDf"`
+	EventCount          int    `json:"eventCount"`
+	RecordStartTimecode string `json:"recordStartTimecode"`
+	RecordEndTimecode   string `json:"recordEndTimecode"`
+	Edl                 string `json:"edl"` // The CMX3600 text
+}
+
+type EdlConformResponse struct {
+	InputFps        string         `json:"inputFps"`
+	FrameRate       string         `json:"frameRate"`
+	TargetFps       string         `json:"targetFps,omitempty"`
+	TargetFrameRate string         `json:"targetFrameRate"`
+	Strategy        string         `json:"strategy,omitempty"`
+	Offset          string         `json:"offset,omitempty"`
+	OffsetFrames    int            `json:"offsetFrames"`
+	SplitAt         string         `json:"splitAt,omitempty"`
+	Renumber        bool           `json:"renumber"`
+	Valid           bool           `json:"valid"`
+	ErrorMsg        string         `json:"errorMsg"`
+	ErrorCode       string         `json:"errorCode"`
+	Err             error          `json:"-"`
+	Edls            []EdlConformed `json:"edls"`
+}
+
+func newFailedEdlConformResponse(InputFps string, Options EdlConformOptions, Err error) *EdlConformResponse {
+	return &EdlConformResponse{
+		InputFps:  InputFps,
+		TargetFps: Options.TargetFps,
+		Strategy:  Options.Strategy,
+		Offset:    Options.Offset,
+		SplitAt:   Options.SplitAt,
+		Renumber:  Options.Renumber,
+		Valid:     false,
+		ErrorMsg:  Err.Error(),
+		ErrorCode: errorCode(Err),
+		Err:       Err,
+		Edls:      []EdlConformed{},
+	}
+}
+
+// LtcFrame is one frame of LTC decoded from audio.
+type LtcFrame struct {
+	Timecode         string  `json:"timecode"`
+	FrameIdx         int     `json:"frameIdx"`
+	IsDf             bool    `json:"isDf"`
+	ColorFrame       bool    `json:"colorFrame"`
+	UserBits         string  `json:"userBits"` // 8 hex digits, user bit group 8 first
+	BinaryGroupFlags int     `json:"binaryGroupFlags"`
+	Reverse          bool    `json:"reverse"`
+	Sample           int     `json:"sample"`  // Where the frame starts in the audio
+	Seconds          float64 `json:"seconds"` // Sample as seconds
+}
+
+type LtcDecodeResponse struct {
+	InputFps        string     `json:"inputFps"`
+	FrameRate       string     `json:"frameRate"`
+	Valid           bool       `json:"valid"`
+	ErrorMsg        string     `json:"errorMsg"`
+	ErrorCode       string     `json:"errorCode"`
+	Err             error      `json:"-"`
+	SampleRate      int        `json:"sampleRate"`
+	Channel         int        `json:"channel"`
+	Channels        int        `json:"channels"`
+	FrameCount      int        `json:"frameCount"`
+	Direction       string     `json:"direction"` // forward, reverse or mixed. Empty when no LTC was found
+	FirstTimecode   string     `json:"firstTimecode"`
+	LastTimecode    string     `json:"lastTimecode"`
+	Discontinuities int        `json:"discontinuities"` // Frames that don't follow on from the one before
+	Frames          []LtcFrame `json:"frames"`
+}
+
+func newFailedLtcDecodeResponse(InputFps string, FrameRate string, Channel int, Err error) *LtcDecodeResponse {
+	return &LtcDecodeResponse{
+		InputFps:  InputFps,
+		FrameRate: FrameRate,
+		Channel:   Channel,
+		Valid:     false,
+		ErrorMsg:  Err.Error(),
+		ErrorCode: errorCode(Err),
+		Err:       Err,
+		Frames:    []LtcFrame{},
+	}
+}
+
+type LtcEncodeResponse struct {
+	InputTimecode string  `json:"inputTimecode"`
+	InputFps      string  `json:"inputFps"`
+	FrameRate     string  `json:"frameRate"`
+	Valid         bool    `json:"valid"`
+	ErrorMsg      string  `json:"errorMsg"`
+	ErrorCode     string  `json:"errorCode"`
+	Err           error   `json:"-"`
+	SampleRate    int     `json:"sampleRate"`
+	BitsPerSample int     `json:"bitsPerSample"`
+	IsDf          bool    `json:"isDf"`
+	ColorFrame    bool    `json:"colorFrame"`
+	UserBits      string  `json:"userBits"`
+	FrameCount    int     `json:"frameCount"`
+	StartTimecode string  `json:"startTimecode"`
+	LastTimecode  string  `json:"lastTimecode"`
+	LengthSamples int     `json:"lengthSamples"`
+	LengthSeconds float64 `json:"lengthSeconds"`
+	Wav           []byte  `json:"-"` // The WAV file
+}
+
+func newFailedLtcEncodeResponse(InputTimecode string, InputFps string, FrameRate string, Err error) *LtcEncodeResponse {
+	return &LtcEncodeResponse{
+		InputTimecode: InputTimecode,
+		InputFps:      InputFps,
+		FrameRate:     FrameRate,
+		Valid:         false,
+		ErrorMsg:      Err.Error(),
+		ErrorCode:     errorCode(Err),
+		Err:           Err,
+	}
+}
+
+// MtcEvent is a timecode read from MTC.
+type MtcEvent struct {
+	Timecode  string `json:"timecode"`
+	FrameIdx  int    `json:"frameIdx"`
+	IsDf      bool   `json:"isDf"`
+	Fps       string `json:"fps"`
+	Type      string `json:"type"`      // full-frame or quarter-frame
+	Direction string `json:"direction"` // forward or reverse for quarter frames, stopped for full frames
+	Offset    int    `json:"offset"`    // Where the message starts in the file
+}
+
+type MtcDumpResponse struct {
+	InputFps        string     `json:"inputFps"`
+	FrameRate       string     `json:"frameRate"`
+	Valid           bool       `json:"valid"`
+	ErrorMsg        string     `json:"errorMsg"`
+	ErrorCode       string     `json:"errorCode"`
+	Err             error      `json:"-"`
+	EventCount      int        `json:"eventCount"`
+	FullFrames      int        `json:"fullFrames"`
+	QuarterFrames   int        `json:"quarterFrames"` // Complete sets of 8 quarter frames
+	Invalid         int        `json:"invalid"`       // Messages with a timecode that isn't valid
+	Direction       string     `json:"direction"`     // forward, reverse or mixed. Empty when there are no quarter frames
+	FirstTimecode   string     `json:"firstTimecode"`
+	LastTimecode    string     `json:"lastTimecode"`
+	Discontinuities int        `json:"discontinuities"` // Quarter frames that don't follow on from the set before
+	Events          []MtcEvent `json:"events"`
+}
+
+func newFailedMtcDumpResponse(InputFps string, FrameRate string, Err error) *MtcDumpResponse {
+	return &MtcDumpResponse{
+		InputFps:  InputFps,
+		FrameRate: FrameRate,
+		Valid:     false,
+		ErrorMsg:  Err.Error(),
+		ErrorCode: errorCode(Err),
+		Err:       Err,
+		Events:    []MtcEvent{},
+	}
+}
+
+type MtcEncodeResponse struct {
+	InputTimecode string `json:"inputTimecode"`
+	InputFps      string `json:"inputFps"`
+	FrameRate     string `json:"frameRate"`
+	Valid         bool   `json:"valid"`
+	ErrorMsg      string `json:"errorMsg"`
+	ErrorCode     string `json:"errorCode"`
+	Err           error  `json:"-"`
+	IsDf          bool   `json:"isDf"`
+	RateCode      int    `json:"rateCode"`
+	Reverse       bool   `json:"reverse"`
+	FrameCount    int    `json:"frameCount"`
+	StartTimecode string `json:"startTimecode"`
+	LastTimecode  string `json:"lastTimecode"`
+	ByteCount     int    `json:"byteCount"`
+	Mtc           []byte `json:"-"` // The MIDI bytes
+}
+
+func newFailedMtcEncodeResponse(InputTimecode string, InputFps string, FrameRate string, Err error) *MtcEncodeResponse {
+	return &MtcEncodeResponse{
+		InputTimecode: InputTimecode,
+		InputFps:      InputFps,
+		FrameRate:     FrameRate,
+		Valid:         false,
+		ErrorMsg:      Err.Error(),
+		ErrorCode:     errorCode(Err),
+		Err:           Err,
+	}
+}
+
+type TodClockResponse struct {
+	Input              string  `json:"input"`
+	InputFps           string  `json:"inputFps"`
+	FrameRate          string  `json:"frameRate"`
+	Valid              bool    `json:"valid"`
+	ErrorMsg           string  `json:"errorMsg"`
+	ErrorCode          string  `json:"errorCode"`
+	Err                error   `json:"-"`
+	IsDf               bool    `json:"isDf"`
+	Zone               string  `json:"zone"`
+	Timecode           string  `json:"timecode"`
+	Clock              string  `json:"clock"`              // The clock time with its date and UTC offset, RFC 3339
+	TimeOfDay          string  `json:"timeOfDay"`          // The clock time as hh:mm:ss.mmm
+	DriftSeconds       float64 `json:"driftSeconds"`       // How far the timecode is ahead of the clock, negative when behind
+	DriftPerDaySeconds float64 `json:"driftPerDaySeconds"` // How far the timecode gets ahead of the clock in a day
+}
+
+func newFailedTodClockResponse(Input string, InputFps string, FrameRate string, Err error) *TodClockResponse {
+	return &TodClockResponse{
+		Input:     Input,
+		InputFps:  InputFps,
+		FrameRate: FrameRate,
+		Valid:     false,
+		ErrorMsg:  Err.Error(),
+		ErrorCode: errorCode(Err),
+		Err:       Err,
+	}
+}
+
+// CaptionCue is one cue of a caption file, after retiming.
+type CaptionCue struct {
+	Number        int     `json:"number"` // Position in the file, counting from 1
+	Id            string  `json:"id"`     // The SRT number or WebVTT cue identifier
+	StartTime     string  `json:"startTime"`
+	EndTime       string  `json:"endTime"`
+	StartTimecode string  `json:"startTimecode"`
+	EndTimecode   string  `json:"endTimecode"` // The frame after the cue
+	StartSeconds  float64 `json:"startSeconds"`
+	EndSeconds    float64 `json:"endSeconds"`
+	LengthFrames  int     `json:"lengthFrames"`
+	Text          string  `json:"text"` // The hex words for SCC
+}
+
+// CaptionIssue is a cue that isn't on screen for a frame, or two cues that
+// overlap. The span is where they overlap.
+type CaptionIssue struct {
+	Kind           string `json:"kind"` // zero-length or overlap
+	Cue            int    `json:"cue"`
+	OtherCue       int    `json:"otherCue,omitempty"` // The earlier cue of an overlap
+	Message        string `json:"message"`
+	StartTimecode  string `json:"startTimecode,omitempty"`
+	LastTimecode   string `json:"lastTimecode,omitempty"`
+	LengthFrames   int    `json:"lengthFrames"`
+	LengthTimecode string `json:"lengthTimecode,omitempty"`
+}
+
+type CaptionsRetimeResponse struct {
+	InputFps        string         `json:"inputFps"`
+	FrameRate       string         `json:"frameRate"`
+	TargetFps       string         `json:"targetFps,omitempty"`
+	TargetFrameRate string         `json:"targetFrameRate"`
+	Strategy        string         `json:"strategy,omitempty"`
+	Offset          string         `json:"offset,omitempty"`
+	OffsetSeconds   float64        `json:"offsetSeconds"`
+	Valid           bool           `json:"valid"`
+	ErrorMsg        string         `json:"errorMsg"`
+	ErrorCode       string         `json:"errorCode"`
+	Err             error          `json:"-"`
+	Format          string         `json:"format"` // srt, vtt or scc
+	IsDf            bool           `json:"isDf"`
+	CueCount        int            `json:"cueCount"`
+	Cues            []CaptionCue   `json:"cues"`
+	Issues          []CaptionIssue `json:"issues"`
+	Captions        string         `json:"captions"` // The retimed file
+}
+
+func newFailedCaptionsRetimeResponse(InputFps string, Options CaptionsRetimeOptions, Err error) *CaptionsRetimeResponse {
+	return &CaptionsRetimeResponse{
+		InputFps:  InputFps,
+		TargetFps: Options.TargetFps,
+		Strategy:  Options.Strategy,
+		Offset:    Options.Offset,
+		Valid:     false,
+		ErrorMsg:  Err.Error(),
+		ErrorCode: errorCode(Err),
+		Err:       Err,
+		Format:    Options.Format,
+		Cues:      []CaptionCue{},
+		Issues:    []CaptionIssue{},
+	}
+}
+
+// FcpxmlClip is one clip of an FCPXML sequence. Edits that aren't on a frame
+// boundary are reported at the nearest frame.
+type FcpxmlClip struct {
+	Kind            string   `json:"kind"` // The element, ie asset-clip or title
+	Name            string   `json:"name"`
+	Lane            int      `json:"lane"` // 0 in the primary storyline
+	Asset           string   `json:"asset,omitempty"`
+	SourceFrameRate string   `json:"sourceFrameRate"`
+	SourceIsDf      bool     `json:"sourceIsDf"`
+	SourceIn        string   `json:"sourceIn"`
+	SourceOut       string   `json:"sourceOut"`
+	RecordIn        string   `json:"recordIn"`
+	RecordOut       string   `json:"recordOut"`
+	RecordInTime    string   `json:"recordInTime"`  // The FCPXML time, ie 1001/30000s
+	RecordOutTime   string   `json:"recordOutTime"` // The FCPXML time, ie 1001/30000s
+	LengthFrames    int      `json:"lengthFrames"`
+	LengthTimecode  string   `json:"lengthTimecode"`
+	LengthSeconds   float64  `json:"lengthSeconds"`
+	FrameAligned    bool     `json:"frameAligned"`
+	UnalignedEdits  []string `json:"unalignedEdits"` // ie record in or source out
+}
+
+type FcpxmlSequence struct {
+	Name           string       `json:"name"` // The project name
+	FrameRate      string       `json:"frameRate"`
+	IsDf           bool         `json:"isDf"`
+	StartTimecode  string       `json:"startTimecode"`
+	EndTimecode    string       `json:"endTimecode"`
+	LengthFrames   int          `json:"lengthFrames"`
+	LengthTimecode string       `json:"lengthTimecode"`
+	LengthSeconds  float64      `json:"lengthSeconds"`
+	ClipCount      int          `json:"clipCount"`
+	UnalignedCount int          `json:"unalignedCount"` // Clips with an edit off a frame boundary
+	Clips          []FcpxmlClip `json:"clips"`
+}
+
+type FcpxmlResponse struct {
+	Valid         bool             `json:"valid"`
+	ErrorMsg      string           `json:"errorMsg"`
+	ErrorCode     string           `json:"errorCode"`
+	Err           error            `json:"-"`
+	Version       string           `json:"version"`
+	SequenceCount int              `json:"sequenceCount"`
+	Sequences     []FcpxmlSequence `json:"sequences"`
+}
+
+func newFailedFcpxmlResponse(Err error) *FcpxmlResponse {
+	return &FcpxmlResponse{
+		Valid:     false,
+		ErrorMsg:  Err.Error(),
+		ErrorCode: errorCode(Err),
+		Err:       Err,
+		Sequences: []FcpxmlSequence{},
+	}
+}
+
+// OtioRationalTime is an OpenTimelineIO time, a number of frames at a rate.
+type OtioRationalTime struct {
+	Value float64 `json:"value"`
+	Rate  float64 `json:"rate"`
+}
+
+// OtioClip is one clip of an OTIO timeline. The spans have the same JSON as
+// the span command.
+type OtioClip struct {
+	Track           string           `json:"track"`
+	TrackKind       string           `json:"trackKind"` // Video or Audio
+	Name            string           `json:"name"`
+	MediaUrl        string           `json:"mediaUrl,omitempty"`
+	SourceStart     OtioRationalTime `json:"sourceStart"`
+	SourceDuration  OtioRationalTime `json:"sourceDuration"`
+	SourceFrameRate string           `json:"sourceFrameRate"`
+	SourceSpan      *SpanResponse    `json:"sourceSpan"` // At the media's rate
+	RecordSpan      *SpanResponse    `json:"recordSpan"` // At the timeline's rate
+	RateMismatch    string           `json:"rateMismatch,omitempty"`
+}
+
+// OtioTransition is a transition of an OTIO timeline. The record span is the
+// overlap around its cut.
+type OtioTransition struct {
+	Track          string        `json:"track"`
+	Name           string        `json:"name"`
+	TransitionType string        `json:"transitionType"` // ie SMPTE_Dissolve
+	RecordSpan     *SpanResponse `json:"recordSpan"`
+}
+
+type OtioResponse struct {
+	InputFps        string           `json:"inputFps,omitempty"`
+	FrameRate       string           `json:"frameRate"`
+	Valid           bool             `json:"valid"`
+	ErrorMsg        string           `json:"errorMsg"`
+	ErrorCode       string           `json:"errorCode"`
+	Err             error            `json:"-"`
+	Name            string           `json:"name"`
+	IsDf            bool             `json:"isDf"`
+	GlobalStartTime OtioRationalTime `json:"globalStartTime"`
+	StartTimecode   string           `json:"startTimecode"`
+	TrackCount      int              `json:"trackCount"`
+	ClipCount       int              `json:"clipCount"`
+	Clips           []OtioClip       `json:"clips"`
+	Transitions     []OtioTransition `json:"transitions"`
+	RateMismatches  []string         `json:"rateMismatches"`
+}
+
+func newFailedOtioResponse(InputFps string, FrameRate string, Err error) *OtioResponse {
+	return &OtioResponse{
+		InputFps:       InputFps,
+		FrameRate:      FrameRate,
+		Valid:          false,
+		ErrorMsg:       Err.Error(),
+		ErrorCode:      errorCode(Err),
+		Err:            Err,
+		Clips:          []OtioClip{},
+		Transitions:    []OtioTransition{},
+		RateMismatches: []string{},
+	}
+}
@@ -0,0 +1,47 @@
+package timecodetool
+
+import (
+	"github.com/invopop/jsonschema"
+	"github.com/marcrleonard/TimecodeTool/internal"
+)
+
+// SchemaNames lists the tools that have a JSON schema, in the order they are
+// documented.
+var SchemaNames = []string{"validate", "span", "calculate", "convert", "fix", "edl", "conform", "ltc-decode", "ltc-encode", "mtc-dump", "mtc-encode", "todclock", "captions-retime", "fcpxml", "otio"}
+
+// NewSchema returns the JSON schema of the JSON output of a tool, ie "span".
+func NewSchema(name string) (*jsonschema.Schema, error) {
+	switch name {
+	case "validate":
+		return jsonschema.Reflect(&ValidateResponse{}), nil
+	case "span":
+		return jsonschema.Reflect(&SpanResponse{}), nil
+	case "calculate":
+		return jsonschema.Reflect(&CalcResponse{}), nil
+	case "convert":
+		return jsonschema.Reflect(&ConvertResponse{}), nil
+	case "fix":
+		return jsonschema.Reflect(&FixResponse{}), nil
+	case "edl":
+		return jsonschema.Reflect(&EdlResponse{}), nil
+	case "conform":
+		return jsonschema.Reflect(&EdlConformResponse{}), nil
+	case "ltc-decode":
+		return jsonschema.Reflect(&LtcDecodeResponse{}), nil
+	case "ltc-encode":
+		return jsonschema.Reflect(&LtcEncodeResponse{}), nil
+	case "mtc-dump":
+		return jsonschema.Reflect(&MtcDumpResponse{}), nil
+	case "mtc-encode":
+		return jsonschema.Reflect(&MtcEncodeResponse{}), nil
+	case "todclock":
+		return jsonschema.Reflect(&TodClockResponse{}), nil
+	case "captions-retime":
+		return jsonschema.Reflect(&CaptionsRetimeResponse{}), nil
+	case "fcpxml":
+		return jsonschema.Reflect(&FcpxmlResponse{}), nil
+	case "otio":
+		return jsonschema.Reflect(&OtioResponse{}), nil
+	}
+	return nil, internal.NewError(internal.ErrInvalidOption, "%s has no schema. Valid options are: %v", name, SchemaNames)
+}
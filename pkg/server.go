@@ -0,0 +1,435 @@
+package timecodetool
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+	"github.com/marcrleonard/TimecodeTool/internal"
+)
+
+type ValidateRequest struct {
+	Timecode string `json:"timecode"`
+	Fps      string `json:"fps"`
+}
+
+type SpanRequest struct {
+	FirstTimecode       string `json:"firstTimecode"`
+	LastTimecode        string `json:"lastTimecode"`
+	Fps                 string `json:"fps"`
+	ExcludeLastTimecode bool   `json:"excludeLastTimecode,omitempty"`
+	Signed              bool   `json:"signed,omitempty"`
+	FilmGauge           string `json:"filmGauge,omitempty"`
+	Fields              bool   `json:"fields,omitempty"`
+	SampleRate          int    `json:"sampleRate,omitempty"`
+}
+
+type CalculateRequest struct {
+	Timecode string `json:"timecode"`
+	// Operations are joined into one expression, ie ["+", "00:00:10:00", "-", "5"]
+	// or ["+ (00:00:30:00 * 4) - 12f"].
+	Operations          []string `json:"operations" query:"split"`
+	Fps                 string   `json:"fps"`
+	ExcludeLastTimecode bool     `json:"excludeLastTimecode,omitempty"`
+	FilmGauge           string   `json:"filmGauge,omitempty"`
+}
+
+type ConvertRequest struct {
+	Timecode     string `json:"timecode"`
+	LastTimecode string `json:"lastTimecode,omitempty"`
+	Fps          string `json:"fps"`
+	TargetFps    string `json:"targetFps"`
+	Strategy     string `json:"strategy,omitempty"`
+}
+
+type FixRequest struct {
+	Timecode string `json:"timecode"`
+	Fps      string `json:"fps"`
+	Policy   string `json:"policy,omitempty"`
+}
+
+type EdlRequest struct {
+	Edl string `json:"edl"`
+	Fps string `json:"fps"`
+}
+
+type ConformRequest struct {
+	// Edls are merged into one EDL, sorted by record in.
+	Edls      []string `json:"edls"`
+	Fps       string   `json:"fps"`
+	TargetFps string   `json:"targetFps,omitempty"`
+	Strategy  string   `json:"strategy,omitempty"`
+	Offset    string   `json:"offset,omitempty"`
+	SplitAt   string   `json:"splitAt,omitempty"`
+	Renumber  bool     `json:"renumber,omitempty"`
+}
+
+type FcpxmlRequest struct {
+	Fcpxml string `json:"fcpxml"`
+}
+
+type OtioRequest struct {
+	Otio string `json:"otio"`
+	// Fps reports timecode at this rate rather than the timeline's rate.
+	Fps string `json:"fps,omitempty"`
+}
+
+type TodClockRequest struct {
+	Input string `json:"input"`
+	Fps   string `json:"fps"`
+	Date  string `json:"date,omitempty"`
+	Zone  string `json:"zone,omitempty"`
+}
+
+type CaptionsRequest struct {
+	Captions  string `json:"captions"`
+	Fps       string `json:"fps"`
+	Format    string `json:"format,omitempty"`
+	TargetFps string `json:"targetFps,omitempty"`
+	Strategy  string `json:"strategy,omitempty"`
+	Offset    string `json:"offset,omitempty"`
+}
+
+// ErrorResponse is returned with a 4xx status when a request can't be run at
+// all, ie a missing parameter. A request that runs but fails (ie an invalid
+// timecode) returns the usual response with valid set to false.
+type ErrorResponse struct {
+	Valid     bool   `json:"valid"`
+	ErrorMsg  string `json:"errorMsg"`
+	ErrorCode string `json:"errorCode"`
+}
+
+// endpoint is one operation of the server. Adding one here adds it to the
+// server and to the OpenAPI document.
+type endpoint struct {
+	path     string
+	summary  string
+	request  any
+	response any
+	run      func(request any) any
+}
+
+var endpoints = []endpoint{
+	{
+		path:     "/validate",
+		summary:  "Returns a timecodes validity and information regarding the frame.",
+		request:  &ValidateRequest{},
+		response: &ValidateResponse{},
+		run: func(request any) any {
+			r := request.(*ValidateRequest)
+			return NewValidateTimecode(r.Timecode, r.Fps)
+		},
+	},
+	{
+		path:     "/span",
+		summary:  "Get duration information spanning two timecodes.",
+		request:  &SpanRequest{},
+		response: &SpanResponse{},
+		run: func(request any) any {
+			r := request.(*SpanRequest)
+			return NewSpan(r.FirstTimecode, r.LastTimecode, r.Fps, SpanOptions{
+				ExcludeLastTimecode: r.ExcludeLastTimecode,
+				Signed:              r.Signed,
+				FilmGauge:           r.FilmGauge,
+				Fields:              r.Fields,
+				SampleRate:          r.SampleRate,
+			})
+		},
+	},
+	{
+		path:     "/calculate",
+		summary:  "Timecode/Frame calculator.",
+		request:  &CalculateRequest{},
+		response: &CalcResponse{},
+		run: func(request any) any {
+			r := request.(*CalculateRequest)
+			return NewCalculate(r.Timecode, r.Operations, r.Fps, CalculateOptions{
+				ExcludeLastTimecode: r.ExcludeLastTimecode,
+				FilmGauge:           r.FilmGauge,
+			})
+		},
+	},
+	{
+		path:     "/convert",
+		summary:  "Convert a timecode, or a span, to another frame rate.",
+		request:  &ConvertRequest{},
+		response: &ConvertResponse{},
+		run: func(request any) any {
+			r := request.(*ConvertRequest)
+			strategy := r.Strategy
+			if strategy == "" {
+				strategy = string(internal.ConvertRealtimeNearest)
+			}
+			return NewConvertTimecode(r.Timecode, r.LastTimecode, r.Fps, r.TargetFps, strategy)
+		},
+	},
+	{
+		path:     "/fix",
+		summary:  "Attempts to repair a broken timecode.",
+		request:  &FixRequest{},
+		response: &FixResponse{},
+		run: func(request any) any {
+			r := request.(*FixRequest)
+			policy := r.Policy
+			if policy == "" {
+				policy = string(internal.RepairCarry)
+			}
+			return NewFixTimecode(r.Timecode, r.Fps, policy)
+		},
+	},
+	{
+		path:     "/edl",
+		summary:  "Reports the events, durations, gaps and overlaps of a CMX3600 EDL.",
+		request:  &EdlRequest{},
+		response: &EdlResponse{},
+		run: func(request any) any {
+			r := request.(*EdlRequest)
+			return NewEdlAnalysis(r.Edl, r.Fps)
+		},
+	},
+	{
+		path:     "/conform",
+		summary:  "Conforms CMX3600 EDLs and writes them back out.",
+		request:  &ConformRequest{},
+		response: &EdlConformResponse{},
+		run: func(request any) any {
+			r := request.(*ConformRequest)
+			return NewEdlConform(r.Edls, r.Fps, EdlConformOptions{
+				TargetFps: r.TargetFps,
+				Strategy:  r.Strategy,
+				Offset:    r.Offset,
+				SplitAt:   r.SplitAt,
+				Renumber:  r.Renumber,
+			})
+		},
+	},
+	{
+		path:     "/fcpxml",
+		summary:  "Lists the clips of an FCPXML project with their record and source timecodes.",
+		request:  &FcpxmlRequest{},
+		response: &FcpxmlResponse{},
+		run: func(request any) any {
+			r := request.(*FcpxmlRequest)
+			return NewFcpxmlAnalysis(r.Fcpxml)
+		},
+	},
+	{
+		path:     "/otio",
+		summary:  "Reports the source and record spans of the clips in an OpenTimelineIO timeline.",
+		request:  &OtioRequest{},
+		response: &OtioResponse{},
+		run: func(request any) any {
+			r := request.(*OtioRequest)
+			return NewOtioAnalysis(r.Otio, r.Fps)
+		},
+	},
+	{
+		path:     "/todclock",
+		summary:  "Converts between time of day timecode and the clock.",
+		request:  &TodClockRequest{},
+		response: &TodClockResponse{},
+		run: func(request any) any {
+			r := request.(*TodClockRequest)
+			return NewTodClock(r.Input, r.Fps, TodClockOptions{Date: r.Date, Zone: r.Zone})
+		},
+	},
+	{
+		path:     "/captions",
+		summary:  "Retimes a caption file and reports overlapping and zero length cues.",
+		request:  &CaptionsRequest{},
+		response: &CaptionsRetimeResponse{},
+		run: func(request any) any {
+			r := request.(*CaptionsRequest)
+			return NewCaptionsRetime(r.Captions, r.Fps, CaptionsRetimeOptions{
+				Format:    r.Format,
+				TargetFps: r.TargetFps,
+				Strategy:  r.Strategy,
+				Offset:    r.Offset,
+			})
+		},
+	},
+}
+
+// NewServer returns the HTTP handler for the serve command. Every operation
+// takes GET query parameters or a POST JSON body, and the OpenAPI document is
+// served at /openapi.json. ltc and mtc read and write audio and MIDI bytes,
+// so they are left to the command line.
+func NewServer() http.Handler {
+	mux := http.NewServeMux()
+
+	for _, e := range endpoints {
+		e := e
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			request := reflect.New(reflect.TypeOf(e.request).Elem()).Interface()
+			if err := decodeRequest(r, request); err != nil {
+				writeJSON(w, http.StatusBadRequest, &ErrorResponse{ErrorMsg: err.Error(), ErrorCode: errorCode(err)})
+				return
+			}
+			writeJSON(w, http.StatusOK, e.run(request))
+		}
+		mux.HandleFunc("GET "+e.path, handler)
+		mux.HandleFunc("POST "+e.path, handler)
+	}
+
+	mux.HandleFunc("GET /openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, OpenAPI())
+	})
+
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// decodeRequest fills a request struct from a JSON body (POST) or from the
+// query parameters (GET), which are matched to the json tags of its fields.
+// Fields without omitempty are required.
+func decodeRequest(r *http.Request, request any) error {
+	if r.Method == http.MethodPost {
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(request); err != nil {
+			return internal.NewError(internal.ErrMalformed, "Request body is not valid JSON: %s", err)
+		}
+	} else {
+		query := r.URL.Query()
+		v := reflect.ValueOf(request).Elem()
+		for i := 0; i < v.NumField(); i++ {
+			name, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("json"), ",")
+			values, ok := query[name]
+			if !ok {
+				continue
+			}
+			field := v.Field(i)
+			switch field.Kind() {
+			case reflect.String:
+				field.SetString(values[0])
+			case reflect.Bool:
+				b, err := strconv.ParseBool(values[0])
+				if err != nil {
+					return internal.NewError(internal.ErrMalformed, "%s must be true or false", name)
+				}
+				field.SetBool(b)
+			case reflect.Int:
+				n, err := strconv.Atoi(values[0])
+				if err != nil {
+					return internal.NewError(internal.ErrMalformed, "%s must be a whole number", name)
+				}
+				field.SetInt(int64(n))
+			case reflect.Slice:
+				// Repeated (?operations=+&operations=5), and space separated
+				// too when the field is tagged query:"split".
+				items := values
+				if v.Type().Field(i).Tag.Get("query") == "split" {
+					items = nil
+					for _, value := range values {
+						items = append(items, strings.Fields(value)...)
+					}
+				}
+				field.Set(reflect.ValueOf(items))
+			}
+		}
+	}
+
+	v := reflect.ValueOf(request).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		name, options, _ := strings.Cut(v.Type().Field(i).Tag.Get("json"), ",")
+		if options != "omitempty" && v.Field(i).IsZero() {
+			return internal.NewError(internal.ErrMalformed, "%s is required", name)
+		}
+	}
+	return nil
+}
+
+// OpenAPI builds the OpenAPI document of the server. The schemas are the same
+// as the ones output by the schema command.
+func OpenAPI() map[string]any {
+	schemas := map[string]any{}
+	addSchema := func(v any) map[string]any {
+		s := jsonschema.Reflect(v)
+		for name, def := range s.Definitions {
+			schemas[name] = def
+		}
+		return map[string]any{"$ref": strings.Replace(s.Ref, "#/$defs/", "#/components/schemas/", 1)}
+	}
+	errorSchema := addSchema(&ErrorResponse{})
+
+	paths := map[string]any{}
+	for _, e := range endpoints {
+		requestSchema := addSchema(e.request)
+		responses := map[string]any{
+			"200": map[string]any{
+				"description": "The response. Check valid to see if the operation succeeded.",
+				"content":     map[string]any{"application/json": map[string]any{"schema": addSchema(e.response)}},
+			},
+			"400": map[string]any{
+				"description": "The request is missing a parameter or is malformed.",
+				"content":     map[string]any{"application/json": map[string]any{"schema": errorSchema}},
+			},
+		}
+
+		var parameters []map[string]any
+		t := reflect.TypeOf(e.request).Elem()
+		for i := 0; i < t.NumField(); i++ {
+			name, options, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+			schema := map[string]any{"type": "string"}
+			switch t.Field(i).Type.Kind() {
+			case reflect.Bool:
+				schema = map[string]any{"type": "boolean"}
+			case reflect.Int:
+				schema = map[string]any{"type": "integer"}
+			case reflect.Slice:
+				schema = map[string]any{"type": "array", "items": map[string]any{"type": "string"}}
+			}
+			parameters = append(parameters, map[string]any{
+				"name":     name,
+				"in":       "query",
+				"required": options != "omitempty",
+				"schema":   schema,
+			})
+		}
+
+		operationID := strings.TrimPrefix(e.path, "/")
+		paths[e.path] = map[string]any{
+			"get": map[string]any{
+				"operationId": operationID,
+				"summary":     e.summary,
+				"parameters":  parameters,
+				"responses":   responses,
+			},
+			"post": map[string]any{
+				"operationId": operationID + "Post",
+				"summary":     e.summary,
+				"requestBody": map[string]any{
+					"required": true,
+					"content":  map[string]any{"application/json": map[string]any{"schema": requestSchema}},
+				},
+				"responses": responses,
+			},
+		}
+	}
+
+	// The schemas refer to each other as #/$defs/, which has to point at
+	// the components of the document instead.
+	data, _ := json.Marshal(schemas)
+	data = []byte(strings.ReplaceAll(string(data), `"#/$defs/`, `"#/components/schemas/`))
+	var components map[string]any
+	json.Unmarshal(data, &components)
+
+	return map[string]any{
+		"openapi": "3.1.0",
+		"info": map[string]any{
+			"title":       "TimecodeTool",
+			"version":     strings.TrimSpace(VERSION),
+			"description": "Every command of TimecodeTool other than ltc and mtc, which read and write audio and MIDI bytes.",
+		},
+		"paths":      paths,
+		"components": map[string]any{"schemas": components},
+	}
+}
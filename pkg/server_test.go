@@ -0,0 +1,187 @@
+package timecodetool
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// serve runs one request against the server, and decodes the JSON response.
+func serve(t *testing.T, method string, target string, body string, response any) int {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	NewServer().ServeHTTP(rec, req)
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), response), rec.Body.String())
+	return rec.Code
+}
+
+func TestServerGetAndPost(t *testing.T) {
+	tests := []struct {
+		name string
+		get  string
+		post string
+		path string
+	}{
+		{
+			"Validate",
+			"/validate?timecode=01:00:00:00&fps=25",
+			`{"timecode":"01:00:00:00","fps":"25"}`,
+			"/validate",
+		},
+		{
+			"Span with a bool",
+			"/span?firstTimecode=01:00:00:00&lastTimecode=01:00:00:24&fps=25&excludeLastTimecode=true",
+			`{"firstTimecode":"01:00:00:00","lastTimecode":"01:00:00:24","fps":"25","excludeLastTimecode":true}`,
+			"/span",
+		},
+		{
+			"Calculate with repeated operations",
+			"/calculate?timecode=01:00:00:00&fps=25&operations=%2B&operations=00:00:10:00",
+			`{"timecode":"01:00:00:00","operations":["+","00:00:10:00"],"fps":"25"}`,
+			"/calculate",
+		},
+		{
+			"Calculate with space separated operations",
+			"/calculate?timecode=01:00:00:00&fps=25&operations=%2B+00:00:10:00",
+			`{"timecode":"01:00:00:00","operations":["+ 00:00:10:00"],"fps":"25"}`,
+			"/calculate",
+		},
+		{
+			"Time of day clock",
+			"/todclock?input=10:00:00%3B00&fps=29.97DF&date=2024-03-01&zone=UTC",
+			`{"input":"10:00:00;00","fps":"29.97DF","date":"2024-03-01","zone":"UTC"}`,
+			"/todclock",
+		},
+		{
+			"EDL",
+			"/edl?fps=25&edl=" + url.QueryEscape(serverTestEDL),
+			`{"edl":` + strconv.Quote(serverTestEDL) + `,"fps":"25"}`,
+			"/edl",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var get, post map[string]any
+			require.Equal(t, http.StatusOK, serve(t, http.MethodGet, tt.get, "", &get))
+			require.Equal(t, http.StatusOK, serve(t, http.MethodPost, tt.path, tt.post, &post))
+			require.Equal(t, true, get["valid"], get["errorMsg"])
+			require.Equal(t, post, get)
+		})
+	}
+}
+
+const serverTestEDL = `TITLE: SERVER
+FCM: NON-DROP FRAME
+
+001  TAPE01   V     C        01:00:00:00 01:00:05:00 10:00:00:00 10:00:05:00
+002  TAPE02   V     C        02:00:00:00 02:00:02:00 10:00:05:00 10:00:07:00
+`
+
+// Repeated EDLs aren't split on their spaces, unlike repeated operations.
+func TestServerConformRepeatedEdls(t *testing.T) {
+	second := "TITLE: SECOND\n001  TAPE03   V     C        03:00:00:00 03:00:01:00 10:00:07:00 10:00:08:00\n"
+	query := url.Values{"edls": {serverTestEDL, second}, "fps": {"25"}}
+	var get, post EdlConformResponse
+	require.Equal(t, http.StatusOK, serve(t, http.MethodGet, "/conform?"+query.Encode(), "", &get))
+	require.True(t, get.Valid, get.ErrorMsg)
+	require.Len(t, get.Edls, 1)
+	require.Equal(t, 3, get.Edls[0].EventCount)
+
+	body, err := json.Marshal(ConformRequest{Edls: []string{serverTestEDL, second}, Fps: "25"})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, serve(t, http.MethodPost, "/conform", string(body), &post))
+	require.Equal(t, post, get)
+}
+
+func TestServerBadRequests(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		target   string
+		body     string
+		expected string
+	}{
+		{"Missing query parameter", http.MethodGet, "/validate?timecode=01:00:00:00", "", "fps is required"},
+		{"Missing body field", http.MethodPost, "/span", `{"firstTimecode":"01:00:00:00","fps":"25"}`, "lastTimecode is required"},
+		{"Missing file", http.MethodGet, "/fcpxml", "", "fcpxml is required"},
+		{"Bad bool", http.MethodGet, "/span?firstTimecode=01:00:00:00&lastTimecode=01:00:00:24&fps=25&signed=maybe", "", "signed must be true or false"},
+		{"Unknown field", http.MethodPost, "/validate", `{"timecode":"01:00:00:00","fps":"25","rate":"25"}`, "Request body is not valid JSON: json: unknown field \"rate\""},
+		{"Not JSON", http.MethodPost, "/validate", `<timecode/>`, "Request body is not valid JSON: invalid character '<' looking for beginning of value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp ErrorResponse
+			require.Equal(t, http.StatusBadRequest, serve(t, tt.method, tt.target, tt.body, &resp))
+			require.Equal(t, ErrorResponse{ErrorMsg: tt.expected, ErrorCode: ErrorCodeMalformed}, resp)
+		})
+	}
+}
+
+// A request that runs but fails is still a 200, with valid set to false.
+func TestServerInvalidTimecode(t *testing.T) {
+	var resp ValidateResponse
+	require.Equal(t, http.StatusOK, serve(t, http.MethodGet, "/validate?timecode=01:00:00:30&fps=25", "", &resp))
+	require.False(t, resp.Valid)
+	require.Equal(t, ErrorCodeFrameOutOfRange, resp.ErrorCode)
+}
+
+func TestServerOpenAPI(t *testing.T) {
+	var doc struct {
+		OpenAPI string                    `json:"openapi"`
+		Paths   map[string]map[string]any `json:"paths"`
+	}
+	require.Equal(t, http.StatusOK, serve(t, http.MethodGet, "/openapi.json", "", &doc))
+	require.Equal(t, "3.1.0", doc.OpenAPI)
+	require.Len(t, doc.Paths, len(endpoints))
+	for _, e := range endpoints {
+		require.Contains(t, doc.Paths, e.path)
+		require.Contains(t, doc.Paths[e.path], "get")
+		require.Contains(t, doc.Paths[e.path], "post")
+	}
+}
+
+func TestServerIntegerParameters(t *testing.T) {
+	var get, post SpanResponse
+	status := serve(t, http.MethodGet, "/span?firstTimecode=01:00:00:00&lastTimecode=01:00:00:24&fps=25&sampleRate=48000", "", &get)
+	require.Equal(t, http.StatusOK, status)
+	status = serve(t, http.MethodPost, "/span", `{"firstTimecode":"01:00:00:00","lastTimecode":"01:00:00:24","fps":"25","sampleRate":48000}`, &post)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, int64(48000), get.LengthSamples)
+	require.Equal(t, post, get)
+
+	var errResp ErrorResponse
+	status = serve(t, http.MethodGet, "/span?firstTimecode=01:00:00:00&lastTimecode=01:00:00:24&fps=25&sampleRate=48k", "", &errResp)
+	require.Equal(t, http.StatusBadRequest, status)
+	require.Equal(t, ErrorResponse{ErrorMsg: "sampleRate must be a whole number", ErrorCode: ErrorCodeMalformed}, errResp)
+
+	var doc struct {
+		Paths map[string]struct {
+			Get struct {
+				Parameters []struct {
+					Name   string         `json:"name"`
+					Schema map[string]any `json:"schema"`
+				} `json:"parameters"`
+			} `json:"get"`
+		} `json:"paths"`
+	}
+	data, err := json.Marshal(OpenAPI())
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &doc))
+	for _, parameter := range doc.Paths["/span"].Get.Parameters {
+		if parameter.Name == "sampleRate" {
+			require.Equal(t, "integer", parameter.Schema["type"])
+			return
+		}
+	}
+	t.Fatal("sampleRate is not a parameter of /span")
+}
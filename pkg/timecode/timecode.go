@@ -0,0 +1,474 @@
+// Package timecode is the importable timecode value type. A Timecode is
+// immutable, so it can be copied, compared with == and stored directly in
+// structs, JSON documents and databases.
+package timecode
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/marcrleonard/TimecodeTool/internal"
+)
+
+// FrameRate is an exact rational frame rate, ie 30000/1001 for 29.97.
+type FrameRate = internal.FrameRate
+
+// Common frame rates.
+var (
+	FPS23976 = FrameRate{Num: 24000, Den: 1001}
+	FPS24    = FrameRate{Num: 24, Den: 1}
+	FPS25    = FrameRate{Num: 25, Den: 1}
+	FPS2997  = FrameRate{Num: 30000, Den: 1001}
+	FPS30    = FrameRate{Num: 30, Den: 1}
+	FPS50    = FrameRate{Num: 50, Den: 1}
+	FPS5994  = FrameRate{Num: 60000, Den: 1001}
+	FPS60    = FrameRate{Num: 60, Den: 1}
+)
+
+// Errors returned by this package. Check them with errors.Is.
+var (
+	ErrMalformed        = internal.ErrMalformed
+	ErrInvalidDropFrame = internal.ErrInvalidDropFrame
+	ErrFrameOutOfRange  = internal.ErrFrameOutOfRange
+	ErrUnsupportedRate  = internal.ErrUnsupportedRate
+	ErrInvalidOption    = internal.ErrInvalidOption
+)
+
+// rateSeparator separates the timecode from the rate in the text form,
+// ie "01:00:00;00@30000/1001".
+const rateSeparator = "@"
+
+// Timecode is a position on a 24 hour clock at a frame rate. The zero value
+// has no rate and is only useful as a placeholder, see IsZero.
+type Timecode struct {
+	frame     int64
+	rate      FrameRate
+	dropFrame bool
+}
+
+// ParseFrameRate parses "29.97", "30000/1001" or "29.97DF". The bool is true
+// when drop frame was asked for.
+func ParseFrameRate(s string) (FrameRate, bool, error) {
+	return internal.ParseFrameRate(s)
+}
+
+// Parse parses hh:mm:ss:ff (or hh:mm:ss;ff for drop frame) at the given rate.
+// The timecode must be valid at that rate.
+func Parse(s string, rate FrameRate) (Timecode, error) {
+	if rate.Num <= 0 || rate.Den <= 0 {
+		return Timecode{}, internal.NewError(ErrUnsupportedRate, "%s is not a valid framerate", rate.Rational())
+	}
+	tc, err := internal.NewTimecodeFromString(s, rate)
+	if err != nil {
+		return Timecode{}, err
+	}
+	if err := tc.Validate(); err != nil {
+		return Timecode{}, err
+	}
+	if tc.HasField() {
+		return Timecode{}, internal.NewError(ErrMalformed, "%s has a field, but Timecode counts whole frames", s)
+	}
+	if _, divisor := tc.GetSubframes(); divisor != 0 {
+		return Timecode{}, internal.NewError(ErrMalformed, "%s has subframes, but Timecode counts whole frames", s)
+	}
+	return Timecode{frame: int64(tc.GetFrameIdx()), rate: rate, dropFrame: tc.DropFrame}, nil
+}
+
+// MustParse is like Parse but panics if the timecode can't be parsed. It is
+// meant for constants and tests.
+func MustParse(s string, rate FrameRate) Timecode {
+	tc, err := Parse(s, rate)
+	if err != nil {
+		panic(err)
+	}
+	return tc
+}
+
+// FromFrames creates a timecode from a frame index, where 0 is 00:00:00:00.
+// The index wraps around 24 hours in either direction.
+func FromFrames(frame int64, rate FrameRate, dropFrame bool) (Timecode, error) {
+	if rate.Num <= 0 || rate.Den <= 0 {
+		return Timecode{}, internal.NewError(ErrUnsupportedRate, "%s is not a valid framerate", rate.Rational())
+	}
+	if dropFrame && !rate.SupportsDropFrame() {
+		return Timecode{}, internal.NewError(ErrInvalidDropFrame, "%s is not a valid framerate for drop frame timecode", rate)
+	}
+	return Timecode{frame: wrap(frame, rate, dropFrame), rate: rate, dropFrame: dropFrame}, nil
+}
+
+func wrap(frame int64, rate FrameRate, dropFrame bool) int64 {
+	perDay := rate.FramesPerDay(dropFrame)
+	frame %= perDay
+	if frame < 0 {
+		frame += perDay
+	}
+	return frame
+}
+
+// IsZero reports whether t is the zero value, which has no rate.
+func (t Timecode) IsZero() bool {
+	return t.rate.Den == 0
+}
+
+// Rate returns the frame rate of t.
+func (t Timecode) Rate() FrameRate {
+	return t.rate
+}
+
+// DropFrame reports whether t is drop frame timecode.
+func (t Timecode) DropFrame() bool {
+	return t.dropFrame
+}
+
+// Frames returns the frame index of t, where 0 is 00:00:00:00.
+func (t Timecode) Frames() int64 {
+	return t.frame
+}
+
+// Seconds returns the real time position of t from 00:00:00:00.
+func (t Timecode) Seconds() float64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.rate.FramesToSeconds(t.frame)
+}
+
+// Duration returns the real time position of t from 00:00:00:00, rounded
+// to the nearest nanosecond.
+func (t Timecode) Duration() time.Duration {
+	if t.IsZero() {
+		return 0
+	}
+	return t.rate.FramesToDuration(t.frame)
+}
+
+// Rounding decides which frame a time between two frames goes to.
+type Rounding = internal.Rounding
+
+// Roundings for FromDuration.
+const (
+	RoundNearest = internal.RoundNearest
+	RoundFloor   = internal.RoundFloor
+	RoundCeil    = internal.RoundCeil
+)
+
+// FromDuration creates a timecode for the frame at a real time from
+// 00:00:00:00, rounded to a whole frame. It wraps around 24 hours in either
+// direction.
+func FromDuration(d time.Duration, rate FrameRate, dropFrame bool, rounding Rounding) (Timecode, error) {
+	if rate.Num <= 0 || rate.Den <= 0 {
+		return Timecode{}, internal.NewError(ErrUnsupportedRate, "%s is not a valid framerate", rate.Rational())
+	}
+	return FromFrames(rate.DurationToFrames(d, rounding), rate, dropFrame)
+}
+
+// Samples returns the first audio sample of t at the sample rate, where
+// sample 0 is 00:00:00:00. At pull down rates frames aren't a whole number of
+// samples, so this is the first sample at or after the start of the frame.
+func (t Timecode) Samples(sampleRate int) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.rate.FramesToSamples(t.frame, sampleRate)
+}
+
+// FromSamples creates a timecode from an audio sample, where sample 0 is
+// 00:00:00:00. offset is how many samples the sample is into the frame.
+func FromSamples(sample int64, sampleRate int, rate FrameRate, dropFrame bool) (tc Timecode, offset int64, err error) {
+	if sampleRate < 1 {
+		return Timecode{}, 0, internal.NewError(ErrInvalidOption, "The sample rate must be above 0")
+	}
+	if rate.Num <= 0 || rate.Den <= 0 {
+		return Timecode{}, 0, internal.NewError(ErrUnsupportedRate, "%s is not a valid framerate", rate.Rational())
+	}
+	frame, offset := rate.SamplesToFrames(sample, sampleRate)
+	tc, err = FromFrames(frame, rate, dropFrame)
+	return tc, offset, err
+}
+
+// RationalTime is an OpenTimelineIO time, a number of frames at a rate.
+type RationalTime = internal.RationalTime
+
+// RationalTime returns t as an OpenTimelineIO time, its frame count at its
+// rate.
+func (t Timecode) RationalTime() RationalTime {
+	if t.IsZero() {
+		return RationalTime{}
+	}
+	return RationalTime{Value: float64(t.frame), Rate: t.rate.Float64()}
+}
+
+// FromRationalTime creates a timecode for the nearest frame to an
+// OpenTimelineIO time. The time has to be at the timecode's rate, or
+// ErrUnsupportedRate is returned. Rescale it with RescaledTo when it isn't.
+func FromRationalTime(rt RationalTime, rate FrameRate, dropFrame bool) (Timecode, error) {
+	if rate.Num <= 0 || rate.Den <= 0 {
+		return Timecode{}, internal.NewError(ErrUnsupportedRate, "%s is not a valid framerate", rate.Rational())
+	}
+	if dropFrame && !rate.SupportsDropFrame() {
+		return Timecode{}, internal.NewError(ErrInvalidDropFrame, "%s is not a valid framerate for drop frame timecode", rate)
+	}
+	tc, err := rt.Timecode(rate, dropFrame)
+	if err != nil {
+		return Timecode{}, err
+	}
+	return Timecode{frame: int64(tc.GetFrameIdx()), rate: rate, dropFrame: dropFrame}, nil
+}
+
+// Components returns the hours, minutes, seconds and frames fields of t.
+func (t Timecode) Components() (hours, minutes, seconds, frames int) {
+	tc := t.toInternal()
+	if tc == nil {
+		return 0, 0, 0, 0
+	}
+	return tc.GetComponents()
+}
+
+// Add returns t moved by the given amount of frames, which can be negative.
+// It wraps around 24 hours in either direction.
+func (t Timecode) Add(frames int64) Timecode {
+	if t.IsZero() {
+		return t
+	}
+	t.frame = wrap(t.frame+frames, t.rate, t.dropFrame)
+	return t
+}
+
+// Sub returns the number of frames from u to t. If the rates differ, the
+// difference is measured in real time and rounded to the nearest frame at
+// the rate of t.
+func (t Timecode) Sub(u Timecode) int64 {
+	if t.rate == u.rate || u.IsZero() {
+		return t.frame - u.frame
+	}
+	if t.IsZero() {
+		return 0
+	}
+	// u.frame * u.Den / u.Num seconds, in frames of t.
+	num := u.frame * u.rate.Den * t.rate.Num
+	den := u.rate.Num * t.rate.Den
+	uFrames := (num + den/2) / den
+	return t.frame - uFrames
+}
+
+// Compare returns -1 if t is before u, 0 if they are at the same position
+// and +1 if t is after u. Timecodes at different rates are compared by real
+// time.
+func (t Timecode) Compare(u Timecode) int {
+	var a, b int64
+	if t.rate == u.rate {
+		a, b = t.frame, u.frame
+	} else {
+		// t.frame * t.Den / t.Num vs u.frame * u.Den / u.Num
+		a = t.frame * t.rate.Den * u.rate.Num
+		b = u.frame * u.rate.Den * t.rate.Num
+	}
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// Equal reports whether t and u are the same timecode at the same rate.
+// Unlike Compare, timecodes at different rates are never equal.
+func (t Timecode) Equal(u Timecode) bool {
+	return t == u
+}
+
+// Before reports whether t is before u.
+func (t Timecode) Before(u Timecode) bool {
+	return t.Compare(u) < 0
+}
+
+// After reports whether t is after u.
+func (t Timecode) After(u Timecode) bool {
+	return t.Compare(u) > 0
+}
+
+// String returns hh:mm:ss:ff, or hh:mm:ss;ff for drop frame.
+func (t Timecode) String() string {
+	tc := t.toInternal()
+	if tc == nil {
+		return ""
+	}
+	return tc.GetTimecode()
+}
+
+func (t Timecode) toInternal() *internal.Timecode {
+	if t.IsZero() {
+		return nil
+	}
+	tc, err := internal.NewTimecodeFromFrames(t.frame, t.rate, t.dropFrame)
+	if err != nil {
+		return nil
+	}
+	return tc
+}
+
+// MarshalText encodes t along with its rate, ie "01:00:00;00@30000/1001".
+func (t Timecode) MarshalText() ([]byte, error) {
+	if t.IsZero() {
+		return []byte{}, nil
+	}
+	return []byte(t.String() + rateSeparator + t.rate.Rational()), nil
+}
+
+// UnmarshalText decodes the output of MarshalText. The rate can be left off
+// (ie "01:00:00:00") if t already has a rate, which is then used.
+func (t *Timecode) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	if s == "" {
+		*t = Timecode{}
+		return nil
+	}
+
+	rate := t.rate
+	dropFrame := false
+	tc, fps, hasRate := strings.Cut(s, rateSeparator)
+	if hasRate {
+		var err error
+		if rate, dropFrame, err = ParseFrameRate(fps); err != nil {
+			return err
+		}
+	} else if t.IsZero() {
+		return internal.NewError(ErrMalformed, "%s has no frame rate. Please format as hh:mm:ss:ff@fps", s)
+	}
+
+	// A "DF" rate suffix makes the timecode drop frame, whatever its delimiter.
+	if dropFrame {
+		if i := strings.LastIndexAny(tc, ":;"); i >= 0 {
+			tc = tc[:i] + ";" + tc[i+1:]
+		}
+	}
+
+	parsed, err := Parse(tc, rate)
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+// MarshalJSON encodes t as a JSON string in the MarshalText format, or null
+// for the zero value.
+func (t Timecode) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	text, _ := t.MarshalText()
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON decodes a JSON string in the MarshalText format.
+func (t *Timecode) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*t = Timecode{}
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return internal.NewError(ErrMalformed, "timecode must be a JSON string: %s", err)
+	}
+	return t.UnmarshalText([]byte(s))
+}
+
+// Value stores t in the MarshalText format, or NULL for the zero value.
+func (t Timecode) Value() (driver.Value, error) {
+	if t.IsZero() {
+		return nil, nil
+	}
+	text, _ := t.MarshalText()
+	return string(text), nil
+}
+
+// Scan reads a value stored by Value.
+func (t *Timecode) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*t = Timecode{}
+		return nil
+	case string:
+		return t.UnmarshalText([]byte(v))
+	case []byte:
+		return t.UnmarshalText(v)
+	default:
+		return internal.NewError(ErrMalformed, "cannot scan %T into a Timecode", src)
+	}
+}
+
+// SMPTEFlags are the color frame, field and binary group flags of a SMPTE 12M
+// time address. Drop frame is the Timecode's.
+type SMPTEFlags = internal.SMPTEFlags
+
+// UserBits are the 32 user bits that travel with SMPTE 12M timecode, as 8
+// groups of 4 bits with group 1 in the lowest bits.
+type UserBits = internal.UserBits
+
+// UserBitsFormat is how the user bits are used, as set by BGF0 and BGF2.
+type UserBitsFormat = internal.UserBitsFormat
+
+// The binary group flags and the user bit formats they select.
+const (
+	BGF0 = internal.BGF0
+	BGF1 = internal.BGF1
+	BGF2 = internal.BGF2
+
+	UserBitsUnspecified = internal.UserBitsUnspecified
+	UserBitsCharacters  = internal.UserBitsCharacters
+	UserBitsDate        = internal.UserBitsDate
+	UserBitsPageLine    = internal.UserBitsPageLine
+)
+
+// PackSMPTE packs t into the 32 bit SMPTE 12M packed BCD time address used by
+// LTC, VITC, AES3, SDI, MXF and DPX. Without flags it reads as HHMMSSFF in hex.
+// Only rates up to 30 fps have a time address.
+func (t Timecode) PackSMPTE(flags SMPTEFlags) (uint32, error) {
+	tc := t.toInternal()
+	if tc == nil {
+		return 0, internal.NewError(ErrUnsupportedRate, "A timecode without a frame rate has no time address")
+	}
+	return tc.PackSMPTE(flags)
+}
+
+// UnpackSMPTE reads a 32 bit SMPTE 12M time address at the given rate, which
+// the time address doesn't carry. Drop frame comes from the time address.
+func UnpackSMPTE(word uint32, rate FrameRate) (Timecode, SMPTEFlags, error) {
+	tc, flags, err := internal.UnpackSMPTE(word, rate)
+	if err != nil {
+		return Timecode{}, flags, err
+	}
+	return Timecode{frame: int64(tc.GetFrameIdx()), rate: rate, dropFrame: tc.DropFrame}, flags, nil
+}
+
+// ParseUserBits parses up to 8 hex digits, group 8 first, ie "12345678".
+func ParseUserBits(s string) (UserBits, error) {
+	return internal.ParseUserBits(s)
+}
+
+// UserBitsFromReelID stores a reel ID of up to 8 hex digits, ie "A001". Read
+// it back with ReelID.
+func UserBitsFromReelID(reel string) (UserBits, error) {
+	return internal.NewUserBitsFromReelID(reel)
+}
+
+// UserBitsFromDate stores a date as BCD day, month and two digit year, with
+// the UserBitsDate format. Read it back with Date.
+func UserBitsFromDate(date time.Time) UserBits {
+	return internal.NewUserBitsFromDate(date)
+}
+
+// UserBitsFromASCII stores text 4 characters to a frame, with the
+// UserBitsCharacters format. 8 characters take two frames.
+func UserBitsFromASCII(text string) ([]UserBits, error) {
+	return internal.NewUserBitsFromASCII(text)
+}
+
+// ASCIIFromUserBits reads text stored by UserBitsFromASCII.
+func ASCIIFromUserBits(words []UserBits) string {
+	return internal.ASCIIFromUserBits(words)
+}
@@ -0,0 +1,205 @@
+package timecode
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tc, err := Parse("01:00:00;00", FPS2997)
+	require.NoError(t, err)
+	require.Equal(t, int64(107892), tc.Frames())
+	require.True(t, tc.DropFrame())
+	require.Equal(t, "01:00:00;00", tc.String())
+
+	h, m, s, f := tc.Add(-1).Components()
+	require.Equal(t, []int{0, 59, 59, 29}, []int{h, m, s, f})
+
+	_, err = Parse("00:01:00;00", FPS2997)
+	require.True(t, errors.Is(err, ErrInvalidDropFrame))
+
+	_, err = Parse("00:00:00:25", FPS25)
+	require.True(t, errors.Is(err, ErrFrameOutOfRange))
+
+	_, err = Parse("1:00", FPS25)
+	require.True(t, errors.Is(err, ErrMalformed))
+
+	_, err = Parse("01:00:00:00.1", FPS25)
+	require.True(t, errors.Is(err, ErrMalformed))
+
+	_, err = Parse("00:00:00:00", FrameRate{})
+	require.True(t, errors.Is(err, ErrUnsupportedRate))
+}
+
+func TestFromFrames(t *testing.T) {
+	tc, err := FromFrames(-1, FPS24, false)
+	require.NoError(t, err)
+	require.Equal(t, "23:59:59:23", tc.String())
+
+	_, err = FromFrames(0, FPS24, true)
+	require.True(t, errors.Is(err, ErrInvalidDropFrame))
+}
+
+func TestArithmetic(t *testing.T) {
+	a := MustParse("01:00:00:00", FPS25)
+	b := a.Add(50)
+
+	require.Equal(t, "01:00:02:00", b.String())
+	require.Equal(t, "01:00:00:00", a.String(), "Add must not change the receiver")
+	require.Equal(t, int64(50), b.Sub(a))
+	require.Equal(t, int64(-50), a.Sub(b))
+	require.True(t, a.Before(b))
+	require.True(t, b.After(a))
+	require.Equal(t, 0, a.Compare(a))
+	require.True(t, a.Equal(MustParse("01:00:00:00", FPS25)))
+	require.Equal(t, "23:59:59:24", MustParse("00:00:00:00", FPS25).Add(-1).String())
+}
+
+func TestCompareAcrossRates(t *testing.T) {
+	pal := MustParse("00:00:01:00", FPS25)
+	film := MustParse("00:00:01:00", FPS24)
+
+	require.Equal(t, 0, pal.Compare(film))
+	require.False(t, pal.Equal(film))
+	require.Equal(t, int64(0), pal.Sub(film))
+
+	ntsc := MustParse("00:00:01:00", FPS2997)
+	require.True(t, ntsc.After(MustParse("00:00:01:00", FPS30)))
+}
+
+func TestConversions(t *testing.T) {
+	tc := MustParse("01:00:00:00", FPS23976)
+	require.Equal(t, int64(86400), tc.Frames())
+	require.InDelta(t, 3603.6, tc.Seconds(), 1e-9)
+	require.Equal(t, 3603600*time.Millisecond, tc.Duration())
+
+	require.Equal(t, time.Duration(41708333), MustParse("00:00:00:01", FPS23976).Duration())
+
+	require.Equal(t, int64(172972800), tc.Samples(48000))
+	back, offset, err := FromSamples(172972800+10, 48000, FPS23976, false)
+	require.NoError(t, err)
+	require.True(t, back.Equal(tc))
+	require.Equal(t, int64(10), offset)
+
+	fromDuration, err := FromDuration(tc.Duration(), FPS23976, false, RoundNearest)
+	require.NoError(t, err)
+	require.True(t, fromDuration.Equal(tc))
+	floor, err := FromDuration(41708332, FPS23976, false, RoundFloor)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), floor.Frames())
+	ceil, err := FromDuration(time.Nanosecond, FPS23976, false, RoundCeil)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), ceil.Frames())
+
+	rt := MustParse("01:00:00;00", FPS2997).RationalTime()
+	require.Equal(t, RationalTime{Value: 107892, Rate: 30000.0 / 1001}, rt)
+	fromRationalTime, err := FromRationalTime(RationalTime{Value: 107892, Rate: 29.97}, FPS2997, true)
+	require.NoError(t, err)
+	require.Equal(t, "01:00:00;00", fromRationalTime.String())
+	_, err = FromRationalTime(RationalTime{Value: 86400, Rate: 24}, FPS25, false)
+	require.True(t, errors.Is(err, ErrUnsupportedRate))
+	fromRationalTime, err = FromRationalTime(RationalTime{Value: 86400, Rate: 24}.RescaledTo(25), FPS25, false)
+	require.NoError(t, err)
+	require.Equal(t, "01:00:00:00", fromRationalTime.String())
+
+	_, err = Parse("01:00:00:00.50", FPS25)
+	require.True(t, errors.Is(err, ErrMalformed))
+}
+
+func TestTextAndJSON(t *testing.T) {
+	tc := MustParse("01:00:00;00", FPS2997)
+
+	text, err := tc.MarshalText()
+	require.NoError(t, err)
+	require.Equal(t, "01:00:00;00@30000/1001", string(text))
+
+	var back Timecode
+	require.NoError(t, back.UnmarshalText(text))
+	require.True(t, tc.Equal(back))
+
+	var df Timecode
+	require.NoError(t, df.UnmarshalText([]byte("01:00:00:00@29.97DF")))
+	require.True(t, tc.Equal(df))
+
+	// Without a rate, the rate of the receiver is used.
+	withRate := MustParse("00:00:00:00", FPS25)
+	require.NoError(t, withRate.UnmarshalText([]byte("00:00:01:00")))
+	require.Equal(t, int64(25), withRate.Frames())
+
+	var noRate Timecode
+	require.True(t, errors.Is(noRate.UnmarshalText([]byte("00:00:01:00")), ErrMalformed))
+
+	type clip struct {
+		In  Timecode `json:"in"`
+		Out Timecode `json:"out"`
+	}
+	data, err := json.Marshal(clip{In: tc})
+	require.NoError(t, err)
+	require.Equal(t, `{"in":"01:00:00;00@30000/1001","out":null}`, string(data))
+
+	var c clip
+	require.NoError(t, json.Unmarshal(data, &c))
+	require.True(t, tc.Equal(c.In))
+	require.True(t, c.Out.IsZero())
+
+	require.Error(t, json.Unmarshal([]byte(`{"in":5}`), &c))
+}
+
+func TestSQL(t *testing.T) {
+	tc := MustParse("10:00:00:00", FPS24)
+
+	v, err := tc.Value()
+	require.NoError(t, err)
+	require.Equal(t, "10:00:00:00@24/1", v)
+
+	var back Timecode
+	require.NoError(t, back.Scan(v))
+	require.True(t, tc.Equal(back))
+	require.NoError(t, back.Scan([]byte("10:00:00:01@24")))
+	require.Equal(t, int64(864001), back.Frames())
+
+	require.NoError(t, back.Scan(nil))
+	require.True(t, back.IsZero())
+	v, err = back.Value()
+	require.NoError(t, err)
+	require.Nil(t, v)
+
+	require.True(t, errors.Is(back.Scan(12), ErrMalformed))
+}
+
+func TestSMPTE(t *testing.T) {
+	tc := MustParse("01:02:03;04", FPS2997)
+
+	word, err := tc.PackSMPTE(SMPTEFlags{ColorFrame: true, BinaryGroupFlags: BGF2})
+	require.NoError(t, err)
+	require.Equal(t, uint32(0x810203C4), word)
+
+	back, flags, err := UnpackSMPTE(word, FPS2997)
+	require.NoError(t, err)
+	require.True(t, tc.Equal(back))
+	require.True(t, back.DropFrame())
+	require.True(t, flags.ColorFrame)
+	require.Equal(t, UserBitsDate, flags.UserBitsFormat())
+
+	_, err = MustParse("01:00:00:00", FPS50).PackSMPTE(SMPTEFlags{})
+	require.True(t, errors.Is(err, ErrUnsupportedRate))
+	_, err = Timecode{}.PackSMPTE(SMPTEFlags{})
+	require.True(t, errors.Is(err, ErrUnsupportedRate))
+
+	date := time.Date(2024, time.June, 7, 0, 0, 0, 0, time.UTC)
+	got, err := UserBitsFromDate(date).Date()
+	require.NoError(t, err)
+	require.Equal(t, date, got)
+
+	words, err := UserBitsFromASCII("REEL0001")
+	require.NoError(t, err)
+	require.Equal(t, "REEL0001", ASCIIFromUserBits(words))
+
+	reel, err := UserBitsFromReelID("A001")
+	require.NoError(t, err)
+	require.Equal(t, "A001", reel.ReelID())
+}